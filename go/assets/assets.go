@@ -10,6 +10,26 @@ import (
 //go:embed shaders/*.vert shaders/*.frag textures/*.png
 var embeddedFS embed.FS
 
+// BlockTextures lists the block texture files in texture layer order.
+// block.Definition texture indices refer to positions in this list.
+var BlockTextures = []string{
+	"textures/dirt.png",            // 0
+	"textures/grass_top.png",       // 1
+	"textures/grass_side.png",      // 2
+	"textures/stone.png",           // 3
+	"textures/wood.png",            // 4
+	"textures/leaves.png",          // 5
+	"textures/water.png",           // 6
+	"textures/ice.png",             // 7
+	"textures/sand.png",            // 8
+	"textures/snow.png",            // 9
+	"textures/glass.png",           // 10
+	"textures/lava.png",            // 11
+	"textures/campfire.png",        // 12
+	"textures/stonebrick.png",      // 13
+	"textures/mossystonebrick.png", // 14
+}
+
 // FS returns the embedded filesystem containing all assets
 func FS() embed.FS {
 	return embeddedFS
@@ -0,0 +1,456 @@
+// Command voxelctl inspects and edits saved worlds without starting the game.
+//
+// Each save argument is a world directory (e.g. ~/.voxelgame/worlds/my-world)
+// or a save file. Flags go before the arguments.
+//
+// Usage:
+//
+//	voxelctl info <save>
+//	voxelctl chunks [-top n] <save>
+//	voxelctl prune [-dry-run] <save>
+//	voxelctl teleport <save> <x> <y> <z>
+//	voxelctl teleport -surface <save> <x> <z>
+//	voxelctl locate [-radius n] <save> <village|ruin|type> [<x> <z>]
+//	voxelctl diff [-limit n] <save-a> <save-b>
+//	voxelctl merge [-keep] [-force] [-dry-run] [-from cx,cz -to cx,cz] <src> <dst>
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"voxelgame/internal/generation/terrain"
+	"voxelgame/internal/headless"
+	"voxelgame/internal/physics"
+	"voxelgame/internal/save"
+)
+
+// command is a voxelctl subcommand
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"info", "info <save>", runInfo},
+	{"chunks", "chunks [-top n] <save>", runChunks},
+	{"prune", "prune [-dry-run] <save>", runPrune},
+	{"teleport", "teleport [-surface] <save> <x> [<y>] <z>", runTeleport},
+	{"locate", "locate [-radius n] <save> <village|ruin|type> [<x> <z>]", runLocate},
+	{"diff", "diff [-limit n] <save-a> <save-b>", runDiff},
+	{"merge", "merge [-keep] [-force] [-dry-run] [-from cx,cz -to cx,cz] <src> <dst>", runMerge},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if err := cmd.run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "voxelctl %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "voxelctl: unknown command %q\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: voxelctl <command> [flags] <args>")
+	fmt.Fprintln(os.Stderr, "A save is a world directory or a save file.")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n", cmd.usage)
+	}
+}
+
+// loadWorld loads a save for inspection or editing
+func loadWorld(path string) (*headless.World, error) {
+	data, err := headless.LoadSave(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	return headless.FromSave(data), nil
+}
+
+// parseArgs parses a subcommand's flags and checks the argument count
+func parseArgs(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	rest := fs.Args()
+	if len(rest) < min || len(rest) > max {
+		return nil, fmt.Errorf("expected %d to %d arguments, got %d", min, max, len(rest))
+	}
+	return rest, nil
+}
+
+func runInfo(args []string) error {
+	fs := flag.NewFlagSet("info", flag.ContinueOnError)
+	rest, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	w, err := loadWorld(rest[0])
+	if err != nil {
+		return err
+	}
+	data := w.Data
+
+	fmt.Printf("Save:        %s\n", rest[0])
+	fmt.Printf("Version:     %s\n", data.Version)
+	fmt.Printf("Saved:       %s\n", time.Unix(data.Timestamp, 0).Format("2006-01-02 15:04:05"))
+	fmt.Printf("Seed:        %d\n", data.World.Seed)
+
+	if gen := data.World.Generator; gen != nil {
+		fmt.Printf("Generator:   %s v%d (sea level %d, amplitude %.2f, trees %.2f, caves %.2f)\n",
+			gen.Preset, gen.Version, gen.SeaLevel, gen.TerrainAmplitude, gen.TreeDensity, gen.CaveFrequency)
+		if gen.FlatLayers != "" {
+			fmt.Printf("Layers:      %s\n", gen.FlatLayers)
+		}
+	} else {
+		fmt.Printf("Generator:   defaults (not stored)\n")
+	}
+	if t := data.World.Time; t != nil {
+		fmt.Printf("Time:        day %d, %02d:%02d\n", t.Day+1, int(t.Hour), int((t.Hour-float32(int(t.Hour)))*60))
+	}
+
+	p := data.Player
+	fmt.Printf("Player:      %.1f, %.1f, %.1f (yaw %.0f, pitch %.0f)\n", p.PositionX, p.PositionY, p.PositionZ, p.Yaw, p.Pitch)
+	if m := p.Movement; m != nil {
+		fmt.Printf("Movement:    flying=%t crouching=%t stamina=%.0f third-person=%t\n", m.IsFlying, m.IsCrouching, m.Stamina, m.ThirdPerson)
+	}
+	if inv := p.Inventory; inv != nil {
+		fmt.Printf("Inventory:   %d items in hotbar, %d in main\n", countItems(inv.Hotbar), countItems(inv.Main))
+	}
+
+	entries := 0
+	for _, chunkMods := range data.World.ModifiedChunks {
+		entries += len(chunkMods.Modifications)
+	}
+	edits := w.Edits()
+	fmt.Printf("Edits:       %d blocks in %d chunks (%d saved entries)\n", len(edits), len(edits.CountByChunk()), entries)
+	fmt.Printf("Creatures:   %d\n", len(data.World.Creatures))
+	fmt.Printf("Items:       %d dropped\n", len(data.World.Items))
+	return nil
+}
+
+// countItems returns the total item count of inventory slots
+func countItems(stacks []save.ItemStackSave) int {
+	n := 0
+	for _, s := range stacks {
+		n += s.Count
+	}
+	return n
+}
+
+func runChunks(args []string) error {
+	fs := flag.NewFlagSet("chunks", flag.ContinueOnError)
+	top := fs.Int("top", 0, "only list the n most modified chunks")
+	rest, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	w, err := loadWorld(rest[0])
+	if err != nil {
+		return err
+	}
+
+	counts := w.Edits().CountByChunk()
+	total := 0
+	for _, c := range counts {
+		total += c.Count
+	}
+
+	fmt.Printf("%-12s %8s\n", "CHUNK", "EDITS")
+	for i, c := range counts {
+		if *top > 0 && i >= *top {
+			fmt.Printf("... %d more chunks\n", len(counts)-i)
+			break
+		}
+		fmt.Printf("%-12s %8d\n", headless.ChunkID(c.CX, c.CZ), c.Count)
+	}
+	fmt.Printf("%d edits in %d chunks\n", total, len(counts))
+	return nil
+}
+
+func runPrune(args []string) error {
+	fs := flag.NewFlagSet("prune", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "report what would be removed without saving")
+	rest, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	w, err := loadWorld(rest[0])
+	if err != nil {
+		return err
+	}
+
+	chunksBefore := len(w.Data.World.ModifiedChunks)
+	removed := w.Prune()
+	remaining := len(w.Edits())
+	fmt.Printf("Removed %d redundant entries, %d edits in %d chunks remain (was %d chunks)\n",
+		removed, remaining, len(w.Data.World.ModifiedChunks), chunksBefore)
+
+	if removed == 0 || *dryRun {
+		return nil
+	}
+	return headless.WriteSave(rest[0], w.Data)
+}
+
+func runTeleport(args []string) error {
+	fs := flag.NewFlagSet("teleport", flag.ContinueOnError)
+	surface := fs.Bool("surface", false, "place the player on top of the terrain at x, z")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	rest := fs.Args()
+
+	want := 4
+	if *surface {
+		want = 3
+	}
+	if len(rest) != want {
+		return fmt.Errorf("expected %d arguments, got %d", want, len(rest))
+	}
+
+	coords := make([]float64, 0, 3)
+	for _, s := range rest[1:] {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid coordinate %q", s)
+		}
+		coords = append(coords, v)
+	}
+
+	w, err := loadWorld(rest[0])
+	if err != nil {
+		return err
+	}
+
+	var x, y, z float64
+	if *surface {
+		x, z = coords[0], coords[1]
+		bx, bz := int(math.Floor(x)), int(math.Floor(z))
+		top := w.SurfaceHeight(bx, bz)
+		if top < 0 {
+			return fmt.Errorf("no ground at %.1f, %.1f", x, z)
+		}
+		if w.GetBlock(bx, top, bz).IsLiquid() {
+			fmt.Printf("Warning: %.1f, %.1f is open water, the player will start swimming\n", x, z)
+		}
+		// The saved position is the player's eyes
+		y = float64(top+1) + physics.PlayerEyeHeight
+	} else {
+		x, y, z = coords[0], coords[1], coords[2]
+	}
+
+	p := &w.Data.Player
+	fmt.Printf("Moving player from %.1f, %.1f, %.1f to %.1f, %.1f, %.1f\n",
+		p.PositionX, p.PositionY, p.PositionZ, x, y, z)
+	p.PositionX = float32(x)
+	p.PositionY = float32(y)
+	p.PositionZ = float32(z)
+
+	return headless.WriteSave(rest[0], w.Data)
+}
+
+func runLocate(args []string) error {
+	fs := flag.NewFlagSet("locate", flag.ContinueOnError)
+	radius := fs.Int("radius", 4000, "search radius in blocks")
+	rest, err := parseArgs(fs, args, 2, 4)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 3 {
+		return fmt.Errorf("expected both x and z")
+	}
+
+	w, err := loadWorld(rest[0])
+	if err != nil {
+		return err
+	}
+	if w.Preset == terrain.PresetSuperflat || w.Preset == terrain.PresetVoid {
+		return fmt.Errorf("%s worlds have no settlements", w.Preset)
+	}
+
+	// Search around the saved player unless given a position
+	x, z := int(w.Data.Player.PositionX), int(w.Data.Player.PositionZ)
+	if len(rest) == 4 {
+		if x, err = strconv.Atoi(rest[2]); err != nil {
+			return fmt.Errorf("invalid coordinate %q", rest[2])
+		}
+		if z, err = strconv.Atoi(rest[3]); err != nil {
+			return fmt.Errorf("invalid coordinate %q", rest[3])
+		}
+	}
+
+	s, ok := w.Generator.Locate(rest[1], x, z, *radius)
+	if !ok {
+		return fmt.Errorf("no %s within %d blocks of %d, %d", rest[1], *radius, x, z)
+	}
+	fmt.Printf("%s (%s) at %d, %d, %d, %.0f blocks away\n", s.Name, s.Kind, s.X, s.Y, s.Z,
+		math.Hypot(float64(s.X-x), float64(s.Z-z)))
+	fmt.Printf("Bounds: %d, %d, %d to %d, %d, %d\n", s.MinX, s.MinY, s.MinZ, s.MaxX, s.MaxY, s.MaxZ)
+	return nil
+}
+
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	limit := fs.Int("limit", 50, "maximum number of block differences to list (0 for all)")
+	rest, err := parseArgs(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	a, err := loadWorld(rest[0])
+	if err != nil {
+		return err
+	}
+	b, err := loadWorld(rest[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("--- %s\n+++ %s\n", rest[0], rest[1])
+
+	da, db := a.Data, b.Data
+	diffField("seed", da.World.Seed, db.World.Seed)
+	diffField("generator", generatorString(da.World.Generator), generatorString(db.World.Generator))
+	diffField("player", fmt.Sprintf("%.1f, %.1f, %.1f", da.Player.PositionX, da.Player.PositionY, da.Player.PositionZ),
+		fmt.Sprintf("%.1f, %.1f, %.1f", db.Player.PositionX, db.Player.PositionY, db.Player.PositionZ))
+	diffField("creatures", len(da.World.Creatures), len(db.World.Creatures))
+	diffField("items", len(da.World.Items), len(db.World.Items))
+	if da.World.Seed != db.World.Seed {
+		fmt.Println("Seeds differ, so unmodified terrain differs everywhere; only edited blocks are compared")
+	}
+
+	diffs := headless.Diff(a, b)
+
+	// Summarize per chunk before listing blocks
+	perChunk := make(map[string]int)
+	for _, d := range diffs {
+		cx, cz := d.Pos.Chunk()
+		perChunk[headless.ChunkID(cx, cz)]++
+	}
+	ids := make([]string, 0, len(perChunk))
+	for id := range perChunk {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Printf("chunk %s: %d blocks differ\n", id, perChunk[id])
+	}
+
+	for i, d := range diffs {
+		if *limit > 0 && i >= *limit {
+			fmt.Printf("... %d more\n", len(diffs)-i)
+			break
+		}
+		fmt.Printf("%d,%d,%d: %s -> %s\n", d.Pos.X, d.Pos.Y, d.Pos.Z, d.A, d.B)
+	}
+	fmt.Printf("%d blocks differ in %d chunks\n", len(diffs), len(perChunk))
+	return nil
+}
+
+// diffField prints a metadata field if it differs
+func diffField(name string, a, b interface{}) {
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		fmt.Printf("%s: %v -> %v\n", name, a, b)
+	}
+}
+
+// generatorString describes a stored generator config
+func generatorString(gen *save.GeneratorSave) string {
+	if gen == nil {
+		return "defaults"
+	}
+	s := fmt.Sprintf("%s v%d sea=%d amp=%.2f trees=%.2f caves=%.2f",
+		gen.Preset, gen.Version, gen.SeaLevel, gen.TerrainAmplitude, gen.TreeDensity, gen.CaveFrequency)
+	if gen.FlatLayers != "" {
+		s += " layers=" + gen.FlatLayers
+	}
+	return s
+}
+
+func runMerge(args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	keep := fs.Bool("keep", false, "keep the destination's block where both saves modified it")
+	force := fs.Bool("force", false, "merge even if the saves have different seeds or generators")
+	dryRun := fs.Bool("dry-run", false, "report what would change without saving")
+	from := fs.String("from", "", "first chunk of the region to merge as cx,cz")
+	to := fs.String("to", "", "last chunk of the region to merge as cx,cz")
+	rest, err := parseArgs(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	src, err := loadWorld(rest[0])
+	if err != nil {
+		return err
+	}
+	dst, err := loadWorld(rest[1])
+	if err != nil {
+		return err
+	}
+
+	// Edits are stored relative to the generated terrain, so they only
+	// line up between worlds generated the same way
+	sameWorld := src.Data.World.Seed == dst.Data.World.Seed &&
+		generatorString(src.Data.World.Generator) == generatorString(dst.Data.World.Generator)
+	if !sameWorld && !*force {
+		return fmt.Errorf("saves have different seeds or generator settings (use -force to merge anyway)")
+	}
+
+	opts := headless.MergeOptions{KeepExisting: *keep}
+	if *from != "" || *to != "" {
+		minCX, minCZ, maxCX, maxCZ, err := parseRegion(*from, *to)
+		if err != nil {
+			return err
+		}
+		opts.Filter = func(cx, cz int) bool {
+			return cx >= minCX && cx <= maxCX && cz >= minCZ && cz <= maxCZ
+		}
+	}
+
+	result := headless.Merge(dst, src, opts)
+	fmt.Printf("Added %d, replaced %d, kept %d conflicting blocks\n", result.Added, result.Replaced, result.Kept)
+
+	if result.Added+result.Replaced == 0 || *dryRun {
+		return nil
+	}
+	return headless.WriteSave(rest[1], dst.Data)
+}
+
+// parseRegion returns the inclusive chunk range between two "cx,cz" corners
+func parseRegion(from, to string) (minCX, minCZ, maxCX, maxCZ int, err error) {
+	if from == "" || to == "" {
+		return 0, 0, 0, 0, fmt.Errorf("-from and -to must be given together")
+	}
+	var x0, z0, x1, z1 int
+	if _, err := fmt.Sscanf(from, "%d,%d", &x0, &z0); err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("invalid chunk coordinate %q, expected cx,cz", from)
+	}
+	if _, err := fmt.Sscanf(to, "%d,%d", &x1, &z1); err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("invalid chunk coordinate %q, expected cx,cz", to)
+	}
+	return min(x0, x1), min(z0, z1), max(x0, x1), max(z0, z1), nil
+}
@@ -0,0 +1,135 @@
+// Command voxelexport writes a region of a world to OBJ or glTF without
+// opening a window.
+//
+// Usage:
+//
+//	voxelexport -world ~/.voxelgame/worlds/my-world -from -2,-2 -to 2,2 -out region.glb
+//	voxelexport -seed 1234 -center 0,0 -radius 3 -textured -out region.obj
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"voxelgame/internal/core/chunk"
+	"voxelgame/internal/export"
+	"voxelgame/internal/generation/terrain"
+	"voxelgame/internal/headless"
+)
+
+func main() {
+	worldPath := flag.String("world", "", "world directory or save file to export from")
+	seed := flag.Int64("seed", 0, "generate terrain from this seed instead of loading a world")
+	preset := flag.String("preset", terrain.PresetDefault, "terrain preset used with -seed ("+strings.Join(terrain.Presets, ", ")+")")
+	layers := flag.String("layers", terrain.DefaultFlatLayers, "superflat layers from the bottom up, used with -preset superflat")
+	from := flag.String("from", "", "first chunk of the region as cx,cz")
+	to := flag.String("to", "", "last chunk of the region as cx,cz")
+	center := flag.String("center", "0,0", "center chunk as cx,cz when -from/-to aren't given")
+	radius := flag.Int("radius", 2, "chunk radius around -center")
+	out := flag.String("out", "region.glb", "output file (.obj, .gltf or .glb)")
+	textured := flag.Bool("textured", false, "export texture atlas UVs instead of plain block colors")
+	flag.Parse()
+
+	if err := run(*worldPath, *seed, *preset, *layers, *from, *to, *center, *radius, *out, *textured); err != nil {
+		fmt.Fprintf(os.Stderr, "voxelexport: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(worldPath string, seed int64, preset, layers, from, to, center string, radius int, out string, textured bool) error {
+	format := strings.ToLower(filepath.Ext(out))
+	if format != ".obj" && format != ".gltf" && format != ".glb" {
+		return fmt.Errorf("unsupported output format %q (use .obj, .gltf or .glb)", format)
+	}
+
+	minCX, minCZ, maxCX, maxCZ, err := parseRegion(from, to, center, radius)
+	if err != nil {
+		return err
+	}
+
+	var w *headless.World
+	if worldPath != "" {
+		data, err := headless.LoadSave(worldPath)
+		if err != nil {
+			return fmt.Errorf("failed to load world: %w", err)
+		}
+		w = headless.FromSave(data)
+	} else {
+		config := terrain.DefaultConfig()
+		config.FlatLayers = layers
+		w = headless.NewWorld(seed, preset, config)
+	}
+
+	fmt.Printf("[Export] Meshing chunks %d,%d to %d,%d (seed %d)\n", minCX, minCZ, maxCX, maxCZ, w.Seed)
+	chunks := w.LoadRegion(minCX, minCZ, maxCX, maxCZ)
+
+	// Place the region's minimum corner at the origin
+	origin := [3]float32{float32(minCX * chunk.Size), 0, float32(minCZ * chunk.Size)}
+	mesh := export.BuildMesh(chunks, w.GetBlock, export.Options{
+		Textured: textured,
+		Origin:   origin,
+	})
+	if mesh.IsEmpty() {
+		return fmt.Errorf("region contains no visible blocks")
+	}
+
+	switch format {
+	case ".obj":
+		err = export.WriteOBJ(out, mesh)
+	case ".gltf":
+		err = export.WriteGLTF(out, mesh, false)
+	case ".glb":
+		err = export.WriteGLTF(out, mesh, true)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", out, err)
+	}
+
+	triangles := 0
+	for _, p := range mesh.Primitives {
+		triangles += len(p.Indices) / 3
+	}
+	fmt.Printf("[Export] Wrote %s: %d triangles in %d materials, %gx%gx%g blocks\n",
+		out, triangles, len(mesh.Primitives),
+		mesh.Max[0]-mesh.Min[0], mesh.Max[1]-mesh.Min[1], mesh.Max[2]-mesh.Min[2])
+	return nil
+}
+
+// parseRegion returns the inclusive chunk range from either -from/-to or -center/-radius
+func parseRegion(from, to, center string, radius int) (minCX, minCZ, maxCX, maxCZ int, err error) {
+	if from != "" || to != "" {
+		if from == "" || to == "" {
+			return 0, 0, 0, 0, fmt.Errorf("-from and -to must be given together")
+		}
+		x0, z0, err := parseChunkCoord(from)
+		if err != nil {
+			return 0, 0, 0, 0, err
+		}
+		x1, z1, err := parseChunkCoord(to)
+		if err != nil {
+			return 0, 0, 0, 0, err
+		}
+		return min(x0, x1), min(z0, z1), max(x0, x1), max(z0, z1), nil
+	}
+
+	if radius < 0 {
+		return 0, 0, 0, 0, fmt.Errorf("radius must not be negative")
+	}
+	cx, cz, err := parseChunkCoord(center)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return cx - radius, cz - radius, cx + radius, cz + radius, nil
+}
+
+// parseChunkCoord parses "cx,cz"
+func parseChunkCoord(s string) (int, int, error) {
+	var cx, cz int
+	if _, err := fmt.Sscanf(s, "%d,%d", &cx, &cz); err != nil {
+		return 0, 0, fmt.Errorf("invalid chunk coordinate %q, expected cx,cz", s)
+	}
+	return cx, cz, nil
+}
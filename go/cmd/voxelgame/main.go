@@ -4,10 +4,12 @@ package main
 
 import (
 	"fmt"
-	"math"
+	"hash/fnv"
 	"math/rand/v2"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
@@ -37,7 +39,6 @@ type Game struct {
 	engine           *render.Engine
 	world            *world.World
 	player           *physics.Player
-	playerModel      *entity.Creature
 	movement         *physics.EnhancedMovement
 	sky              *render.Sky
 	postProcess      *render.PostProcess
@@ -59,7 +60,8 @@ type Game struct {
 	minimap      *ui.Minimap
 
 	// Save system
-	saveManager *save.Manager
+	worldStore  *save.WorldStore
+	worldSelect *ui.WorldSelectScreen
 
 	// Config
 	screenWidth  int
@@ -145,11 +147,13 @@ func NewGame() (*Game, error) {
 			"R - Raytracing",
 			"LMB - Break",
 			"RMB - Place",
+			"Q - Throw Item",
 			"1-9 - Hotbar",
 			"I - Inventario",
 			"H - Toggle Help",
 			"F3 - Debug Info",
 			"F5 - Quick Save",
+			"F6 - Next Weather",
 			"F9 - Quick Load",
 			"ESC - Pause",
 		},
@@ -197,8 +201,11 @@ func NewGame() (*Game, error) {
 	// Create minimap
 	g.minimap = ui.NewMinimap(128)
 
-	// Create save manager
-	g.saveManager = save.NewManager()
+	// Create world store, bringing over saves from before named worlds
+	g.worldStore = save.NewWorldStore()
+	if _, err := g.worldStore.ImportLegacySaves(save.NewManager()); err != nil {
+		fmt.Printf("Warning: Failed to import old saves: %v\n", err)
+	}
 
 	// Create sky renderer
 	sky, err := render.NewSky()
@@ -266,6 +273,14 @@ func (g *Game) setupMenus() {
 		g.quitGame,     // Quit
 	)
 
+	g.worldSelect = ui.NewWorldSelectScreen()
+	g.worldSelect.OnPlay = g.playWorld
+	g.worldSelect.OnCreate = g.createWorld
+	g.worldSelect.OnRename = g.renameWorld
+	g.worldSelect.OnDuplicate = g.duplicateWorld
+	g.worldSelect.OnDelete = g.deleteWorld
+	g.worldSelect.OnBack = g.closeWorldSelect
+
 	g.pauseMenu = ui.NewPauseMenu(
 		g.resumeGame,       // Resume
 		g.openSettings,     // Settings
@@ -276,13 +291,79 @@ func (g *Game) setupMenus() {
 
 // Menu callbacks
 func (g *Game) startNewGame() {
-	fmt.Println("[DEBUG] startNewGame called")
+	g.openWorldSelect()
+	g.worldSelect.BeginCreate()
+}
+
+func (g *Game) loadGame() {
+	g.openWorldSelect()
+}
+
+// openWorldSelect shows the world selection screen
+func (g *Game) openWorldSelect() {
+	g.refreshWorldList()
+	g.worldSelect.Mode = ui.WorldSelectBrowse
+	g.worldSelect.Message = ""
+	g.worldSelect.IsVisible = true
+	g.mainMenu.IsVisible = false
+	g.stateManager.SetState(ui.StateWorldSelect)
+}
+
+// closeWorldSelect returns from the world selection screen to the main menu
+func (g *Game) closeWorldSelect() {
+	g.worldSelect.IsVisible = false
+	g.worldSelect.ClearThumbnails()
+	g.mainMenu.IsVisible = true
+	g.stateManager.SetState(ui.StateMainMenu)
+}
+
+// refreshWorldList reloads the world list and thumbnails from disk
+func (g *Game) refreshWorldList() {
+	worlds, err := g.worldStore.List()
+	if err != nil {
+		fmt.Printf("Failed to list worlds: %v\n", err)
+	}
+	g.worldSelect.SetWorlds(worlds)
 
-	// Create world with random seed
-	seed := time.Now().UnixNano()
+	g.worldSelect.ClearThumbnails()
+	for _, info := range worlds {
+		if !info.HasThumbnail {
+			continue
+		}
+		img, err := g.worldStore.LoadThumbnail(info.ID)
+		if err != nil {
+			continue
+		}
+		g.worldSelect.Thumbnails[info.ID] = ui.NewImageTexture(img)
+	}
+}
+
+// createWorld creates a named world and starts playing it
+func (g *Game) createWorld(name, seedText, layers string) {
+	seed := parseSeed(seedText)
 	fmt.Printf("World seed: %d\n", seed)
 
-	g.world = world.NewWorld(seed)
+	config := g.terrainConfig()
+	if g.terrainPreset() == terrain.PresetSuperflat {
+		if _, err := terrain.ParseFlatLayers(layers); err != nil {
+			g.worldSelect.Message = fmt.Sprintf("Invalid layers: %v", err)
+			return
+		}
+		config.FlatLayers = layers
+	}
+
+	info, err := g.worldStore.Create(name, seed)
+	if err != nil {
+		g.worldSelect.Message = fmt.Sprintf("Failed to create world: %v", err)
+		return
+	}
+
+	// Terrain settings are captured here and fixed for the world's lifetime
+	g.world = world.NewWorldWithConfig(seed, g.terrainPreset(), config)
+	g.attachWorld(info)
+
+	// Fresh inventory for the new world
+	g.inventory = ui.NewInventory()
 
 	// Get spawn position
 	spawnX, spawnY, spawnZ := g.world.GetSpawnPosition()
@@ -294,48 +375,46 @@ func (g *Game) startNewGame() {
 		return g.world.GetBlock(x, y, z)
 	})
 
-	// Create player model for 3rd person view
-	gen := entity.NewGenerator(seed)
-	g.playerModel = gen.Create(entity.TemplateBiped, "plains", spawnPos, 1.0)
-	g.playerModel.PrimaryColor = [3]float32{0.2, 0.2, 0.8}
-	g.playerModel.SecondaryColor = [3]float32{0.1, 0.1, 0.1}
-
 	// Create demo campfire structure
 	// Ground height at 5,5
 	fireX, fireZ := 5, 5
 	fireY := g.world.GetHeight(fireX, fireZ)
-	// Clear area
-	g.world.SetBlock(fireX, fireY+1, fireZ, block.Air)
-	g.world.SetBlock(fireX, fireY+2, fireZ, block.Air)
-	// Base
-	g.world.SetBlock(fireX, fireY, fireZ, block.Cobblestone)
-	// "Logs" for fire (using OakLog horizontally if possible, but just block for now)
-	// Since we don't have rotation yet, just place a log.
-	// Or maybe 4 logs around a center fire?
-	// Simple: Just a log block with fire on top.
-	g.world.SetBlock(fireX, fireY+1, fireZ, block.OakLog)
+	if fireY > 0 { // Void worlds have no ground there
+		// Clear area
+		g.world.SetBlock(fireX, fireY+1, fireZ, block.Air)
+		g.world.SetBlock(fireX, fireY+2, fireZ, block.Air)
+		// Base
+		g.world.SetBlock(fireX, fireY, fireZ, block.Cobblestone)
+		// "Logs" for fire (using OakLog horizontally if possible, but just block for now)
+		// Since we don't have rotation yet, just place a log.
+		// Or maybe 4 logs around a center fire?
+		// Simple: Just a log block with fire on top.
+		g.world.SetBlock(fireX, fireY+1, fireZ, block.OakLog)
+	}
 
 	// Create enhanced movement
 	g.movement = physics.NewEnhancedMovement()
 
-	// Switch to playing state
-	g.stateManager.SetState(ui.StatePlaying)
-	g.mainMenu.IsVisible = false
+	// Write the initial save right away so the world's generator config is on disk
+	if err := g.world.Save(save.LevelSaveName); err != nil {
+		fmt.Printf("Failed to save new world: %v\n", err)
+	}
 
-	// Capture mouse for FPS controls
-	g.engine.SetCursorMode(true)
+	g.enterWorld()
 	fmt.Println("[DEBUG] Entered playing state")
 }
 
-func (g *Game) loadGame() {
-	data, err := g.saveManager.QuickLoad()
+// playWorld loads a named world and starts playing it
+func (g *Game) playWorld(info save.WorldInfo) {
+	data, err := g.worldStore.Manager(info.ID).Load(save.LevelSaveName)
 	if err != nil {
-		fmt.Printf("Failed to load: %v\n", err)
+		g.worldSelect.Message = fmt.Sprintf("Failed to load %s: %v", info.Name, err)
 		return
 	}
 
-	// Create world with saved seed
-	g.world = world.NewWorld(data.World.Seed)
+	// Create world with saved seed and restore modifications, time and creatures
+	g.world = world.NewWorldFromSave(data)
+	g.attachWorld(&info)
 
 	// Create player at saved position
 	spawnPos := mgl32.Vec3{data.Player.PositionX, data.Player.PositionY, data.Player.PositionZ}
@@ -345,25 +424,111 @@ func (g *Game) loadGame() {
 	g.player.Yaw = data.Player.Yaw
 	g.player.Pitch = data.Player.Pitch
 
-	// Create player model for 3rd person view
-	gen := entity.NewGenerator(data.World.Seed)
-	g.playerModel = gen.Create(entity.TemplateBiped, "plains", spawnPos, 1.0)
-	g.playerModel.PrimaryColor = [3]float32{0.2, 0.2, 0.8}
-	g.playerModel.SecondaryColor = [3]float32{0.1, 0.1, 0.1}
-
 	// Create enhanced movement
 	g.movement = physics.NewEnhancedMovement()
 
+	// Restore movement, camera and inventory (missing in older saves)
+	if m := data.Player.Movement; m != nil {
+		g.player.IsFlying = m.IsFlying
+		g.movement.IsCrouching = m.IsCrouching
+		g.movement.Stamina = m.Stamina
+		g.engine.GetCamera().ThirdPerson = m.ThirdPerson
+	}
+	g.inventory = ui.NewInventory()
+	if data.Player.Inventory != nil {
+		g.inventory.RestoreState(*data.Player.Inventory)
+	}
+
+	if err := g.worldStore.RecordPlay(info.ID, info.PlayTime); err != nil {
+		fmt.Printf("Failed to update world info: %v\n", err)
+	}
+
+	g.enterWorld()
+	fmt.Println("[DEBUG] Game loaded, entered playing state")
+}
+
+// attachWorld connects the current world to its directory in the world store
+func (g *Game) attachWorld(info *save.WorldInfo) {
+	id := info.ID
+	store := g.worldStore
+
+	g.world.SaveManager = store.Manager(id)
+	g.world.UsePregenerated(store.ChunkDir(id))
+	g.world.PlayTime = info.PlayTime
+	g.world.PlayerState = g.playerSaveState
+	g.world.ConfigureAutosave(g.autosaveConfig())
+	g.world.OnLightning = func(x, y, z int) {
+		g.engine.EmitLightning(mgl32.Vec3{float32(x) + 0.5, float32(y) + 1, float32(z) + 0.5})
+	}
+	g.world.OnPickup = func(item block.Type, count int) bool {
+		return g.inventory.AddBlock(item, count)
+	}
+
+	// Keep the world list details and thumbnail in step with the save
+	g.world.OnSaved = func(snapshot world.WorldSnapshot) {
+		if err := store.RecordPlay(id, snapshot.PlayTime); err != nil {
+			fmt.Printf("Failed to update world info: %v\n", err)
+		}
+		if snapshot.Thumbnail != nil {
+			if err := store.SaveThumbnail(id, snapshot.Thumbnail); err != nil {
+				fmt.Printf("Failed to save thumbnail: %v\n", err)
+			}
+		}
+	}
+}
+
+// enterWorld leaves the menus and starts playing the loaded world
+func (g *Game) enterWorld() {
+	g.worldSelect.IsVisible = false
+	g.worldSelect.ClearThumbnails()
+
 	// Switch to playing state
 	g.stateManager.SetState(ui.StatePlaying)
 	g.mainMenu.IsVisible = false
 
 	// Capture mouse for FPS controls
 	g.engine.SetCursorMode(true)
-	fmt.Println("[DEBUG] Game loaded, entered playing state")
+}
+
+func (g *Game) renameWorld(info save.WorldInfo, name string) {
+	if err := g.worldStore.Rename(info.ID, name); err != nil {
+		g.worldSelect.Message = fmt.Sprintf("Failed to rename: %v", err)
+	}
+	g.refreshWorldList()
+}
+
+func (g *Game) duplicateWorld(info save.WorldInfo) {
+	if _, err := g.worldStore.Duplicate(info.ID, ""); err != nil {
+		g.worldSelect.Message = fmt.Sprintf("Failed to copy: %v", err)
+	}
+	g.refreshWorldList()
+}
+
+func (g *Game) deleteWorld(info save.WorldInfo) {
+	if err := g.worldStore.Delete(info.ID); err != nil {
+		g.worldSelect.Message = fmt.Sprintf("Failed to delete: %v", err)
+	}
+	g.refreshWorldList()
+}
+
+// parseSeed turns the seed typed when creating a world into a number.
+// Empty means random; text that isn't a number is hashed.
+func parseSeed(text string) int64 {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return time.Now().UnixNano()
+	}
+	if seed, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return seed
+	}
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return int64(h.Sum64())
 }
 
 func (g *Game) openSettings() {
+	// Terrain settings belong to the world once it exists
+	g.settingsMenu.WorldLoaded = g.world != nil
 	g.stateManager.SetState(ui.StateSettings)
 	g.settingsMenu.IsVisible = true
 }
@@ -385,21 +550,64 @@ func (g *Game) saveGame() {
 		return
 	}
 
-	err := g.saveManager.QuickSave(save.PlayerSave{
+	// Serialize and write in the background so the frame doesn't hitch
+	if !g.world.SaveAsync(save.LevelSaveName) {
+		fmt.Println("Save already in progress")
+	}
+}
+
+// playerSaveState returns the current player state for saving
+func (g *Game) playerSaveState() save.PlayerSave {
+	if g.player == nil {
+		return save.PlayerSave{}
+	}
+	state := save.PlayerSave{
 		PositionX: g.player.Position.X(),
 		PositionY: g.player.Position.Y(),
 		PositionZ: g.player.Position.Z(),
 		Yaw:       g.player.Yaw,
 		Pitch:     g.player.Pitch,
-	}, g.world.Seed)
+		Movement: &save.MovementSave{
+			IsFlying:    g.player.IsFlying,
+			ThirdPerson: g.engine.GetCamera().ThirdPerson,
+		},
+	}
+	if g.movement != nil {
+		state.Movement.IsCrouching = g.movement.IsCrouching
+		state.Movement.Stamina = g.movement.Stamina
+	}
+	if g.inventory != nil {
+		inv := g.inventory.SaveState()
+		state.Inventory = &inv
+	}
+	return state
+}
 
-	if err != nil {
-		fmt.Printf("Failed to save: %v\n", err)
-	} else {
-		fmt.Println("Game saved!")
+// terrainConfig builds the terrain config for new worlds from settings
+func (g *Game) terrainConfig() terrain.GeneratorConfig {
+	return terrain.GeneratorConfig{
+		SeaLevel:         g.settings.SeaLevel,
+		TerrainAmplitude: g.settings.TerrainAmplitude,
+		TreeDensity:      g.settings.TreeDensity,
+		CaveFrequency:    g.settings.CaveFrequency,
 	}
 }
 
+// terrainPreset returns the terrain preset chosen in settings
+func (g *Game) terrainPreset() string {
+	return g.settings.WorldType
+}
+
+// autosaveConfig builds the world autosave config from settings
+func (g *Game) autosaveConfig() world.AutosaveConfig {
+	config := world.DefaultAutosaveConfig()
+	config.SaveName = save.LevelSaveName
+	config.Enabled = g.settings.AutosaveEnabled
+	config.Interval = g.settings.AutosaveInterval
+	config.SaveOnPause = g.settings.SaveOnPause
+	return config
+}
+
 func (g *Game) returnToMainMenu() {
 	fmt.Println("[DEBUG] returnToMainMenu called")
 	// Cleanup world
@@ -444,6 +652,8 @@ func (g *Game) Update(dt float32) {
 		g.updatePaused(input)
 	case ui.StateSettings:
 		g.updateSettings(input)
+	case ui.StateWorldSelect:
+		g.updateWorldSelect(input)
 	}
 }
 
@@ -459,6 +669,54 @@ func (g *Game) updateMainMenu(input *render.Input) {
 	}
 }
 
+func (g *Game) updateWorldSelect(input *render.Input) {
+	ws := g.worldSelect
+	text := input.ConsumeText()
+
+	if g.wasKeyJustPressed(input, glfw.KeyEscape) {
+		ws.Cancel()
+		return
+	}
+	if g.wasKeyJustPressed(input, glfw.KeyEnter) {
+		ws.Confirm()
+		return
+	}
+
+	// Dialogs with text fields take typed characters instead of shortcuts
+	if ws.IsEditingText() {
+		ws.TypeText(text)
+		if g.wasKeyJustPressed(input, glfw.KeyBackspace) {
+			ws.Backspace()
+		}
+		if g.wasKeyJustPressed(input, glfw.KeyTab) {
+			ws.NextField()
+		}
+		return
+	}
+	if ws.Mode != ui.WorldSelectBrowse {
+		return
+	}
+
+	if g.wasKeyJustPressed(input, glfw.KeyUp) || g.wasKeyJustPressed(input, glfw.KeyW) {
+		ws.SelectPrevious()
+	}
+	if g.wasKeyJustPressed(input, glfw.KeyDown) || g.wasKeyJustPressed(input, glfw.KeyS) {
+		ws.SelectNext()
+	}
+	if g.wasKeyJustPressed(input, glfw.KeyN) {
+		ws.BeginCreate()
+	}
+	if g.wasKeyJustPressed(input, glfw.KeyR) {
+		ws.BeginRename()
+	}
+	if g.wasKeyJustPressed(input, glfw.KeyC) {
+		ws.Duplicate()
+	}
+	if g.wasKeyJustPressed(input, glfw.KeyDelete) {
+		ws.BeginDelete()
+	}
+}
+
 func (g *Game) updatePlaying(input *render.Input, dt float32) {
 	if g.player == nil || g.world == nil {
 		return
@@ -467,6 +725,7 @@ func (g *Game) updatePlaying(input *render.Input, dt float32) {
 	// Pause
 	if g.wasKeyJustPressed(input, glfw.KeyEscape) || g.wasKeyJustPressed(input, glfw.KeyP) {
 		fmt.Println("[DEBUG] Pausing game")
+		g.world.OnPause()
 		g.stateManager.SetState(ui.StatePaused)
 		g.pauseMenu.IsVisible = true
 		g.engine.SetCursorMode(false) // Show cursor for menu
@@ -515,6 +774,18 @@ func (g *Game) updatePlaying(input *render.Input, dt float32) {
 		g.saveGame()
 	}
 
+	// Cycle weather (F6)
+	if g.wasKeyJustPressed(input, glfw.KeyF6) {
+		next := (g.world.Weather.State + 1) % (world.WeatherSnow + 1)
+		g.world.Weather.Set(next)
+		fmt.Printf("Weather: %s\n", next)
+	}
+
+	// Throw the selected item (Q)
+	if g.wasKeyJustPressed(input, glfw.KeyQ) {
+		g.throwItem()
+	}
+
 	// Quick load (F9)
 	if g.wasKeyJustPressed(input, glfw.KeyF9) {
 		g.loadGame()
@@ -634,27 +905,10 @@ func (g *Game) updatePlaying(input *render.Input, dt float32) {
 	// Update player physics
 	g.player.Update(dt)
 
-	// Update player model animation state
-	if g.playerModel != nil {
-		g.playerModel.AnimationTime += dt
-
-		// Calculate horizontal speed
-		vel := g.player.Velocity
-		speed := float32(math.Sqrt(float64(vel.X()*vel.X() + vel.Z()*vel.Z())))
-
-		g.playerModel.IsMoving = speed > 0.1 && g.player.IsOnGround && !g.player.IsFlying
-		if g.playerModel.IsMoving {
-			// Update walk phase based on speed
-			// 3.0 is a multiplier to match the step speed with movement
-			g.playerModel.WalkPhase += dt * speed * 3.0
-			if g.playerModel.WalkPhase > 2*math.Pi {
-				g.playerModel.WalkPhase -= 2 * math.Pi
-			}
-		} else {
-			// Decay walk phase smoothly when stopping
-			g.playerModel.WalkPhase *= 0.8
-		}
-	}
+	// Move the player's entity with the physics; the world animates it.
+	// Player yaw 0 faces +X, model rotation 0 faces +Z.
+	g.world.SyncPlayer(g.player.GetFeetPosition(), mgl32.DegToRad(-g.player.Yaw+90),
+		g.player.Velocity, g.player.IsOnGround && !g.player.IsFlying)
 
 	// === UPDATE CAMERA FROM PLAYER AFTER ALL UPDATES ===
 	camera := g.engine.GetCamera()
@@ -664,9 +918,11 @@ func (g *Game) updatePlaying(input *render.Input, dt float32) {
 	// Update Minimap
 	if g.minimap != nil && g.world != nil {
 		creatures := make([]mgl32.Vec3, 0)
-		for _, c := range g.world.CreatureManager.GetCreatures() {
-			creatures = append(creatures, c.Position)
-		}
+		g.world.Entities.Brains.Each(func(id entity.ID, _ *entity.Brain) {
+			if t := g.world.Entities.Transforms.Get(id); t != nil {
+				creatures = append(creatures, t.Position)
+			}
+		})
 		g.minimap.Update(g.player.Position, g.world.GetBiomeAt, g.world.GetHeight, creatures)
 	}
 
@@ -680,6 +936,11 @@ func (g *Game) updatePlaying(input *render.Input, dt float32) {
 	// Update sky
 	if g.sky != nil {
 		g.sky.Update(dt)
+		// The sky shows the world's clock, on the solar hour so the
+		// sun keeps its path as days lengthen and shorten
+		g.sky.SetTime(g.world.TimeOfDay.SolarHour(), g.world.TimeOfDay.Day)
+		g.sky.Overcast = g.world.TimeOfDay.Overcast
+		g.sky.Flash = g.world.Weather.Flash
 	}
 
 	// Update atmospheric particles based on biome and time of day
@@ -690,6 +951,20 @@ func (g *Game) updatePlaying(input *render.Input, dt float32) {
 		g.engine.UpdateAtmosphericParticles(playerPos, biome, timeOfDay)
 	}
 
+	// Update rain and snow where the weather falls at the player
+	if g.world != nil {
+		playerPos := g.player.Position
+		local := g.world.LocalWeather(int(playerPos.X()), int(playerPos.Z()))
+		intensity := float32(0)
+		switch local {
+		case world.WeatherRain, world.WeatherSnow:
+			intensity = g.world.Weather.Intensity() * 0.6
+		case world.WeatherStorm:
+			intensity = g.world.Weather.Intensity()
+		}
+		g.engine.UpdatePrecipitation(playerPos, local == world.WeatherSnow, intensity, g.world.GetHeight)
+	}
+
 	// Update water particles (bubbles, fish)
 	if g.world != nil {
 		waterSurfaceY := float32(12) // Sea level
@@ -701,17 +976,6 @@ func (g *Game) updatePlaying(input *render.Input, dt float32) {
 	campfirePos := mgl32.Vec3{5, float32(g.world.GetHeight(5, 5)) + 2.0, 5}
 	g.engine.EmitCampfire(campfirePos)
 
-	// Update animation state
-	if isMoving {
-		g.playerModel.IsMoving = true
-		// Walk phase updated in Render or Update of creature, but for manual control:
-		speed := float32(6.0)                                // Approx speed
-		g.playerModel.Velocity = mgl32.Vec3{speed, 0, speed} // Hack to trigger animation logic
-	} else {
-		g.playerModel.IsMoving = false
-		g.playerModel.Velocity = mgl32.Vec3{0, 0, 0}
-	}
-
 	// Raycast for block selection
 	lookDir := g.player.GetLookDirection()
 	result := physics.Raycast(g.player.Position, lookDir, 5.0, func(x, y, z int) block.Type {
@@ -727,16 +991,11 @@ func (g *Game) updatePlaying(input *render.Input, dt float32) {
 	if g.blockBreaker != nil {
 		lmbPressed := input.IsMouseButtonPressed(glfw.MouseButtonLeft)
 
-		// Handle swing animation state
-		if lmbPressed {
-			g.playerModel.IsSwinging = true
-		} else {
-			g.playerModel.IsSwinging = false
+		// Swing the player's arm while breaking
+		if m := g.world.Entities.Renderables.Get(g.world.Player); m != nil {
+			m.Swinging = lmbPressed
 		}
 
-		// Update player model (handles animation timers)
-		g.playerModel.Update(dt, g.playerModel.Position)
-
 		if lmbPressed {
 			// We have a target block and LMB is pressed
 			if g.targetBlock != nil {
@@ -784,7 +1043,7 @@ func (g *Game) updatePlaying(input *render.Input, dt float32) {
 							breakTime /= 4.0 // 4x faster
 						}
 					case block.Axe:
-						if targetType == block.Wood || targetType == block.OakLog || targetType == block.BirchLog || targetType == block.SpruceLog {
+						if targetType == block.Wood || targetType == block.OakLog || targetType == block.BirchLog || targetType == block.SpruceLog || targetType == block.JungleLog {
 							breakTime /= 4.0 // 4x faster
 						}
 					}
@@ -798,10 +1057,8 @@ func (g *Game) updatePlaying(input *render.Input, dt float32) {
 						destroyedBlock := g.world.GetBlock(targetPos[0], targetPos[1], targetPos[2])
 						blockColor := destroyedBlock.GetColor()
 
-						// Add to inventory
-						if destroyedBlock != block.Air {
-							g.inventory.AddBlock(destroyedBlock, 1)
-						}
+						// Drop it to be picked up
+						g.dropBlock(destroyedBlock, targetPos[0], targetPos[1], targetPos[2])
 
 						// Destroy block
 						g.world.SetBlock(targetPos[0], targetPos[1], targetPos[2], block.Air)
@@ -842,10 +1099,8 @@ func (g *Game) updatePlaying(input *render.Input, dt float32) {
 		destroyedBlock := g.world.GetBlock(targetPos[0], targetPos[1], targetPos[2])
 		blockColor := destroyedBlock.GetColor()
 
-		// Add to inventory
-		if destroyedBlock != block.Air {
-			g.inventory.AddBlock(destroyedBlock, 1)
-		}
+		// Drop it to be picked up
+		g.dropBlock(destroyedBlock, targetPos[0], targetPos[1], targetPos[2])
 
 		// Destroy block
 		g.world.SetBlock(targetPos[0], targetPos[1], targetPos[2], block.Air)
@@ -891,6 +1146,34 @@ func (g *Game) updatePlaying(input *render.Input, dt float32) {
 	}
 }
 
+// throwSpeed is how fast thrown items leave the hand, in blocks per second
+const throwSpeed = 15
+
+// throwItem throws one of the selected item where the player looks
+func (g *Game) throwItem() {
+	item := g.inventory.GetSelectedBlock()
+	if item == block.Air || !g.inventory.RemoveBlock() {
+		return
+	}
+	dir := g.player.GetLookDirection()
+	g.world.ThrowItem(item, g.player.Position.Add(dir.Mul(0.5)), dir.Mul(throwSpeed).Add(g.player.Velocity))
+}
+
+// dropBlock drops what breaking a block yields for the player to pick up.
+// Chests yield their loot, spawners yield nothing.
+func (g *Game) dropBlock(t block.Type, x, y, z int) {
+	pos := mgl32.Vec3{float32(x) + 0.5, float32(y), float32(z) + 0.5}
+	switch t {
+	case block.Air, block.Spawner:
+	case block.Chest:
+		for _, stack := range g.world.TerrainGenerator.ChestLoot(x, y, z) {
+			g.world.DropItem(stack.Item, stack.Count, pos)
+		}
+	default:
+		g.world.DropItem(t, 1, pos)
+	}
+}
+
 func (g *Game) updatePaused(input *render.Input) {
 	if g.wasKeyJustPressed(input, glfw.KeyEscape) || g.wasKeyJustPressed(input, glfw.KeyP) {
 		g.resumeGame()
@@ -939,13 +1222,8 @@ func (g *Game) updateSettings(input *render.Input) {
 		g.postProcess.BloomStrength = g.settings.BloomStrength
 	}
 	if g.world != nil {
-		terrainConfig := terrain.GeneratorConfig{
-			SeaLevel:         g.settings.SeaLevel,
-			TerrainAmplitude: g.settings.TerrainAmplitude,
-			TreeDensity:      g.settings.TreeDensity,
-			CaveFrequency:    g.settings.CaveFrequency,
-		}
-		g.world.ApplySettings(g.settings.DayDuration, g.settings.NightBrightness, terrainConfig)
+		g.world.ApplySettings(g.settings.DayDuration, g.settings.NightBrightness)
+		g.world.ConfigureAutosave(g.autosaveConfig())
 	}
 }
 
@@ -978,6 +1256,8 @@ func (g *Game) Render() {
 			g.renderPlaying()
 		}
 		g.renderSettings()
+	case ui.StateWorldSelect:
+		g.renderWorldSelect()
 	}
 }
 
@@ -989,6 +1269,14 @@ func (g *Game) renderMainMenu() {
 	}
 }
 
+func (g *Game) renderWorldSelect() {
+	if g.uiRenderer != nil {
+		g.uiRenderer.BeginFrame()
+		g.menuRenderer.RenderWorldSelect(g.worldSelect, g.screenWidth, g.screenHeight)
+		g.uiRenderer.EndFrame()
+	}
+}
+
 func (g *Game) renderPlaying() {
 	if g.world == nil {
 		return
@@ -1038,6 +1326,12 @@ func (g *Game) renderPlaying() {
 			}
 		}
 
+		// Clouds dim and gray the light, lightning brightens it
+		tod := g.world.TimeOfDay
+		sunIntensity = tod.OvercastLight(sunIntensity) + g.world.Weather.Flash*0.8
+		skyColor = tod.OvercastColor(skyColor)
+		ambientColor = tod.OvercastColor(ambientColor)
+
 		g.engine.UseVoxelShaderWithTime(render.TimeOfDayData{
 			SunDirection: sunDir,
 			SunIntensity: sunIntensity,
@@ -1060,24 +1354,15 @@ func (g *Game) renderPlaying() {
 			if g.sky != nil {
 				sunDir = g.sky.GetSunDirection()
 			}
-			g.creatureRenderer.RenderCreatures(g.world.GetCreatures(), view, projection, sunDir)
-
-			if g.engine.GetCamera().ThirdPerson && g.playerModel != nil {
-				// Update player model position and rotation
-				// Player position is eye height, model position is feet
-				feetPos := g.player.GetFeetPosition()
-				g.playerModel.Position = feetPos
-				// Convert yaw to radians and invert/adjust as needed keying off standard math
-				// Player Yaw: 0 = +X, 90 = +Z?
-				// MathGL RotateY: CCW rotation around Y
-				// We need to match the camera yaw
-				g.playerModel.Rotation = mgl32.DegToRad(-g.player.Yaw + 90)
-
-				// Update held item
-				g.playerModel.HeldItem = g.inventory.GetSelectedBlock()
-
-				g.creatureRenderer.RenderCreature(g.playerModel, view, projection, sunDir)
+			// The player's own model is only seen in third person
+			hidden := g.world.Player
+			if g.engine.GetCamera().ThirdPerson {
+				hidden = 0
+			}
+			if inv := g.world.Entities.Inventories.Get(g.world.Player); inv != nil {
+				inv.Held = g.inventory.GetSelectedBlock()
 			}
+			g.creatureRenderer.RenderEntities(g.world.Entities, hidden, view, projection, sunDir)
 		}
 
 		// Render block outline
@@ -1118,7 +1403,11 @@ func (g *Game) renderPlaying() {
 			sunDir = g.sky.GetSunDirection()
 		}
 		// Use player model's swing phase for view model animation
-		g.creatureRenderer.RenderViewModel(selectedItem, float32(glfw.GetTime()), g.playerModel.SwingPhase, viewProj, sunDir)
+		var swingPhase float32
+		if m := g.world.Entities.Renderables.Get(g.world.Player); m != nil {
+			swingPhase = m.SwingPhase
+		}
+		g.creatureRenderer.RenderViewModel(selectedItem, float32(glfw.GetTime()), swingPhase, viewProj, sunDir)
 
 		// Hotbar with item counts and names
 		hotbarSlots := g.inventory.GetHotbarSlots()
@@ -1153,6 +1442,19 @@ func (g *Game) renderPlaying() {
 			g.uiRenderer.DrawTimeIndicator(g.world.TimeOfDay.GetTimeString())
 		}
 
+		// Save indicator
+		if g.world != nil {
+			status := g.world.GetSaveStatus()
+			recent := !status.LastSave.IsZero() && time.Since(status.LastSave) < 2*time.Second
+			if status.InProgress {
+				g.uiRenderer.DrawSaveIndicator("Saving...")
+			} else if recent && status.LastError != nil {
+				g.uiRenderer.DrawSaveIndicator("Save failed")
+			} else if recent {
+				g.uiRenderer.DrawSaveIndicator("Game saved")
+			}
+		}
+
 		// Raytracing indicator
 		if g.settings.EnableRaytracing {
 			g.uiRenderer.DrawRect(10, float32(g.screenHeight-40), 120, 25, [4]float32{1, 0.5, 0, 0.8})
@@ -1231,11 +1533,17 @@ func (g *Game) renderSettings() {
 			}
 
 			// Setting Name
+			locked := g.settingsMenu.IsLocked(&g.settingsMenu.Items[i])
 			nameColor := [4]float32{0.8, 0.8, 0.8, 1}
 			if i == g.settingsMenu.SelectedIndex {
 				nameColor = [4]float32{1, 1, 1, 1}
 			}
-			g.uiRenderer.DrawText(panelX+40, itemY+10, 2.0, item.Name, nameColor)
+			name := item.Name
+			if locked {
+				nameColor = [4]float32{0.5, 0.5, 0.5, 1}
+				name += " (fixed for this world)"
+			}
+			g.uiRenderer.DrawText(panelX+40, itemY+10, 2.0, name, nameColor)
 
 			// Setting Value
 			valueStr := ""
@@ -1258,12 +1566,19 @@ func (g *Game) renderSettings() {
 				if v, ok := g.getSettingValue(item.Name).(float32); ok {
 					valueStr = fmt.Sprintf("%.2f", v)
 				}
+			case ui.SettingOption:
+				if v, ok := g.getSettingValue(item.Name).(string); ok {
+					valueStr = v
+				}
 			}
 
 			valueColor := [4]float32{0.3, 0.8, 0.3, 1} // Greenish
 			if valueStr == "OFF" {
 				valueColor = [4]float32{0.8, 0.3, 0.3, 1} // Reddish
 			}
+			if locked {
+				valueColor = [4]float32{0.5, 0.5, 0.5, 1} // Greyed out
+			}
 
 			// Align value to right
 			valWidth := float32(len(valueStr) * 10) // Approx
@@ -1304,6 +1619,33 @@ func (g *Game) getSettingValue(name string) interface{} {
 		return g.settings.InvertY
 	case "VSync":
 		return g.settings.VSync
+	case "Autosave":
+		return g.settings.AutosaveEnabled
+	case "Autosave Interval (sec)":
+		return g.settings.AutosaveInterval
+	case "Save On Pause":
+		return g.settings.SaveOnPause
+	}
+
+	// Terrain settings show the loaded world's values, which can't change
+	config := g.terrainConfig()
+	if g.world != nil {
+		config = g.world.GetGeneratorConfig()
+	}
+	switch name {
+	case "Terrain Amplitude":
+		return config.TerrainAmplitude
+	case "Sea Level":
+		return config.SeaLevel
+	case "Tree Density":
+		return config.TreeDensity
+	case "Cave Frequency":
+		return config.CaveFrequency
+	case "World Type":
+		if g.world != nil {
+			return g.world.Preset
+		}
+		return g.settings.WorldType
 	}
 	return nil
 }
@@ -0,0 +1,231 @@
+// Command voxelmap renders top-down PNG maps of a world without starting
+// the game, and can pre-generate a world's chunks for a faster first load.
+//
+// Usage:
+//
+//	voxelmap -seed 1234 -radius 16 -biomes -out map.png
+//	voxelmap -world ~/.voxelgame/worlds/my-world -center 4,-2 -radius 12 -pregen
+//	voxelmap -seed 1234 -skip caves,dungeons -timings
+//	voxelmap -seed 1234 -set caves.frequency=2,ores.veins=0.5
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"voxelgame/internal/core/chunk"
+	"voxelgame/internal/generation/terrain"
+	"voxelgame/internal/headless"
+	"voxelgame/internal/save"
+	"voxelgame/internal/worldmap"
+)
+
+func main() {
+	worldPath := flag.String("world", "", "world directory or save file (saved modifications are included)")
+	seed := flag.Int64("seed", 0, "generate terrain from this seed instead of loading a world")
+	preset := flag.String("preset", terrain.PresetDefault, "terrain preset used with -seed ("+strings.Join(terrain.Presets, ", ")+")")
+	layers := flag.String("layers", terrain.DefaultFlatLayers, "superflat layers from the bottom up, used with -preset superflat")
+	center := flag.String("center", "0,0", "center chunk as cx,cz")
+	radius := flag.Int("radius", 8, "chunk radius around -center")
+	out := flag.String("out", "map.png", "output PNG")
+	scale := flag.Int("scale", 1, "pixels per block")
+	biomes := flag.Bool("biomes", false, "also write a biome overlay map next to -out")
+	markers := flag.Bool("markers", true, "mark dungeons, waterfalls, villages and ruins")
+	shading := flag.Bool("shading", true, "shade by height and slope")
+	pregen := flag.Bool("pregen", false, "store the generated chunks in the world directory for faster loading")
+	skip := flag.String("skip", "", "comma-separated generation passes to leave out ("+strings.Join(terrain.NewGenerator(0).Pipeline.Passes(), ", ")+")")
+	set := flag.String("set", "", "comma-separated generation pass settings as pass.key=value, e.g. caves.frequency=2")
+	timings := flag.Bool("timings", false, "print the time spent in each generation pass")
+	flag.Parse()
+
+	if err := run(*worldPath, *seed, *preset, *layers, *center, *radius, *out, *scale, *biomes, *markers, *shading, *pregen, *skip, *set, *timings); err != nil {
+		fmt.Fprintf(os.Stderr, "voxelmap: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(worldPath string, seed int64, preset, layers, center string, radius int, out string, scale int, biomes, markers, shading, pregen bool, skip, set string, timings bool) error {
+	if radius < 0 {
+		return fmt.Errorf("radius must not be negative")
+	}
+	var cx, cz int
+	if _, err := fmt.Sscanf(center, "%d,%d", &cx, &cz); err != nil {
+		return fmt.Errorf("invalid chunk coordinate %q, expected cx,cz", center)
+	}
+
+	var w *headless.World
+	if worldPath != "" {
+		data, err := headless.LoadSave(worldPath)
+		if err != nil {
+			return fmt.Errorf("failed to load world: %w", err)
+		}
+		w = headless.FromSave(data)
+	} else {
+		if pregen {
+			return fmt.Errorf("-pregen needs a -world directory to store chunks in")
+		}
+		config := terrain.DefaultConfig()
+		config.FlatLayers = layers
+		w = headless.NewWorld(seed, preset, config)
+	}
+
+	if skip != "" {
+		if pregen {
+			return fmt.Errorf("-skip cannot be used with -pregen, the stored chunks would be incomplete")
+		}
+		for _, name := range strings.Split(skip, ",") {
+			if err := w.Generator.Pipeline.SetEnabled(strings.TrimSpace(name), false); err != nil {
+				return err
+			}
+		}
+	}
+
+	if set != "" {
+		if pregen {
+			return fmt.Errorf("-set cannot be used with -pregen, the stored chunks wouldn't match the world")
+		}
+		if err := configurePasses(w.Generator.Pipeline, set); err != nil {
+			return err
+		}
+	}
+
+	if pregen {
+		store, err := openPregenStore(worldPath, w)
+		if err != nil {
+			return err
+		}
+		// Store generated terrain before the manager applies saved modifications
+		w.Chunks.SetGenerator(&recordingGenerator{store: store, generator: w.ChunkGenerator})
+	}
+
+	minCX, minCZ := cx-radius, cz-radius
+	maxCX, maxCZ := cx+radius, cz+radius
+	fmt.Printf("[Map] Generating chunks %d,%d to %d,%d (seed %d)\n", minCX, minCZ, maxCX, maxCZ, w.Seed)
+
+	m := worldmap.Build(w, minCX, minCZ, maxCX, maxCZ, func(done, total int) {
+		if done%64 == 0 || done == total {
+			fmt.Printf("[Map] %d/%d chunks\n", done, total)
+		}
+	})
+
+	if timings {
+		for _, t := range w.Generator.Pipeline.Timings() {
+			if !t.Enabled {
+				fmt.Printf("  %-12s skipped\n", t.Name)
+				continue
+			}
+			fmt.Printf("  %-12s %8s total %8s per chunk\n", t.Name, t.Total.Round(time.Millisecond), t.Average().Round(time.Microsecond))
+		}
+	}
+
+	opts := worldmap.DefaultOptions()
+	opts.Scale = scale
+	opts.Markers = markers
+	opts.Shading = shading
+	if err := writePNG(out, m.Render(opts)); err != nil {
+		return err
+	}
+	fmt.Printf("[Map] Wrote %s (%dx%d blocks)\n", out, m.Width, m.Depth)
+
+	if biomes {
+		opts.Biomes = true
+		biomeOut := strings.TrimSuffix(out, filepath.Ext(out)) + "_biomes.png"
+		if err := writePNG(biomeOut, m.Render(opts)); err != nil {
+			return err
+		}
+		fmt.Printf("[Map] Wrote %s\n", biomeOut)
+		for i, name := range m.BiomeNames {
+			c := m.BiomeColors[i]
+			fmt.Printf("  %-12s #%02x%02x%02x\n", name, c.R, c.G, c.B)
+		}
+	}
+
+	for _, kind := range worldmap.MarkerKinds {
+		c := worldmap.MarkerColor(kind)
+		fmt.Printf("  %d %ss (#%02x%02x%02x)\n", m.CountMarkers(kind), kind, c.R, c.G, c.B)
+	}
+	return nil
+}
+
+// openPregenStore prepares the world's chunk directory for pre-generation
+func openPregenStore(worldPath string, w *headless.World) (*chunk.Store, error) {
+	info, err := os.Stat(worldPath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("-pregen needs a world directory, not a save file")
+	}
+
+	dir := filepath.Join(worldPath, save.ChunkDirName)
+	manifest := headless.PregenManifest{Seed: w.Seed, Generator: w.GeneratorSave()}
+
+	// Chunks generated with other settings are useless, start over
+	if existing, err := headless.ReadPregenManifest(dir); err == nil && *existing != manifest {
+		fmt.Printf("[Map] Discarding chunks generated with different settings in %s\n", dir)
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, err
+		}
+	}
+	if err := headless.WritePregenManifest(dir, manifest); err != nil {
+		return nil, fmt.Errorf("failed to write chunk manifest: %w", err)
+	}
+	return chunk.NewStore(dir), nil
+}
+
+// recordingGenerator generates chunks and stores each one as it is generated
+type recordingGenerator struct {
+	store     *chunk.Store
+	generator chunk.ChunkGenerator
+}
+
+// GenerateChunk generates a chunk and writes it to the store
+func (g *recordingGenerator) GenerateChunk(c *chunk.Chunk) {
+	g.generator.GenerateChunk(c)
+	if err := g.store.Save(c); err != nil {
+		fmt.Printf("[Map] Failed to store chunk %s: %v\n", c.ID(), err)
+	}
+}
+
+// writePNG encodes an image to a file
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// configurePasses applies comma-separated pass.key=value settings
+func configurePasses(p *terrain.Pipeline, settings string) error {
+	for _, setting := range strings.Split(settings, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(setting), "=")
+		pass, key, hasKey := strings.Cut(name, ".")
+		if !ok || !hasKey {
+			return fmt.Errorf("invalid pass setting %q, expected pass.key=value", setting)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value in pass setting %q", setting)
+		}
+		// Catch typos: the built-in passes declare every key they read
+		if _, known := p.Config(pass)[key]; !known && p.Config(pass) != nil {
+			return fmt.Errorf("pass %q has no setting %q", pass, key)
+		}
+		if err := p.Configure(pass, key, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
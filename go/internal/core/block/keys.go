@@ -0,0 +1,97 @@
+// Package block provides stable text keys for block types
+package block
+
+import "fmt"
+
+// keys are the identifiers used for block types in data files. Unlike the
+// display names in the Registry they are English, lowercase and never change.
+var keys = map[Type]string{
+	Air:             "air",
+	Grass:           "grass",
+	Dirt:            "dirt",
+	Stone:           "stone",
+	Wood:            "wood",
+	Leaves:          "leaves",
+	Sand:            "sand",
+	Water:           "water",
+	Snow:            "snow",
+	Ice:             "ice",
+	Clay:            "clay",
+	Gravel:          "gravel",
+	Cobblestone:     "cobblestone",
+	Bedrock:         "bedrock",
+	CoalOre:         "coal_ore",
+	IronOre:         "iron_ore",
+	GoldOre:         "gold_ore",
+	DiamondOre:      "diamond_ore",
+	Cactus:          "cactus",
+	DeadBush:        "dead_bush",
+	FlowerRed:       "flower_red",
+	FlowerYellow:    "flower_yellow",
+	MushroomRed:     "mushroom_red",
+	MushroomBrown:   "mushroom_brown",
+	TallGrass:       "tall_grass",
+	OakLog:          "oak_log",
+	BirchLog:        "birch_log",
+	SpruceLog:       "spruce_log",
+	OakLeaves:       "oak_leaves",
+	BirchLeaves:     "birch_leaves",
+	SpruceLeaves:    "spruce_leaves",
+	Glass:           "glass",
+	Brick:           "brick",
+	Pickaxe:         "pickaxe",
+	Axe:             "axe",
+	Sword:           "sword",
+	Shovel:          "shovel",
+	Lava:            "lava",
+	Campfire:        "campfire",
+	StoneBrick:      "stone_brick",
+	MossyStoneBrick: "mossy_stone_brick",
+	Chest:           "chest",
+	Spawner:         "spawner",
+	JungleLog:       "jungle_log",
+	JungleLeaves:    "jungle_leaves",
+}
+
+// byKey is the reverse of keys
+var byKey = func() map[string]Type {
+	m := make(map[string]Type, len(keys))
+	for t, k := range keys {
+		m[k] = t
+	}
+	return m
+}()
+
+// Key returns the data file identifier of the block type
+func (t Type) Key() string {
+	if k, ok := keys[t]; ok {
+		return k
+	}
+	return fmt.Sprintf("unknown_%d", uint8(t))
+}
+
+// ParseKey returns the block type with the given data file identifier
+func ParseKey(key string) (Type, error) {
+	if t, ok := byKey[key]; ok {
+		return t, nil
+	}
+	return Air, fmt.Errorf("unknown block %q", key)
+}
+
+// MarshalText encodes the block type as its key
+func (t Type) MarshalText() ([]byte, error) {
+	if _, ok := keys[t]; !ok {
+		return nil, fmt.Errorf("block type %d has no key", uint8(t))
+	}
+	return []byte(t.Key()), nil
+}
+
+// UnmarshalText decodes a block type from its key
+func (t *Type) UnmarshalText(text []byte) error {
+	parsed, err := ParseKey(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
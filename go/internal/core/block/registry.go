@@ -430,6 +430,47 @@ var Registry = map[Type]Definition{
 		TextureBottom: 14,
 		Material:      MaterialStone,
 	},
+	Chest: {
+		Name:          "Baú",
+		Solid:         true,
+		Transparent:   false,
+		Collidable:    true,
+		Color:         hexToRGB("#a0672d"),
+		BreakTime:     1.0,
+		TextureTop:    4,
+		TextureSide:   4,
+		TextureBottom: 4,
+	},
+	Spawner: {
+		Name:          "Gerador de Criaturas",
+		Solid:         true,
+		Transparent:   false,
+		Collidable:    true,
+		Color:         hexToRGB("#3a4650"),
+		BreakTime:     4.0,
+		Emissive:      0.3,
+		TextureTop:    13,
+		TextureSide:   13,
+		TextureBottom: 13,
+		Material:      MaterialStone,
+	},
+	JungleLog: {
+		Name:        "Tronco de Selva",
+		Solid:       true,
+		Transparent: false,
+		Collidable:  true,
+		Color:       hexToRGB("#5a4a2a"),
+		BreakTime:   1.5,
+	},
+	JungleLeaves: {
+		Name:        "Folhas de Selva",
+		Solid:       true,
+		Transparent: true,
+		Collidable:  true,
+		Color:       hexToRGB("#2e8b1e"),
+		BreakTime:   0.2,
+		Material:    MaterialFoliage,
+	},
 }
 
 // GetDefinition returns the definition for a block type
@@ -445,9 +486,11 @@ func GetAllPlaceableBlocks() []Type {
 	placeable := make([]Type, 0, BlockTypeCount)
 	for t := Type(1); t < BlockTypeCount; t++ { // Skip Air
 		def := Registry[t]
-		// Include solid blocks, water, and tools
+		// Include solid blocks, water, and tools, but not the generated
+		// dungeon blocks
 		isTool := t == Pickaxe || t == Axe || t == Sword || t == Shovel
-		if def.Solid || t == Water || isTool {
+		isDungeon := t == Chest || t == Spawner
+		if (def.Solid || t == Water || isTool) && !isDungeon {
 			placeable = append(placeable, t)
 		}
 	}
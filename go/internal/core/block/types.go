@@ -47,6 +47,10 @@ const (
 	Campfire
 	StoneBrick
 	MossyStoneBrick
+	Chest   // Dungeon loot container, yields its loot when broken
+	Spawner // Spawns dungeon creatures near the player
+	JungleLog
+	JungleLeaves
 	BlockTypeCount // Total number of block types
 )
 
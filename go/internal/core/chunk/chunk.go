@@ -115,6 +115,22 @@ func (c *Chunk) GetHeight(lx, lz int) int {
 	return int(c.HeightMap[lx+lz*Size])
 }
 
+// UpdateHeight recomputes the height map of a column. SetBlock only raises
+// heights, so call it after removing blocks from the top of a column.
+func (c *Chunk) UpdateHeight(lx, lz int) {
+	if lx < 0 || lx >= Size || lz < 0 || lz >= Size {
+		return
+	}
+	height := 0
+	for y := Height - 1; y > 0; y-- {
+		if c.GetBlock(lx, y, lz) != block.Air {
+			height = y
+			break
+		}
+	}
+	c.HeightMap[lx+lz*Size] = uint8(height)
+}
+
 // ForEachSolidBlock iterates over all non-air blocks
 func (c *Chunk) ForEachSolidBlock(fn func(lx, ly, lz int, t block.Type)) {
 	for z := 0; z < Size; z++ {
@@ -194,6 +210,9 @@ func Deserialize(s SerializedChunk) *Chunk {
 
 	for i, b := range s.Data {
 		c.Data[i] = block.Type(b)
+		if c.Data[i] != block.Air {
+			c.SolidBlockCount++
+		}
 	}
 	copy(c.HeightMap, s.HeightMap)
 
@@ -84,6 +84,12 @@ func NewManager(config ManagerConfig, generator ChunkGenerator) *Manager {
 	}
 }
 
+// SetGenerator replaces the generator used for chunks that aren't loaded yet.
+// Call it before chunks start loading.
+func (m *Manager) SetGenerator(generator ChunkGenerator) {
+	m.generator = generator
+}
+
 // GetChunk returns a chunk if it's loaded, nil otherwise
 func (m *Manager) GetChunk(cx, cz int) *Chunk {
 	id := m.chunkID(cx, cz)
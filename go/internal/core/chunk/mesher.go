@@ -58,8 +58,23 @@ type MeshData struct {
 	IndexCount  int
 }
 
+// SurfaceLook changes how the surface is drawn without changing its
+// blocks, for seasons. It is cosmetic only: frozen water is still water to
+// physics and saves, so the player swims through lake ice.
+type SurfaceLook struct {
+	// Frozen reports whether a column is covered in snow, with ice on its
+	// water. Nil freezes nothing.
+	Frozen func(wx, wz int) bool
+
+	// Foliage tints leaves and grass, zero for no tint
+	Foliage [3]float32
+}
+
 // Mesher generates optimized meshes for chunks
 type Mesher struct {
+	// Surface is applied to every mesh generated
+	Surface SurfaceLook
+
 	// Buffers for building mesh
 	vertices []float32
 	indices  []uint32
@@ -82,6 +97,7 @@ func (m *Mesher) GenerateMesh(c *Chunk, getBlock BlockGetter) *MeshData {
 
 	worldOffsetX := int(c.CX) * Size
 	worldOffsetZ := int(c.CZ) * Size
+	snowY := m.snowLevels(c)
 
 	// Iterate over all blocks
 	for z := 0; z < Size; z++ {
@@ -93,20 +109,34 @@ func (m *Mesher) GenerateMesh(c *Chunk, getBlock BlockGetter) *MeshData {
 					continue
 				}
 
-				blockDef := block.GetDefinition(blockType)
 				worldX := worldOffsetX + x
 				worldZ := worldOffsetZ + z
 
+				// Snow covers the top solid block and water there freezes.
+				// Frozen water keeps the faces of water, drawn as ice.
+				blockDef := m.tint(block.GetDefinition(blockType))
+				topDef := blockDef
+				snowy := y == snowY[x+z*Size]
+				if snowy && blockType == block.Water {
+					blockDef = block.GetDefinition(block.Ice)
+					topDef = blockDef
+					snowy = false
+				} else if snowy {
+					topDef = block.GetDefinition(block.Snow)
+				} else if blockType == block.Grass {
+					topDef.Color = m.foliage(topDef.Color)
+				}
+
 				// Check each face
 				m.addVisibleFaces(
 					x, y, z,
 					worldX, y, worldZ,
-					blockType, blockDef,
+					blockType, blockDef, topDef,
 					c, getBlock,
 				)
 
 				// Add custom details (foliage, grass blades)
-				if blockDef.HasCustomMesh || blockType == block.Grass {
+				if blockDef.HasCustomMesh || (blockType == block.Grass && !snowy) {
 					m.addDetailedGeometry(
 						x, y, z,
 						worldX, y, worldZ,
@@ -130,6 +160,52 @@ func (m *Mesher) GenerateMesh(c *Chunk, getBlock BlockGetter) *MeshData {
 	}
 }
 
+// snowLevels returns the height of the snow-covered block in each column,
+// above the top of the chunk where the surface isn't frozen. Snow settles
+// on the highest solid block, or on water that freezes.
+func (m *Mesher) snowLevels(c *Chunk) [Size * Size]int {
+	var levels [Size * Size]int
+	for i := range levels {
+		levels[i] = Height
+	}
+	if m.Surface.Frozen == nil {
+		return levels
+	}
+
+	for z := 0; z < Size; z++ {
+		for x := 0; x < Size; x++ {
+			if !m.Surface.Frozen(int(c.CX)*Size+x, int(c.CZ)*Size+z) {
+				continue
+			}
+			for y := c.GetHeight(x, z); y >= 0; y-- {
+				t := c.GetBlock(x, y, z)
+				if t == block.Water || block.GetDefinition(t).Solid {
+					levels[x+z*Size] = y
+					break
+				}
+			}
+		}
+	}
+	return levels
+}
+
+// tint applies the foliage tint to foliage blocks
+func (m *Mesher) tint(def block.Definition) block.Definition {
+	if def.Material == block.MaterialFoliage {
+		def.Color = m.foliage(def.Color)
+	}
+	return def
+}
+
+// foliage applies the foliage tint to a color
+func (m *Mesher) foliage(color [3]float32) [3]float32 {
+	tint := m.Surface.Foliage
+	if tint == [3]float32{} {
+		return color
+	}
+	return [3]float32{color[0] * tint[0], color[1] * tint[1], color[2] * tint[2]}
+}
+
 // addDetailedGeometry adds custom geometry like cross-meshes or grass blades
 func (m *Mesher) addDetailedGeometry(
 	lx, ly, lz int,
@@ -191,7 +267,7 @@ func (m *Mesher) addGrassBlades(x, y, z float32, blockDef block.Definition) {
 	seed := int(x*31 + y*17 + z*23)
 	count := 5 + (seed % 3)
 
-	color := blockDef.Color
+	color := m.foliage(blockDef.Color)
 	matID := float32(block.MaterialFoliage) // Force foliage material for sway
 
 	for i := 0; i < count; i++ {
@@ -260,7 +336,7 @@ func (m *Mesher) addVisibleFaces(
 	lx, ly, lz int,
 	wx, wy, wz int,
 	blockType block.Type,
-	blockDef block.Definition,
+	blockDef, topDef block.Definition,
 	c *Chunk,
 	getBlock BlockGetter,
 ) {
@@ -294,7 +370,11 @@ func (m *Mesher) addVisibleFaces(
 		}
 
 		if shouldRender {
-			m.addFace(face, float32(wx), float32(wy), float32(wz), blockDef, c, lx, ly, lz, getBlock)
+			def := blockDef
+			if face == "top" {
+				def = topDef
+			}
+			m.addFace(face, float32(wx), float32(wy), float32(wz), def, c, lx, ly, lz, getBlock)
 		}
 	}
 }
@@ -0,0 +1,128 @@
+// Package chunk provides on-disk storage of generated chunks
+package chunk
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Store reads and writes generated chunks in a directory, one gzipped file
+// per chunk. It holds generated terrain only; player modifications are
+// kept in the save and applied by the Manager on load.
+type Store struct {
+	dir string
+}
+
+// NewStore creates a chunk store in the given directory
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir returns the store's directory
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Has reports whether a chunk is stored
+func (s *Store) Has(cx, cz int) bool {
+	_, err := os.Stat(s.path(cx, cz))
+	return err == nil
+}
+
+// Count returns the number of stored chunks
+func (s *Store) Count() int {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0
+	}
+	n := 0
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".chunk.gz") {
+			n++
+		}
+	}
+	return n
+}
+
+// Load reads a stored chunk
+func (s *Store) Load(cx, cz int) (*Chunk, error) {
+	f, err := os.Open(s.path(cx, cz))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var sc SerializedChunk
+	if err := json.NewDecoder(zr).Decode(&sc); err != nil {
+		return nil, err
+	}
+	if int(sc.CX) != cx || int(sc.CZ) != cz || len(sc.Data) != Size*Height*Size {
+		return nil, fmt.Errorf("stored chunk %s is invalid", ChunkID(cx, cz))
+	}
+	return Deserialize(sc), nil
+}
+
+// Save writes a chunk, replacing any stored copy
+func (s *Store) Save(c *Chunk) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+
+	path := s.path(int(c.CX), int(c.CZ))
+	tmp, err := os.CreateTemp(s.dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(tmp)
+	err = json.NewEncoder(zw).Encode(c.Serialize())
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// path returns the file of a chunk
+func (s *Store) path(cx, cz int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%d_%d.chunk.gz", cx, cz))
+}
+
+// StoredGenerator serves chunks from a Store and falls back to another
+// generator for chunks that aren't stored
+type StoredGenerator struct {
+	Store     *Store
+	Generator ChunkGenerator
+}
+
+// GenerateChunk fills the chunk from the store if possible
+func (g *StoredGenerator) GenerateChunk(c *Chunk) {
+	stored, err := g.Store.Load(int(c.CX), int(c.CZ))
+	if err == nil {
+		copy(c.Data, stored.Data)
+		copy(c.HeightMap, stored.HeightMap)
+		c.SolidBlockCount = stored.SolidBlockCount
+		c.IsGenerated = true
+		return
+	}
+	if !os.IsNotExist(err) {
+		fmt.Printf("[ChunkStore] Regenerating %s: %v\n", c.ID(), err)
+	}
+	g.Generator.GenerateChunk(c)
+}
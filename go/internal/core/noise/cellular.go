@@ -0,0 +1,91 @@
+// Package noise provides Worley (cellular) noise
+package noise
+
+import (
+	"math"
+)
+
+// CellularNoise implements Worley noise: every lattice cell holds one
+// randomly placed feature point, and samples measure the distance to the
+// nearest ones. It makes cracks, cells and Voronoi regions.
+type CellularNoise struct {
+	seed int64
+
+	// Jitter scatters the feature points within their cells, from 0 (a
+	// regular grid) to 1 (anywhere in the cell)
+	Jitter float64
+}
+
+// CellSample is the result of sampling cellular noise
+type CellSample struct {
+	F1 float64 // Distance to the nearest feature point
+	F2 float64 // Distance to the second nearest feature point
+
+	// Value is a random value in [-1, 1) shared by every position whose
+	// nearest feature point is the same, for Voronoi regions
+	Value float64
+}
+
+// Edge returns F2 - F1: 0 on the borders between cells, growing towards
+// the feature points
+func (s CellSample) Edge() float64 {
+	return s.F2 - s.F1
+}
+
+// NewCellularNoise creates a cellular noise generator with the given seed
+func NewCellularNoise(seed int64) *CellularNoise {
+	return &CellularNoise{seed: seed, Jitter: 1}
+}
+
+// Sample2D samples cellular noise in 2D, one feature point per unit square
+func (c *CellularNoise) Sample2D(x, z float64) CellSample {
+	cx, cz := int64(math.Floor(x)), int64(math.Floor(z))
+	s := CellSample{F1: math.Inf(1), F2: math.Inf(1)}
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dz := int64(-1); dz <= 1; dz++ {
+			px, pz := cx+dx, cz+dz
+			fx := float64(px) + c.offset(px, 0, pz, 0)
+			fz := float64(pz) + c.offset(px, 0, pz, 2)
+			c.add(&s, math.Hypot(fx-x, fz-z), px, 0, pz)
+		}
+	}
+	return s
+}
+
+// Sample3D samples cellular noise in 3D, one feature point per unit cube
+func (c *CellularNoise) Sample3D(x, y, z float64) CellSample {
+	cx, cy, cz := int64(math.Floor(x)), int64(math.Floor(y)), int64(math.Floor(z))
+	s := CellSample{F1: math.Inf(1), F2: math.Inf(1)}
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for dz := int64(-1); dz <= 1; dz++ {
+				px, py, pz := cx+dx, cy+dy, cz+dz
+				fx := float64(px) + c.offset(px, py, pz, 0)
+				fy := float64(py) + c.offset(px, py, pz, 1)
+				fz := float64(pz) + c.offset(px, py, pz, 2)
+				dist := math.Sqrt((fx-x)*(fx-x) + (fy-y)*(fy-y) + (fz-z)*(fz-z))
+				c.add(&s, dist, px, py, pz)
+			}
+		}
+	}
+	return s
+}
+
+// offset returns the position of a cell's feature point along one axis,
+// relative to the cell's corner
+func (c *CellularNoise) offset(x, y, z int64, axis int64) float64 {
+	u := hashUnit(c.seed+axis*0x51ED27, x, y, z)*0.5 + 0.5
+	return 0.5 + (u-0.5)*c.Jitter
+}
+
+// add records the distance to a cell's feature point
+func (c *CellularNoise) add(s *CellSample, dist float64, x, y, z int64) {
+	switch {
+	case dist < s.F1:
+		s.F2 = s.F1
+		s.F1 = dist
+		s.Value = hashUnit(c.seed+0x3C6EF3, x, y, z)
+	case dist < s.F2:
+		s.F2 = dist
+	}
+}
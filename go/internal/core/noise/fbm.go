@@ -132,3 +132,48 @@ func (f *FBM) Warped2D(noise *SimplexNoise, x, z, warpAmount float64) float64 {
 	warpZ := f.Sample2D(noise, x*0.5+100, z*0.5+100) * warpAmount
 	return f.Sample2D(noise, x+warpX, z+warpZ)
 }
+
+// Ridged3D samples ridged FBM noise in 3D
+func (f *FBM) Ridged3D(noise *SimplexNoise, x, y, z float64) float64 {
+	value := 0.0
+	amplitude := 1.0
+	frequency := f.Config.Scale
+	maxValue := 0.0
+
+	for i := 0; i < f.Config.Octaves; i++ {
+		n := noise.Noise3D(
+			(x+f.Config.OffsetX)*frequency,
+			y*frequency,
+			(z+f.Config.OffsetZ)*frequency,
+		)
+		n = 1 - math.Abs(n) // Ridge
+		n = n * n           // Sharpen
+		value += amplitude * n
+		maxValue += amplitude
+		amplitude *= f.Config.Persistence
+		frequency *= f.Config.Lacunarity
+	}
+
+	return value / maxValue
+}
+
+// Turbulence3D samples turbulent FBM noise in 3D
+func (f *FBM) Turbulence3D(noise *SimplexNoise, x, y, z float64) float64 {
+	value := 0.0
+	amplitude := 1.0
+	frequency := f.Config.Scale
+	maxValue := 0.0
+
+	for i := 0; i < f.Config.Octaves; i++ {
+		value += amplitude * math.Abs(noise.Noise3D(
+			(x+f.Config.OffsetX)*frequency,
+			y*frequency,
+			(z+f.Config.OffsetZ)*frequency,
+		))
+		maxValue += amplitude
+		amplitude *= f.Config.Persistence
+		frequency *= f.Config.Lacunarity
+	}
+
+	return value / maxValue
+}
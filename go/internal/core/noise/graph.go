@@ -0,0 +1,664 @@
+// Package noise provides composable noise graphs
+package noise
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Graph combines noise sources and operations into one function, declared
+// in JSON instead of code:
+//
+//	{
+//	  "inputs": ["height"],
+//	  "nodes": {
+//	    "hills": {"type": "fbm", "frequency": 0.005}
+//	  },
+//	  "output": {"type": "add", "inputs": [20, "height", "hills"]}
+//	}
+//
+// Wherever a node takes an input, a number is a constant, a string names a
+// node from "nodes" or a value passed in with each sample (see Inputs), and
+// an object defines a node in place. Node types:
+//
+//	constant   "value"
+//	input      "value" passed at index "input" (prefer naming inputs)
+//	simplex    simplex noise
+//	fbm        fractal noise, "mode" fbm, ridged or turbulence
+//	value      value noise
+//	cellular   cellular noise, "mode" f1, f2, edge or value, with "jitter"
+//	add        sum of "inputs"
+//	multiply   product of "inputs", skipping the rest after a 0
+//	clamp      "input" limited to "min" and "max"
+//	spline     "input" remapped through "points" [[in, out], ...]
+//	cache      "input", evaluated once per column
+//	warp       "input" sampled at coordinates warped by "levels"
+//
+// Noise sources take "seed" (added to the graph's seed), "frequency" and
+// "amplitude". The fbm type also takes "octaves", "lacunarity" and
+// "persistence".
+type Graph struct {
+	root   node
+	inputs []string
+	caches int // Number of cache nodes, each with a slot in every Sampler
+	pool   sync.Pool
+}
+
+// Sampler evaluates a graph. It holds the graph's caches, so a Sampler must
+// not be shared between goroutines; the Graph's own sample methods are safe
+// for concurrent use.
+type Sampler struct {
+	graph  *Graph
+	inputs []float64
+	caches []cacheSlot
+}
+
+// cacheSlot remembers a cache node's value for one column
+type cacheSlot struct {
+	x, z  float64
+	value float64
+	valid bool
+}
+
+// point is the position a graph is evaluated at
+type point struct {
+	x, y, z float64
+	is3D    bool
+}
+
+// node is one operation in a graph
+type node interface {
+	eval(s *Sampler, p point) float64
+}
+
+// Inputs returns the names of the values passed with each sample, in order
+func (g *Graph) Inputs() []string {
+	return g.inputs
+}
+
+// NewSampler creates a sampler for the graph
+func (g *Graph) NewSampler() *Sampler {
+	return &Sampler{
+		graph:  g,
+		inputs: make([]float64, len(g.inputs)),
+		caches: make([]cacheSlot, g.caches),
+	}
+}
+
+// Sample2D evaluates the graph at a 2D position, with inputs in the order
+// of Inputs
+func (g *Graph) Sample2D(x, z float64, inputs ...float64) float64 {
+	s := g.pool.Get().(*Sampler)
+	v := s.Sample2D(x, z, inputs...)
+	g.pool.Put(s)
+	return v
+}
+
+// Sample3D evaluates the graph at a 3D position, with inputs in the order
+// of Inputs
+func (g *Graph) Sample3D(x, y, z float64, inputs ...float64) float64 {
+	s := g.pool.Get().(*Sampler)
+	v := s.Sample3D(x, y, z, inputs...)
+	g.pool.Put(s)
+	return v
+}
+
+// Sample2D evaluates the graph at a 2D position
+func (s *Sampler) Sample2D(x, z float64, inputs ...float64) float64 {
+	s.setInputs(inputs)
+	return s.graph.root.eval(s, point{x: x, z: z})
+}
+
+// Sample3D evaluates the graph at a 3D position
+func (s *Sampler) Sample3D(x, y, z float64, inputs ...float64) float64 {
+	s.setInputs(inputs)
+	return s.graph.root.eval(s, point{x: x, y: y, z: z, is3D: true})
+}
+
+// setInputs stores the inputs of a sample, missing ones are 0. Cached
+// values are dropped when the inputs change, as they may depend on them.
+func (s *Sampler) setInputs(inputs []float64) {
+	changed := false
+	for i := range s.inputs {
+		v := 0.0
+		if i < len(inputs) {
+			v = inputs[i]
+		}
+		if s.inputs[i] != v {
+			s.inputs[i] = v
+			changed = true
+		}
+	}
+	if changed {
+		for i := range s.caches {
+			s.caches[i].valid = false
+		}
+	}
+}
+
+// LoadGraph parses a noise graph from JSON. Noise sources are seeded from
+// seed plus their own seed.
+func LoadGraph(r io.Reader, seed int64) (*Graph, error) {
+	var file graphFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("noise graph: %w", err)
+	}
+	if len(file.Output) == 0 {
+		return nil, fmt.Errorf("noise graph: no output")
+	}
+
+	b := &graphBuilder{
+		seed:     seed,
+		defs:     file.Nodes,
+		inputs:   make(map[string]int),
+		built:    make(map[string]node),
+		building: make(map[string]bool),
+	}
+	for i, name := range file.Inputs {
+		if _, ok := b.inputs[name]; ok {
+			return nil, fmt.Errorf("noise graph: input %q declared twice", name)
+		}
+		if _, ok := file.Nodes[name]; ok {
+			return nil, fmt.Errorf("noise graph: %q is both an input and a node", name)
+		}
+		b.inputs[name] = i
+	}
+
+	root, err := b.ref(file.Output, "output")
+	if err != nil {
+		return nil, fmt.Errorf("noise graph: %w", err)
+	}
+	// Report unused nodes, they are usually typos in a reference
+	var unused []string
+	for name := range file.Nodes {
+		if _, ok := b.built[name]; !ok {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return nil, fmt.Errorf("noise graph: unused nodes %v", unused)
+	}
+
+	g := &Graph{root: root, inputs: file.Inputs, caches: b.caches}
+	g.pool.New = func() any { return g.NewSampler() }
+	return g, nil
+}
+
+// graphFile is the JSON form of a graph
+type graphFile struct {
+	Inputs []string                   `json:"inputs"`
+	Nodes  map[string]json.RawMessage `json:"nodes"`
+	Output json.RawMessage            `json:"output"`
+}
+
+// nodeDef is the JSON form of a node
+type nodeDef struct {
+	Type        string            `json:"type"`
+	Value       float64           `json:"value"`
+	Seed        int64             `json:"seed"`
+	Frequency   *float64          `json:"frequency"`
+	Amplitude   *float64          `json:"amplitude"`
+	Octaves     int               `json:"octaves"`
+	Lacunarity  float64           `json:"lacunarity"`
+	Persistence float64           `json:"persistence"`
+	Mode        string            `json:"mode"`
+	Jitter      *float64          `json:"jitter"`
+	Input       json.RawMessage   `json:"input"`
+	Inputs      []json.RawMessage `json:"inputs"`
+	Min         *float64          `json:"min"`
+	Max         *float64          `json:"max"`
+	Points      [][2]float64      `json:"points"`
+	Levels      []WarpLevel       `json:"levels"`
+}
+
+// graphBuilder turns definitions into nodes
+type graphBuilder struct {
+	seed     int64
+	defs     map[string]json.RawMessage
+	inputs   map[string]int
+	built    map[string]node
+	building map[string]bool // Named nodes being built, to catch cycles
+	caches   int
+}
+
+// ref builds the node an input refers to
+func (b *graphBuilder) ref(raw json.RawMessage, where string) (node, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%s: missing input", where)
+	}
+
+	switch raw[0] {
+	case '"':
+		var name string
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return nil, fmt.Errorf("%s: %w", where, err)
+		}
+		return b.named(name, where)
+	case '{':
+		return b.def(raw, where)
+	default:
+		var v float64
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("%s: input must be a number, name or node", where)
+		}
+		return constNode(v), nil
+	}
+}
+
+// named builds a named node or input
+func (b *graphBuilder) named(name, where string) (node, error) {
+	if i, ok := b.inputs[name]; ok {
+		return inputNode(i), nil
+	}
+	if n, ok := b.built[name]; ok {
+		return n, nil
+	}
+	raw, ok := b.defs[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown node %q", where, name)
+	}
+	if b.building[name] {
+		return nil, fmt.Errorf("%s: node %q depends on itself", where, name)
+	}
+
+	b.building[name] = true
+	n, err := b.ref(raw, name)
+	delete(b.building, name)
+	if err != nil {
+		return nil, err
+	}
+	b.built[name] = n
+	return n, nil
+}
+
+// def builds a node from its definition
+func (b *graphBuilder) def(raw json.RawMessage, where string) (node, error) {
+	var d nodeDef
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&d); err != nil {
+		return nil, fmt.Errorf("%s: %w", where, err)
+	}
+
+	seed := b.seed + d.Seed
+	src := source{frequency: 1, amplitude: 1}
+	if d.Frequency != nil {
+		src.frequency = *d.Frequency
+	}
+	if d.Amplitude != nil {
+		src.amplitude = *d.Amplitude
+	}
+
+	switch d.Type {
+	case "constant":
+		return constNode(d.Value), nil
+
+	case "input":
+		if len(d.Input) == 0 {
+			return nil, fmt.Errorf("%s: input node needs an input index", where)
+		}
+		var i int
+		if err := json.Unmarshal(d.Input, &i); err != nil || i < 0 || i >= len(b.inputs) {
+			return nil, fmt.Errorf("%s: input index out of range", where)
+		}
+		return inputNode(i), nil
+
+	case "simplex":
+		src.noise = NewSimplexNoise(seed)
+		return &simplexNode{src}, nil
+
+	case "fbm":
+		cfg := DefaultFBMConfig()
+		cfg.Scale = src.frequency
+		if d.Octaves > 0 {
+			cfg.Octaves = d.Octaves
+		}
+		if d.Lacunarity != 0 {
+			cfg.Lacunarity = d.Lacunarity
+		}
+		if d.Persistence != 0 {
+			cfg.Persistence = d.Persistence
+		}
+		n := &fbmNode{noise: NewSimplexNoise(seed), fbm: NewFBM(cfg), amplitude: src.amplitude}
+		switch d.Mode {
+		case "", "fbm":
+			n.mode = fbmPlain
+		case "ridged":
+			n.mode = fbmRidged
+		case "turbulence":
+			n.mode = fbmTurbulence
+		default:
+			return nil, fmt.Errorf("%s: unknown fbm mode %q", where, d.Mode)
+		}
+		return n, nil
+
+	case "value":
+		return &valueNode{source: src, noise: NewValueNoise(seed)}, nil
+
+	case "cellular":
+		n := &cellularNode{source: src, noise: NewCellularNoise(seed)}
+		if d.Jitter != nil {
+			n.noise.Jitter = *d.Jitter
+		}
+		switch d.Mode {
+		case "", "f1":
+			n.mode = cellF1
+		case "f2":
+			n.mode = cellF2
+		case "edge":
+			n.mode = cellEdge
+		case "value":
+			n.mode = cellValue
+		default:
+			return nil, fmt.Errorf("%s: unknown cellular mode %q", where, d.Mode)
+		}
+		return n, nil
+
+	case "add", "multiply":
+		if len(d.Inputs) == 0 {
+			return nil, fmt.Errorf("%s: %s node needs inputs", where, d.Type)
+		}
+		inputs := make([]node, len(d.Inputs))
+		for i, raw := range d.Inputs {
+			n, err := b.ref(raw, where)
+			if err != nil {
+				return nil, err
+			}
+			inputs[i] = n
+		}
+		if d.Type == "add" {
+			return addNode(inputs), nil
+		}
+		return multiplyNode(inputs), nil
+
+	case "clamp":
+		input, err := b.ref(d.Input, where)
+		if err != nil {
+			return nil, err
+		}
+		n := &clampNode{input: input, min: math.Inf(-1), max: math.Inf(1)}
+		if d.Min != nil {
+			n.min = *d.Min
+		}
+		if d.Max != nil {
+			n.max = *d.Max
+		}
+		if n.min > n.max {
+			return nil, fmt.Errorf("%s: clamp min is above max", where)
+		}
+		return n, nil
+
+	case "spline":
+		input, err := b.ref(d.Input, where)
+		if err != nil {
+			return nil, err
+		}
+		if len(d.Points) < 2 {
+			return nil, fmt.Errorf("%s: spline needs at least 2 points", where)
+		}
+		for i := 1; i < len(d.Points); i++ {
+			if d.Points[i][0] <= d.Points[i-1][0] {
+				return nil, fmt.Errorf("%s: spline points must be in increasing order", where)
+			}
+		}
+		return &splineNode{input: input, points: d.Points}, nil
+
+	case "cache":
+		input, err := b.ref(d.Input, where)
+		if err != nil {
+			return nil, err
+		}
+		n := &cacheNode{input: input, slot: b.caches}
+		b.caches++
+		return n, nil
+
+	case "warp":
+		input, err := b.ref(d.Input, where)
+		if err != nil {
+			return nil, err
+		}
+		if len(d.Levels) == 0 {
+			return nil, fmt.Errorf("%s: warp needs levels", where)
+		}
+		return &warpNode{input: input, warp: NewDomainWarp(seed, d.Levels)}, nil
+
+	case "":
+		return nil, fmt.Errorf("%s: node has no type", where)
+	default:
+		return nil, fmt.Errorf("%s: unknown node type %q", where, d.Type)
+	}
+}
+
+// constNode is a constant value
+type constNode float64
+
+func (n constNode) eval(s *Sampler, p point) float64 {
+	return float64(n)
+}
+
+// inputNode is a value passed with each sample
+type inputNode int
+
+func (n inputNode) eval(s *Sampler, p point) float64 {
+	return s.inputs[n]
+}
+
+// source holds the settings shared by noise nodes
+type source struct {
+	noise     *SimplexNoise
+	frequency float64
+	amplitude float64
+}
+
+// simplexNode samples simplex noise
+type simplexNode struct {
+	source
+}
+
+func (n *simplexNode) eval(s *Sampler, p point) float64 {
+	f := n.frequency
+	if p.is3D {
+		return n.noise.Noise3D(p.x*f, p.y*f, p.z*f) * n.amplitude
+	}
+	return n.noise.Noise2D(p.x*f, p.z*f) * n.amplitude
+}
+
+// FBM modes
+const (
+	fbmPlain = iota
+	fbmRidged
+	fbmTurbulence
+)
+
+// fbmNode samples fractal noise. Its frequency is the FBM's base scale.
+type fbmNode struct {
+	noise     *SimplexNoise
+	fbm       *FBM
+	mode      int
+	amplitude float64
+}
+
+func (n *fbmNode) eval(s *Sampler, p point) float64 {
+	var v float64
+	switch {
+	case n.mode == fbmRidged && p.is3D:
+		v = n.fbm.Ridged3D(n.noise, p.x, p.y, p.z)
+	case n.mode == fbmRidged:
+		v = n.fbm.Ridged2D(n.noise, p.x, p.z)
+	case n.mode == fbmTurbulence && p.is3D:
+		v = n.fbm.Turbulence3D(n.noise, p.x, p.y, p.z)
+	case n.mode == fbmTurbulence:
+		v = n.fbm.Turbulence2D(n.noise, p.x, p.z)
+	case p.is3D:
+		v = n.fbm.Sample3D(n.noise, p.x, p.y, p.z)
+	default:
+		v = n.fbm.Sample2D(n.noise, p.x, p.z)
+	}
+	return v * n.amplitude
+}
+
+// valueNode samples value noise
+type valueNode struct {
+	source
+	noise *ValueNoise
+}
+
+func (n *valueNode) eval(s *Sampler, p point) float64 {
+	f := n.frequency
+	if p.is3D {
+		return n.noise.Noise3D(p.x*f, p.y*f, p.z*f) * n.amplitude
+	}
+	return n.noise.Noise2D(p.x*f, p.z*f) * n.amplitude
+}
+
+// Cellular modes
+const (
+	cellF1 = iota
+	cellF2
+	cellEdge
+	cellValue
+)
+
+// cellularNode samples cellular noise
+type cellularNode struct {
+	source
+	noise *CellularNoise
+	mode  int
+}
+
+func (n *cellularNode) eval(s *Sampler, p point) float64 {
+	f := n.frequency
+	var c CellSample
+	if p.is3D {
+		c = n.noise.Sample3D(p.x*f, p.y*f, p.z*f)
+	} else {
+		c = n.noise.Sample2D(p.x*f, p.z*f)
+	}
+
+	var v float64
+	switch n.mode {
+	case cellF1:
+		v = c.F1
+	case cellF2:
+		v = c.F2
+	case cellEdge:
+		v = c.Edge()
+	case cellValue:
+		v = c.Value
+	}
+	return v * n.amplitude
+}
+
+// addNode sums its inputs
+type addNode []node
+
+func (n addNode) eval(s *Sampler, p point) float64 {
+	v := 0.0
+	for _, in := range n {
+		v += in.eval(s, p)
+	}
+	return v
+}
+
+// multiplyNode multiplies its inputs. Once a factor is 0 the rest are not
+// evaluated, so masks placed first skip expensive noise.
+type multiplyNode []node
+
+func (n multiplyNode) eval(s *Sampler, p point) float64 {
+	v := 1.0
+	for _, in := range n {
+		v *= in.eval(s, p)
+		if v == 0 {
+			return 0
+		}
+	}
+	return v
+}
+
+// clampNode limits its input to a range
+type clampNode struct {
+	input    node
+	min, max float64
+}
+
+func (n *clampNode) eval(s *Sampler, p point) float64 {
+	return math.Max(n.min, math.Min(n.max, n.input.eval(s, p)))
+}
+
+// splineNode remaps its input through a smooth curve passing through its
+// points, flat beyond the first and last
+type splineNode struct {
+	input  node
+	points [][2]float64
+}
+
+func (n *splineNode) eval(s *Sampler, p point) float64 {
+	v := n.input.eval(s, p)
+	pts := n.points
+	last := len(pts) - 1
+	if v <= pts[0][0] {
+		return pts[0][1]
+	}
+	if v >= pts[last][0] {
+		return pts[last][1]
+	}
+
+	i := sort.Search(last, func(i int) bool { return pts[i+1][0] > v })
+	x0, y0 := pts[i][0], pts[i][1]
+	x1, y1 := pts[i+1][0], pts[i+1][1]
+	w := x1 - x0
+	t := (v - x0) / w
+
+	// Cubic Hermite with Catmull-Rom slopes, flat at the ends
+	slope := func(j int) float64 {
+		if j == 0 || j == last {
+			return 0
+		}
+		return (pts[j+1][1] - pts[j-1][1]) / (pts[j+1][0] - pts[j-1][0])
+	}
+	m0, m1 := slope(i)*w, slope(i+1)*w
+	t2, t3 := t*t, t*t*t
+	return (2*t3-3*t2+1)*y0 + (t3-2*t2+t)*m0 + (-2*t3+3*t2)*y1 + (t3-t2)*m1
+}
+
+// cacheNode evaluates its input once per column and reuses the value while
+// the sampler stays on that column. It ignores y, so in 3D graphs it only
+// belongs above parts that do not depend on height.
+type cacheNode struct {
+	input node
+	slot  int
+}
+
+func (n *cacheNode) eval(s *Sampler, p point) float64 {
+	c := &s.caches[n.slot]
+	if c.valid && c.x == p.x && c.z == p.z {
+		return c.value
+	}
+	v := n.input.eval(s, p)
+	*c = cacheSlot{x: p.x, z: p.z, value: v, valid: true}
+	return v
+}
+
+// warpNode evaluates its input at warped coordinates
+type warpNode struct {
+	input node
+	warp  *DomainWarp
+}
+
+func (n *warpNode) eval(s *Sampler, p point) float64 {
+	if p.is3D {
+		p.x, p.y, p.z = n.warp.Warp3D(p.x, p.y, p.z)
+	} else {
+		p.x, p.z = n.warp.Warp2D(p.x, p.z)
+	}
+	return n.input.eval(s, p)
+}
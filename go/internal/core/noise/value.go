@@ -0,0 +1,77 @@
+// Package noise provides seeded value noise
+package noise
+
+import (
+	"math"
+)
+
+// ValueNoise interpolates random values at integer lattice points. It is
+// blockier than simplex noise and much cheaper.
+type ValueNoise struct {
+	seed int64
+}
+
+// NewValueNoise creates a value noise generator with the given seed
+func NewValueNoise(seed int64) *ValueNoise {
+	return &ValueNoise{seed: seed}
+}
+
+// Noise2D generates 2D value noise at the given coordinates
+// Returns a value in the range [-1, 1]
+func (v *ValueNoise) Noise2D(x, z float64) float64 {
+	x0, z0 := math.Floor(x), math.Floor(z)
+	ix, iz := int64(x0), int64(z0)
+	tx, tz := smootherstep(x-x0), smootherstep(z-z0)
+
+	c00 := hashUnit(v.seed, ix, 0, iz)
+	c10 := hashUnit(v.seed, ix+1, 0, iz)
+	c01 := hashUnit(v.seed, ix, 0, iz+1)
+	c11 := hashUnit(v.seed, ix+1, 0, iz+1)
+	return lerp(lerp(c00, c10, tx), lerp(c01, c11, tx), tz)
+}
+
+// Noise3D generates 3D value noise at the given coordinates
+// Returns a value in the range [-1, 1]
+func (v *ValueNoise) Noise3D(x, y, z float64) float64 {
+	x0, y0, z0 := math.Floor(x), math.Floor(y), math.Floor(z)
+	ix, iy, iz := int64(x0), int64(y0), int64(z0)
+	tx, ty, tz := smootherstep(x-x0), smootherstep(y-y0), smootherstep(z-z0)
+
+	layer := func(iy int64) float64 {
+		c00 := hashUnit(v.seed, ix, iy, iz)
+		c10 := hashUnit(v.seed, ix+1, iy, iz)
+		c01 := hashUnit(v.seed, ix, iy, iz+1)
+		c11 := hashUnit(v.seed, ix+1, iy, iz+1)
+		return lerp(lerp(c00, c10, tx), lerp(c01, c11, tx), tz)
+	}
+	return lerp(layer(iy), layer(iy+1), ty)
+}
+
+// hash mixes a seed and lattice coordinates into 64 random bits
+func hash(seed, x, y, z int64) uint64 {
+	h := uint64(seed)*0x9E3779B97F4A7C15 ^ uint64(x)*0xC2B2AE3D27D4EB4F ^
+		uint64(y)*0x165667B19E3779F9 ^ uint64(z)*0x27D4EB2F165667C5
+	// SplitMix64 finalizer
+	h ^= h >> 30
+	h *= 0xBF58476D1CE4E5B9
+	h ^= h >> 27
+	h *= 0x94D049BB133111EB
+	h ^= h >> 31
+	return h
+}
+
+// hashUnit returns a random value in [-1, 1) for lattice coordinates
+func hashUnit(seed, x, y, z int64) float64 {
+	return float64(hash(seed, x, y, z)>>11)/(1<<52) - 1
+}
+
+// lerp interpolates linearly between a and b
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// smootherstep eases t in [0, 1] with zero first and second derivatives at
+// both ends, hiding the lattice
+func smootherstep(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
@@ -0,0 +1,69 @@
+// Package noise provides multi-level domain warping
+package noise
+
+// WarpLevel configures one level of a domain warp
+type WarpLevel struct {
+	Seed      int64   `json:"seed"`      // Added to the warp's seed
+	Frequency float64 `json:"frequency"` // Frequency of the offset noise
+	Amplitude float64 `json:"amplitude"` // Largest offset, in input units
+	Octaves   int     `json:"octaves"`   // FBM octaves of the offset noise, 1 if unset
+}
+
+// DomainWarp distorts coordinates with noise before they are sampled,
+// bending straight features into swirls. Each level offsets the
+// coordinates warped by the levels before it, so later levels warp the
+// warp.
+type DomainWarp struct {
+	levels []warpLevel
+}
+
+// warpLevel is a WarpLevel with its noise
+type warpLevel struct {
+	noise     [3]*SimplexNoise // One per axis
+	fbm       *FBM
+	amplitude float64
+}
+
+// NewDomainWarp creates a domain warp with the given seed and levels
+func NewDomainWarp(seed int64, levels []WarpLevel) *DomainWarp {
+	w := &DomainWarp{}
+	for _, l := range levels {
+		s := seed + l.Seed
+		octaves := l.Octaves
+		if octaves < 1 {
+			octaves = 1
+		}
+		w.levels = append(w.levels, warpLevel{
+			noise: [3]*SimplexNoise{NewSimplexNoise(s), NewSimplexNoise(s + 1), NewSimplexNoise(s + 2)},
+			fbm: NewFBM(FBMConfig{
+				Octaves:     octaves,
+				Lacunarity:  2.0,
+				Persistence: 0.5,
+				Scale:       l.Frequency,
+			}),
+			amplitude: l.Amplitude,
+		})
+	}
+	return w
+}
+
+// Warp2D returns warped 2D coordinates
+func (w *DomainWarp) Warp2D(x, z float64) (float64, float64) {
+	for _, l := range w.levels {
+		dx := l.fbm.Sample2D(l.noise[0], x, z) * l.amplitude
+		dz := l.fbm.Sample2D(l.noise[2], x, z) * l.amplitude
+		x, z = x+dx, z+dz
+	}
+	return x, z
+}
+
+// Warp3D returns warped 3D coordinates
+func (w *DomainWarp) Warp3D(x, y, z float64) (float64, float64, float64) {
+	for _, l := range w.levels {
+		dx := l.fbm.Sample3D(l.noise[0], x, y, z) * l.amplitude
+		dy := l.fbm.Sample3D(l.noise[1], x, y, z) * l.amplitude
+		dz := l.fbm.Sample3D(l.noise[2], x, y, z) * l.amplitude
+		x, y, z = x+dx, y+dy, z+dz
+	}
+	return x, y, z
+}
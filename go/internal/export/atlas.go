@@ -0,0 +1,114 @@
+// Package export provides a texture atlas built from the block textures
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"math"
+
+	"voxelgame/assets"
+)
+
+// TileSize is the size of one block texture in the atlas, matching the game's texture array
+const TileSize = 16
+
+// atlasInset keeps UVs half a texel away from tile edges so filtering
+// doesn't bleed into neighbouring tiles
+const atlasInset = 0.5 / TileSize
+
+// Atlas packs the block texture layers into a single image, laid out
+// left to right and top to bottom in layer order
+type Atlas struct {
+	Image *image.RGBA
+	Cols  int
+	Rows  int
+}
+
+// NewBlockAtlas builds an atlas from the embedded block textures
+func NewBlockAtlas() *Atlas {
+	return NewAtlas(assets.FS(), assets.BlockTextures)
+}
+
+// NewAtlas builds an atlas from texture files. Missing or unreadable
+// textures get the same magenta placeholder the game uses.
+func NewAtlas(fsys fs.FS, files []string) *Atlas {
+	cols := int(math.Ceil(math.Sqrt(float64(len(files)))))
+	if cols < 1 {
+		cols = 1
+	}
+	rows := (len(files) + cols - 1) / cols
+	if rows < 1 {
+		rows = 1
+	}
+
+	atlas := &Atlas{
+		Image: image.NewRGBA(image.Rect(0, 0, cols*TileSize, rows*TileSize)),
+		Cols:  cols,
+		Rows:  rows,
+	}
+
+	magenta := image.NewUniform(color.RGBA{255, 0, 255, 255})
+	for i, file := range files {
+		tile := atlas.tileRect(i)
+
+		img, err := loadTile(fsys, file)
+		if err != nil {
+			fmt.Printf("[Export] Warning: Failed to load texture %s: %v. Using magenta placeholder.\n", file, err)
+			draw.Draw(atlas.Image, tile, magenta, image.Point{}, draw.Src)
+			continue
+		}
+		draw.Draw(atlas.Image, tile, img, img.Bounds().Min, draw.Src)
+	}
+
+	return atlas
+}
+
+// UV maps a texture coordinate within a layer to atlas coordinates.
+// v = 0 is the top of the tile, as in the game's texture array and glTF.
+func (a *Atlas) UV(layer int, u, v float32) (float32, float32) {
+	if layer < 0 || layer >= a.Cols*a.Rows {
+		layer = 0
+	}
+	col := layer % a.Cols
+	row := layer / a.Cols
+
+	u = atlasInset + u*(1-2*atlasInset)
+	v = atlasInset + v*(1-2*atlasInset)
+	return (float32(col) + u) / float32(a.Cols), (float32(row) + v) / float32(a.Rows)
+}
+
+// EncodePNG writes the atlas image as PNG
+func (a *Atlas) EncodePNG(w io.Writer) error {
+	return png.Encode(w, a.Image)
+}
+
+// tileRect returns the pixel rectangle of a layer
+func (a *Atlas) tileRect(layer int) image.Rectangle {
+	x := (layer % a.Cols) * TileSize
+	y := (layer / a.Cols) * TileSize
+	return image.Rect(x, y, x+TileSize, y+TileSize)
+}
+
+// loadTile decodes a texture and crops it to the tile size
+func loadTile(fsys fs.FS, path string) (image.Image, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, TileSize, TileSize))
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Src)
+	return dst, nil
+}
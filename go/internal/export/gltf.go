@@ -0,0 +1,337 @@
+// Package export provides glTF 2.0 output
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// glTF constants
+const (
+	gltfFloat        = 5126
+	gltfUnsignedInt  = 5125
+	gltfArrayBuffer  = 34962
+	gltfElementArray = 34963
+	gltfTriangles    = 4
+	gltfNearest      = 9728
+	gltfClampToEdge  = 33071
+
+	glbMagic     = 0x46546C67 // "glTF"
+	glbChunkJSON = 0x4E4F534A // "JSON"
+	glbChunkBIN  = 0x004E4942 // "BIN\0"
+)
+
+type gltfDocument struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes"`
+	Meshes      []gltfMesh       `json:"meshes"`
+	Materials   []gltfMaterial   `json:"materials,omitempty"`
+	Textures    []gltfTexture    `json:"textures,omitempty"`
+	Images      []gltfImage      `json:"images,omitempty"`
+	Samplers    []gltfSampler    `json:"samplers,omitempty"`
+	Accessors   []gltfAccessor   `json:"accessors"`
+	BufferViews []gltfBufferView `json:"bufferViews"`
+	Buffers     []gltfBuffer     `json:"buffers"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name string `json:"name,omitempty"`
+	Mesh int    `json:"mesh"`
+}
+
+type gltfMesh struct {
+	Name       string          `json:"name,omitempty"`
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    int            `json:"indices"`
+	Material   int            `json:"material"`
+	Mode       int            `json:"mode"`
+}
+
+type gltfMaterial struct {
+	Name        string   `json:"name"`
+	PBR         gltfPBR  `json:"pbrMetallicRoughness"`
+	AlphaMode   string   `json:"alphaMode,omitempty"`
+	AlphaCutoff *float32 `json:"alphaCutoff,omitempty"`
+	DoubleSided bool     `json:"doubleSided,omitempty"`
+}
+
+type gltfPBR struct {
+	BaseColorFactor  [4]float32       `json:"baseColorFactor"`
+	BaseColorTexture *gltfTextureInfo `json:"baseColorTexture,omitempty"`
+	MetallicFactor   float32          `json:"metallicFactor"`
+	RoughnessFactor  float32          `json:"roughnessFactor"`
+}
+
+type gltfTextureInfo struct {
+	Index int `json:"index"`
+}
+
+type gltfTexture struct {
+	Sampler int `json:"sampler"`
+	Source  int `json:"source"`
+}
+
+type gltfImage struct {
+	URI        string `json:"uri,omitempty"`
+	MimeType   string `json:"mimeType,omitempty"`
+	BufferView *int   `json:"bufferView,omitempty"`
+}
+
+type gltfSampler struct {
+	MagFilter int `json:"magFilter"`
+	MinFilter int `json:"minFilter"`
+	WrapS     int `json:"wrapS"`
+	WrapT     int `json:"wrapT"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target,omitempty"`
+}
+
+type gltfBuffer struct {
+	URI        string `json:"uri,omitempty"`
+	ByteLength int    `json:"byteLength"`
+}
+
+// gltfBuilder accumulates the document and its binary buffer
+type gltfBuilder struct {
+	doc gltfDocument
+	bin bytes.Buffer
+}
+
+// WriteGLTF writes the mesh as glTF 2.0. With glb set the output is a
+// single .glb file, otherwise a .gltf with the buffer and atlas embedded as
+// data URIs.
+func WriteGLTF(path string, mesh *Mesh, glb bool) error {
+	b := &gltfBuilder{}
+	if err := b.build(mesh, glb); err != nil {
+		return err
+	}
+
+	var out []byte
+	var err error
+	if glb {
+		out, err = b.encodeGLB()
+	} else {
+		out, err = b.encodeGLTF()
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+// build fills in the document from the mesh
+func (b *gltfBuilder) build(mesh *Mesh, glb bool) error {
+	b.doc.Asset = gltfAsset{Version: "2.0", Generator: "voxelgame"}
+	b.doc.Scenes = []gltfScene{{Nodes: []int{0}}}
+	b.doc.Nodes = []gltfNode{{Name: "region", Mesh: 0}}
+
+	texture := -1
+	if mesh.Atlas != nil {
+		var png bytes.Buffer
+		if err := mesh.Atlas.EncodePNG(&png); err != nil {
+			return fmt.Errorf("failed to encode atlas: %w", err)
+		}
+
+		img := gltfImage{MimeType: "image/png"}
+		if glb {
+			view := b.addBufferView(png.Bytes(), 0)
+			img.BufferView = &view
+		} else {
+			img.URI = "data:image/png;base64," + base64.StdEncoding.EncodeToString(png.Bytes())
+		}
+
+		b.doc.Images = []gltfImage{img}
+		b.doc.Samplers = []gltfSampler{{
+			MagFilter: gltfNearest,
+			MinFilter: gltfNearest,
+			WrapS:     gltfClampToEdge,
+			WrapT:     gltfClampToEdge,
+		}}
+		b.doc.Textures = []gltfTexture{{Sampler: 0, Source: 0}}
+		texture = 0
+	}
+
+	gm := gltfMesh{Name: "region"}
+	for _, prim := range mesh.Primitives {
+		if len(prim.Indices) == 0 {
+			continue
+		}
+
+		attrs := map[string]int{
+			"POSITION": b.addFloatAccessor(prim.Positions, "VEC3", 3, true),
+			"NORMAL":   b.addFloatAccessor(prim.Normals, "VEC3", 3, false),
+			"COLOR_0":  b.addFloatAccessor(prim.Colors, "VEC3", 3, false),
+		}
+		if prim.TexCoords != nil {
+			attrs["TEXCOORD_0"] = b.addFloatAccessor(prim.TexCoords, "VEC2", 2, false)
+		}
+
+		gm.Primitives = append(gm.Primitives, gltfPrimitive{
+			Attributes: attrs,
+			Indices:    b.addIndexAccessor(prim.Indices),
+			Material:   b.addMaterial(MaterialFor(prim.Material), texture),
+			Mode:       gltfTriangles,
+		})
+	}
+	b.doc.Meshes = []gltfMesh{gm}
+
+	b.doc.Buffers = []gltfBuffer{{ByteLength: b.bin.Len()}}
+	return nil
+}
+
+// addMaterial adds a PBR material that approximates the voxel shader
+func (b *gltfBuilder) addMaterial(mat Material, texture int) int {
+	m := gltfMaterial{
+		Name: mat.Name,
+		PBR: gltfPBR{
+			BaseColorFactor: [4]float32{1, 1, 1, mat.Alpha},
+			RoughnessFactor: mat.Roughness,
+		},
+		DoubleSided: mat.DoubleSided,
+	}
+
+	if texture >= 0 {
+		m.PBR.BaseColorTexture = &gltfTextureInfo{Index: texture}
+		if mat.AlphaCutout {
+			cutoff := float32(0.1)
+			m.AlphaMode = "MASK"
+			m.AlphaCutoff = &cutoff
+		}
+	}
+	if mat.Alpha < 1 {
+		m.AlphaMode = "BLEND"
+		m.AlphaCutoff = nil
+	}
+
+	b.doc.Materials = append(b.doc.Materials, m)
+	return len(b.doc.Materials) - 1
+}
+
+// addFloatAccessor appends float data to the buffer and returns its accessor
+func (b *gltfBuilder) addFloatAccessor(data []float32, typ string, components int, bounds bool) int {
+	raw := make([]byte, len(data)*4)
+	for i, f := range data {
+		binary.LittleEndian.PutUint32(raw[i*4:], math.Float32bits(f))
+	}
+
+	acc := gltfAccessor{
+		BufferView:    b.addBufferView(raw, gltfArrayBuffer),
+		ComponentType: gltfFloat,
+		Count:         len(data) / components,
+		Type:          typ,
+	}
+
+	// POSITION accessors must have bounds
+	if bounds && len(data) >= components {
+		acc.Min = append([]float32{}, data[:components]...)
+		acc.Max = append([]float32{}, data[:components]...)
+		for i := components; i < len(data); i += components {
+			for c := 0; c < components; c++ {
+				acc.Min[c] = float32(math.Min(float64(acc.Min[c]), float64(data[i+c])))
+				acc.Max[c] = float32(math.Max(float64(acc.Max[c]), float64(data[i+c])))
+			}
+		}
+	}
+
+	b.doc.Accessors = append(b.doc.Accessors, acc)
+	return len(b.doc.Accessors) - 1
+}
+
+// addIndexAccessor appends triangle indices to the buffer and returns their accessor
+func (b *gltfBuilder) addIndexAccessor(indices []uint32) int {
+	raw := make([]byte, len(indices)*4)
+	for i, idx := range indices {
+		binary.LittleEndian.PutUint32(raw[i*4:], idx)
+	}
+
+	b.doc.Accessors = append(b.doc.Accessors, gltfAccessor{
+		BufferView:    b.addBufferView(raw, gltfElementArray),
+		ComponentType: gltfUnsignedInt,
+		Count:         len(indices),
+		Type:          "SCALAR",
+	})
+	return len(b.doc.Accessors) - 1
+}
+
+// addBufferView appends data to the buffer, 4-byte aligned, and returns its view
+func (b *gltfBuilder) addBufferView(data []byte, target int) int {
+	for b.bin.Len()%4 != 0 {
+		b.bin.WriteByte(0)
+	}
+
+	b.doc.BufferViews = append(b.doc.BufferViews, gltfBufferView{
+		Buffer:     0,
+		ByteOffset: b.bin.Len(),
+		ByteLength: len(data),
+		Target:     target,
+	})
+	b.bin.Write(data)
+	return len(b.doc.BufferViews) - 1
+}
+
+// encodeGLTF returns the JSON document with the buffer as a data URI
+func (b *gltfBuilder) encodeGLTF() ([]byte, error) {
+	b.doc.Buffers[0].URI = "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(b.bin.Bytes())
+	return json.MarshalIndent(b.doc, "", "  ")
+}
+
+// encodeGLB returns the binary container: header, JSON chunk and BIN chunk
+func (b *gltfBuilder) encodeGLB() ([]byte, error) {
+	for b.bin.Len()%4 != 0 {
+		b.bin.WriteByte(0)
+	}
+	b.doc.Buffers[0].ByteLength = b.bin.Len()
+
+	js, err := json.Marshal(b.doc)
+	if err != nil {
+		return nil, err
+	}
+	// The JSON chunk is padded with spaces
+	for len(js)%4 != 0 {
+		js = append(js, ' ')
+	}
+
+	var out bytes.Buffer
+	total := 12 + 8 + len(js) + 8 + b.bin.Len()
+	binary.Write(&out, binary.LittleEndian, []uint32{glbMagic, 2, uint32(total)})
+	binary.Write(&out, binary.LittleEndian, []uint32{uint32(len(js)), glbChunkJSON})
+	out.Write(js)
+	binary.Write(&out, binary.LittleEndian, []uint32{uint32(b.bin.Len()), glbChunkBIN})
+	out.Write(b.bin.Bytes())
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,176 @@
+// Package export provides conversion of chunk meshes to OBJ and glTF files
+package export
+
+import (
+	"sort"
+
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/core/chunk"
+)
+
+// Offsets into the mesher's vertex layout
+const (
+	attrPosition = 0
+	attrNormal   = 3
+	attrColor    = 6
+	attrTexCoord = 10
+	attrMaterial = 12
+	attrLayer    = 13
+)
+
+// Options controls how chunks are exported
+type Options struct {
+	// Textured adds texture atlas UVs and an atlas image.
+	// Without it faces are colored by their block color only.
+	Textured bool
+
+	// Origin is subtracted from all positions, e.g. the region's minimum corner
+	Origin [3]float32
+}
+
+// Primitive holds the triangles of a single material
+type Primitive struct {
+	Material block.MaterialType
+
+	Positions []float32 // 3 per vertex
+	Normals   []float32 // 3 per vertex
+	Colors    []float32 // 3 per vertex, block color with ambient occlusion
+	TexCoords []float32 // 2 per vertex, atlas UVs with v pointing down (textured only)
+	Indices   []uint32  // Counter-clockwise triangles
+}
+
+// VertexCount returns the number of vertices in the primitive
+func (p *Primitive) VertexCount() int {
+	return len(p.Positions) / 3
+}
+
+// Mesh is an exportable mesh, split into one primitive per material
+type Mesh struct {
+	Primitives []*Primitive
+	Atlas      *Atlas // nil unless textured
+
+	Min, Max [3]float32
+}
+
+// IsEmpty reports whether the mesh has no triangles
+func (m *Mesh) IsEmpty() bool {
+	for _, p := range m.Primitives {
+		if len(p.Indices) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// BuildMesh runs the chunk mesher over the given chunks and merges the
+// results. getBlock is used for face culling across chunk borders, so faces
+// bordering chunks outside the region are kept.
+func BuildMesh(chunks []*chunk.Chunk, getBlock chunk.BlockGetter, opts Options) *Mesh {
+	mesh := &Mesh{}
+	if opts.Textured {
+		mesh.Atlas = NewBlockAtlas()
+	}
+
+	mesher := chunk.NewMesher()
+	primitives := make(map[block.MaterialType]*Primitive)
+	first := true
+
+	for _, c := range chunks {
+		data := mesher.GenerateMesh(c, getBlock)
+		if data == nil {
+			continue
+		}
+
+		// Mesher vertex index -> primitive vertex index, per material
+		remap := make(map[block.MaterialType]map[uint32]uint32)
+
+		for t := 0; t+2 < len(data.Indices); t += 3 {
+			tri := data.Indices[t : t+3]
+			mat := block.MaterialType(data.Vertices[int(tri[0])*chunk.VertexSize+attrMaterial])
+
+			prim, ok := primitives[mat]
+			if !ok {
+				prim = &Primitive{Material: mat}
+				primitives[mat] = prim
+			}
+			if remap[mat] == nil {
+				remap[mat] = make(map[uint32]uint32)
+			}
+
+			var out [3]uint32
+			for i, src := range tri {
+				dst, ok := remap[mat][src]
+				if !ok {
+					dst = uint32(prim.VertexCount())
+					remap[mat][src] = dst
+					mesh.addVertex(prim, data.Vertices[int(src)*chunk.VertexSize:], opts, first)
+					first = false
+				}
+				out[i] = dst
+			}
+
+			// The mesher emits clockwise triangles, exported formats expect counter-clockwise
+			prim.Indices = append(prim.Indices, out[0], out[2], out[1])
+		}
+	}
+
+	for _, prim := range primitives {
+		mesh.Primitives = append(mesh.Primitives, prim)
+	}
+	sort.Slice(mesh.Primitives, func(i, j int) bool {
+		return mesh.Primitives[i].Material < mesh.Primitives[j].Material
+	})
+
+	return mesh
+}
+
+// addVertex appends one mesher vertex to a primitive and grows the bounds
+func (m *Mesh) addVertex(prim *Primitive, v []float32, opts Options, first bool) {
+	var pos [3]float32
+	for i := 0; i < 3; i++ {
+		pos[i] = v[attrPosition+i] - opts.Origin[i]
+		if first || pos[i] < m.Min[i] {
+			m.Min[i] = pos[i]
+		}
+		if first || pos[i] > m.Max[i] {
+			m.Max[i] = pos[i]
+		}
+	}
+
+	prim.Positions = append(prim.Positions, pos[0], pos[1], pos[2])
+	prim.Normals = append(prim.Normals, v[attrNormal], v[attrNormal+1], v[attrNormal+2])
+	prim.Colors = append(prim.Colors, v[attrColor], v[attrColor+1], v[attrColor+2])
+
+	if m.Atlas != nil {
+		u, tv := m.Atlas.UV(int(v[attrLayer]), v[attrTexCoord], v[attrTexCoord+1])
+		prim.TexCoords = append(prim.TexCoords, u, tv)
+	}
+}
+
+// Material describes how a block material is exported
+type Material struct {
+	Name      string
+	Alpha     float32 // 1 for opaque materials
+	Roughness float32
+	Specular  float32 // Phong exponent for OBJ
+
+	AlphaCutout bool // Transparent texels are cut out rather than blended
+	DoubleSided bool
+}
+
+// materials mirrors the material handling of the voxel shader
+var materials = map[block.MaterialType]Material{
+	block.MaterialStandard: {Name: "standard", Alpha: 1, Roughness: 0.9, Specular: 10},
+	block.MaterialFoliage:  {Name: "foliage", Alpha: 1, Roughness: 0.8, Specular: 10, AlphaCutout: true, DoubleSided: true},
+	block.MaterialLiquid:   {Name: "liquid", Alpha: 0.65, Roughness: 0.1, Specular: 200},
+	block.MaterialGlass:    {Name: "glass", Alpha: 0.75, Roughness: 0.05, Specular: 300},
+	block.MaterialStone:    {Name: "stone", Alpha: 1, Roughness: 0.75, Specular: 30},
+}
+
+// MaterialFor returns the export material for a block material
+func MaterialFor(mat block.MaterialType) Material {
+	if m, ok := materials[mat]; ok {
+		return m
+	}
+	return materials[block.MaterialStandard]
+}
@@ -0,0 +1,129 @@
+// Package export provides Wavefront OBJ output
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteOBJ writes the mesh to an .obj file with a .mtl material library
+// next to it. Vertex colors are written as "v x y z r g b", which most
+// importers understand. Textured meshes also get a PNG atlas.
+func WriteOBJ(path string, mesh *Mesh) error {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	dir := filepath.Dir(path)
+	mtlName := base + ".mtl"
+	atlasName := base + "_atlas.png"
+
+	if mesh.Atlas != nil {
+		if err := writeAtlasPNG(filepath.Join(dir, atlasName), mesh.Atlas); err != nil {
+			return fmt.Errorf("failed to write atlas: %w", err)
+		}
+	}
+
+	if err := writeMTL(filepath.Join(dir, mtlName), mesh, atlasName); err != nil {
+		return fmt.Errorf("failed to write materials: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+
+	fmt.Fprintf(w, "# voxelgame region export\n")
+	fmt.Fprintf(w, "mtllib %s\n", mtlName)
+	fmt.Fprintf(w, "o %s\n", base)
+
+	// OBJ indices are global and 1-based
+	offset := 1
+	for _, prim := range mesh.Primitives {
+		n := prim.VertexCount()
+		fmt.Fprintf(w, "\ng %s\n", MaterialFor(prim.Material).Name)
+
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(w, "v %g %g %g %.4f %.4f %.4f\n",
+				prim.Positions[i*3], prim.Positions[i*3+1], prim.Positions[i*3+2],
+				prim.Colors[i*3], prim.Colors[i*3+1], prim.Colors[i*3+2])
+		}
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(w, "vn %g %g %g\n", prim.Normals[i*3], prim.Normals[i*3+1], prim.Normals[i*3+2])
+		}
+		if prim.TexCoords != nil {
+			// OBJ texture coordinates have v pointing up
+			for i := 0; i < n; i++ {
+				fmt.Fprintf(w, "vt %.6f %.6f\n", prim.TexCoords[i*2], 1-prim.TexCoords[i*2+1])
+			}
+		}
+
+		fmt.Fprintf(w, "usemtl %s\n", MaterialFor(prim.Material).Name)
+		for t := 0; t+2 < len(prim.Indices); t += 3 {
+			a := int(prim.Indices[t]) + offset
+			b := int(prim.Indices[t+1]) + offset
+			c := int(prim.Indices[t+2]) + offset
+			if prim.TexCoords != nil {
+				fmt.Fprintf(w, "f %d/%d/%d %d/%d/%d %d/%d/%d\n", a, a, a, b, b, b, c, c, c)
+			} else {
+				fmt.Fprintf(w, "f %d//%d %d//%d %d//%d\n", a, a, b, b, c, c)
+			}
+		}
+
+		offset += n
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeMTL writes one material per primitive
+func writeMTL(path string, mesh *Mesh, atlasName string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+
+	fmt.Fprintf(w, "# voxelgame region export\n")
+	for _, prim := range mesh.Primitives {
+		mat := MaterialFor(prim.Material)
+		fmt.Fprintf(w, "\nnewmtl %s\n", mat.Name)
+		// Block colors come from the vertex colors, so the diffuse color is white
+		fmt.Fprintf(w, "Ka 0 0 0\n")
+		fmt.Fprintf(w, "Kd 1 1 1\n")
+		fmt.Fprintf(w, "Ks 0.2 0.2 0.2\n")
+		fmt.Fprintf(w, "Ns %g\n", mat.Specular)
+		fmt.Fprintf(w, "d %g\n", mat.Alpha)
+		fmt.Fprintf(w, "illum 2\n")
+		if mesh.Atlas != nil {
+			fmt.Fprintf(w, "map_Kd %s\n", atlasName)
+			if mat.AlphaCutout {
+				fmt.Fprintf(w, "map_d %s\n", atlasName)
+			}
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeAtlasPNG writes the atlas image to a file
+func writeAtlasPNG(path string, atlas *Atlas) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := atlas.EncodePNG(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
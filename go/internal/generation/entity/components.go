@@ -0,0 +1,164 @@
+// Package entity provides the components entities are built from
+package entity
+
+import (
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/physics"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Transform places an entity in the world
+type Transform struct {
+	Position mgl32.Vec3 // Bottom center, at the feet of creatures
+	Rotation float32    // Yaw in radians
+}
+
+// Motion is how a body moves
+type Motion int
+
+const (
+	MotionWalk   Motion = iota // Falls and stands on the ground
+	MotionHover                // Floats above the ground
+	MotionSwim                 // Stays under the water surface
+	MotionDriven               // Moved by its owner, like the player
+)
+
+// Body is the physical extent and movement of an entity, colliding with
+// blocks like the player does
+type Body struct {
+	physics.Body
+	Motion Motion
+}
+
+// Health is how much damage an entity takes before it dies
+type Health struct {
+	Current int
+	Max     int
+}
+
+// Damage takes health away and reports whether it was the last of it
+func (h *Health) Damage(amount int) bool {
+	h.Current -= amount
+	if h.Current < 0 {
+		h.Current = 0
+	}
+	return h.Current == 0
+}
+
+// Dead reports whether the health is gone
+func (h *Health) Dead() bool {
+	return h.Current <= 0
+}
+
+// Brain states
+const (
+	StateIdle   = "idle"
+	StateWander = "wander"
+	StateFlee   = "flee"
+)
+
+// Brain is the AI of a creature
+type Brain struct {
+	State     string
+	Behaviors []Behavior
+	Target    *mgl32.Vec3
+	Timer     float32
+
+	Speed     float32
+	JumpForce float32
+	Damage    int
+	Hostile   bool
+}
+
+// Has reports whether the brain has a behavior
+func (b *Brain) Has(behavior Behavior) bool {
+	for _, have := range b.Behaviors {
+		if have == behavior {
+			return true
+		}
+	}
+	return false
+}
+
+// Animation is the state of a model's animations
+type Animation struct {
+	Time       float32 // Continuous time for animations
+	WalkPhase  float32 // 0-2π cycle for walk animation
+	Moving     bool    // Whether the entity is walking
+	SwingPhase float32 // 0-π for swing animation (arm swing)
+	Swinging   bool    // Whether the entity is swinging/attacking
+}
+
+// Renderable is how an entity looks: a creature model built from body
+// parts, or, without parts, the item it carries
+type Renderable struct {
+	Template  CreatureTemplate
+	Size      float32
+	Biome     string // Biome whose palette colored the model
+	BodyParts []BodyPart
+
+	PrimaryColor   [3]float32
+	SecondaryColor [3]float32
+	AccentColor    [3]float32
+
+	Item  block.Type // Shown for models without body parts
+	Scale float32    // Size of the item shown
+
+	Animation
+}
+
+// IsItem reports whether the entity is drawn as an item
+func (r *Renderable) IsItem() bool {
+	return len(r.BodyParts) == 0
+}
+
+// ItemStack is a number of items of one type
+type ItemStack struct {
+	Item  block.Type
+	Count int
+}
+
+// Inventory holds the items an entity carries
+type Inventory struct {
+	Held   block.Type // Item in hand, drawn with the model
+	Stacks []ItemStack
+
+	// Collects makes the entity pick up dropped items it walks over
+	Collects bool
+
+	// OnCollect, if set, receives collected items instead of Stacks, for
+	// inventories kept elsewhere like the player's hotbar. It reports
+	// whether the items were taken.
+	OnCollect func(item block.Type, count int) bool
+}
+
+// Add puts items into the inventory and reports whether they were taken
+func (inv *Inventory) Add(item block.Type, count int) bool {
+	if inv.OnCollect != nil {
+		return inv.OnCollect(item, count)
+	}
+	for i := range inv.Stacks {
+		if inv.Stacks[i].Item == item {
+			inv.Stacks[i].Count += count
+			return true
+		}
+	}
+	inv.Stacks = append(inv.Stacks, ItemStack{item, count})
+	return true
+}
+
+// Item is an item lying in the world, waiting to be picked up
+type Item struct {
+	Type  block.Type
+	Count int
+	Age   float32 // Seconds since it was dropped
+}
+
+// Projectile is a thrown entity that hurts what it hits
+type Projectile struct {
+	Owner  ID // Not hit by its own projectile
+	Damage int
+	Life   float32    // Seconds left before it falls apart
+	Drops  block.Type // Dropped as an item where it lands
+}
@@ -2,9 +2,6 @@
 package entity
 
 import (
-	"math"
-
-	"voxelgame/internal/core/block"
 	vmath "voxelgame/pkg/math"
 
 	"github.com/go-gl/mathgl/mgl32"
@@ -42,11 +39,20 @@ const (
 
 // BiomeColors defines creature color palettes by biome
 var BiomeColors = map[string][][3]float32{
-	"plains":    {{0.55, 0.27, 0.07}, {0.85, 0.65, 0.13}, {0.96, 0.87, 0.70}, {0.82, 0.71, 0.55}, {0.63, 0.32, 0.18}},
-	"forest":    {{0.13, 0.55, 0.13}, {0.55, 0.27, 0.07}, {0.18, 0.55, 0.34}, {0.42, 0.56, 0.14}, {0.33, 0.42, 0.18}},
-	"desert":    {{0.88, 0.75, 0.56}, {0.87, 0.72, 0.53}, {0.96, 0.64, 0.38}, {0.82, 0.41, 0.12}, {0.80, 0.52, 0.25}},
-	"snow":      {{1.0, 1.0, 1.0}, {0.94, 0.97, 1.0}, {0.90, 0.90, 0.98}, {0.69, 0.77, 0.87}, {0.47, 0.53, 0.60}},
-	"mountains": {{0.41, 0.41, 0.41}, {0.50, 0.50, 0.50}, {0.66, 0.66, 0.66}, {0.18, 0.31, 0.31}, {0.44, 0.50, 0.56}},
+	"plains":       {{0.55, 0.27, 0.07}, {0.85, 0.65, 0.13}, {0.96, 0.87, 0.70}, {0.82, 0.71, 0.55}, {0.63, 0.32, 0.18}},
+	"forest":       {{0.13, 0.55, 0.13}, {0.55, 0.27, 0.07}, {0.18, 0.55, 0.34}, {0.42, 0.56, 0.14}, {0.33, 0.42, 0.18}},
+	"birch_forest": {{0.85, 0.82, 0.70}, {0.40, 0.62, 0.20}, {0.60, 0.45, 0.30}, {0.95, 0.92, 0.85}, {0.30, 0.30, 0.28}},
+	"desert":       {{0.88, 0.75, 0.56}, {0.87, 0.72, 0.53}, {0.96, 0.64, 0.38}, {0.82, 0.41, 0.12}, {0.80, 0.52, 0.25}},
+	"snow":         {{1.0, 1.0, 1.0}, {0.94, 0.97, 1.0}, {0.90, 0.90, 0.98}, {0.69, 0.77, 0.87}, {0.47, 0.53, 0.60}},
+	"mountains":    {{0.41, 0.41, 0.41}, {0.50, 0.50, 0.50}, {0.66, 0.66, 0.66}, {0.18, 0.31, 0.31}, {0.44, 0.50, 0.56}},
+	"taiga":        {{0.36, 0.25, 0.20}, {0.55, 0.45, 0.35}, {0.80, 0.78, 0.75}, {0.20, 0.33, 0.27}, {0.45, 0.36, 0.30}},
+	"swamp":        {{0.33, 0.42, 0.18}, {0.42, 0.37, 0.20}, {0.24, 0.30, 0.18}, {0.55, 0.60, 0.30}, {0.30, 0.25, 0.15}},
+	"savanna":      {{0.80, 0.65, 0.35}, {0.70, 0.50, 0.25}, {0.90, 0.80, 0.55}, {0.55, 0.40, 0.20}, {0.35, 0.25, 0.15}},
+	"jungle":       {{0.10, 0.50, 0.15}, {0.95, 0.75, 0.10}, {0.85, 0.25, 0.15}, {0.20, 0.35, 0.70}, {0.40, 0.25, 0.10}},
+	"badlands":     {{0.75, 0.40, 0.20}, {0.65, 0.30, 0.15}, {0.85, 0.60, 0.40}, {0.55, 0.35, 0.25}, {0.90, 0.75, 0.55}},
+	"stony_shore":  {{0.50, 0.50, 0.52}, {0.35, 0.35, 0.38}, {0.85, 0.85, 0.85}, {0.25, 0.30, 0.35}, {0.65, 0.60, 0.55}},
+	"beach":        {{0.96, 0.87, 0.70}, {0.90, 0.55, 0.45}, {0.85, 0.80, 0.65}, {0.60, 0.70, 0.75}, {0.95, 0.65, 0.35}},
+	"ocean":        {{0.25, 0.45, 0.70}, {0.40, 0.65, 0.80}, {0.85, 0.55, 0.30}, {0.70, 0.75, 0.80}, {0.20, 0.30, 0.50}},
 }
 
 // BodyPart represents a part of a creature's body
@@ -66,57 +72,10 @@ type CreatureStats struct {
 	Hostile   bool
 }
 
-// Creature represents a procedurally generated creature
-type Creature struct {
-	ID       int
-	Template CreatureTemplate
-	Size     float32
-	Biome    string
-
-	// Body
-	BodyParts []BodyPart
-	Height    float32
-	Width     float32
-	Depth     float32
-
-	// Appearance
-	PrimaryColor   [3]float32
-	SecondaryColor [3]float32
-	AccentColor    [3]float32
-
-	// Behavior
-	Behaviors []Behavior
-	State     string
-
-	// Position and movement
-	Position mgl32.Vec3
-	Velocity mgl32.Vec3
-	Rotation float32
-
-	// AI state
-	Target *mgl32.Vec3
-	Timer  float32
-
-	// Stats
-	Stats CreatureStats
-
-	// Inventory
-	HeldItem block.Type
-
-	// Animation state
-	AnimationTime float32 // Continuous time for animations
-	WalkPhase     float32 // 0-2π cycle for walk animation
-	IsMoving      bool    // Whether creature is currently moving
-	GroundY       float32 // Current ground level Y position
-	SwingPhase    float32 // 0-PI for swing animation (arm swing)
-	IsSwinging    bool    // Whether creature is currently swinging/attacking
-}
-
 // Generator creates procedural creatures
 type Generator struct {
-	seed       int64
-	rng        *vmath.SeededRNG
-	creatureID int
+	seed int64
+	rng  *vmath.SeededRNG
 }
 
 // NewGenerator creates a new creature generator
@@ -127,44 +86,63 @@ func NewGenerator(seed int64) *Generator {
 	}
 }
 
-// Create creates a new procedural creature
-func (g *Generator) Create(template CreatureTemplate, biome string, position mgl32.Vec3, size float32) *Creature {
+// Spawn creates a procedural creature in the registry and returns it. A
+// size of 0 picks a random one.
+func (g *Generator) Spawn(r *Registry, template CreatureTemplate, biome string, position mgl32.Vec3, size float32) ID {
 	if size <= 0 {
 		size = float32(g.rng.NextFloat(0.5, 2.0))
 	}
 
-	c := &Creature{
-		ID:       g.creatureID,
-		Template: template,
-		Size:     size,
-		Biome:    biome,
-		Position: position,
-		State:    "idle",
-	}
-	g.creatureID++
-
-	// Generate body
-	c.BodyParts = g.generateBody(template, size)
-	c.Height = size
-	c.Width = size * 0.6
-	c.Depth = size * 0.8
+	id := r.Create()
+	r.Transforms.Add(id, Transform{Position: position})
 
-	// Generate colors
-	c.PrimaryColor, c.SecondaryColor, c.AccentColor = g.generateColors(biome)
-
-	// Generate behaviors
-	c.Behaviors = g.generateBehaviors(template)
-
-	// Generate stats
-	c.Stats = g.generateStats(template, size)
-
-	return c
+	// Generate body and colors
+	model := Renderable{
+		Template:  template,
+		Size:      size,
+		Biome:     biome,
+		BodyParts: g.generateBody(template, size),
+	}
+	model.PrimaryColor, model.SecondaryColor, model.AccentColor = g.generateColors(biome)
+	r.Renderables.Add(id, model)
+	r.Bodies.Add(id, creatureBody(template, size))
+
+	// Generate behaviors and stats
+	stats := g.generateStats(template, size)
+	r.Healths.Add(id, Health{Current: stats.Health, Max: stats.MaxHealth})
+	r.Brains.Add(id, Brain{
+		State:     StateIdle,
+		Behaviors: g.generateBehaviors(template),
+		Speed:     stats.Speed,
+		JumpForce: stats.JumpForce,
+		Damage:    stats.Damage,
+		Hostile:   stats.Hostile,
+	})
+	r.Inventories.Add(id, Inventory{})
+
+	return id
 }
 
-// CreateRandom creates a random creature for the given biome
-func (g *Generator) CreateRandom(biome string, position mgl32.Vec3) *Creature {
-	template := vmath.Choose(g.rng, AllTemplates)
-	return g.Create(template, biome, position, 0)
+// creatureBody returns the body of a creature, moving the way its
+// template does. Creatures walk up single blocks instead of jumping.
+func creatureBody(template CreatureTemplate, size float32) Body {
+	body := Body{Motion: MotionWalk}
+	body.Width = size * 0.6
+	body.Height = size
+	body.Gravity = 20
+	body.Friction = 6
+	body.Drag = 6
+	body.StepHeight = 1
+	switch template {
+	case TemplateFlying:
+		body.Motion = MotionHover
+		body.Gravity = 0
+	case TemplateFish:
+		body.Motion = MotionSwim
+	case TemplateSlime:
+		body.Gravity = 15 // Slimes bounce
+	}
+	return body
 }
 
 func (g *Generator) generateBody(template CreatureTemplate, size float32) []BodyPart {
@@ -307,106 +285,6 @@ func (g *Generator) generateStats(template CreatureTemplate, size float32) Creat
 	return stats
 }
 
-// Update updates creature behavior
-func (c *Creature) Update(dt float32, playerPos mgl32.Vec3) {
-	c.Timer += dt
-	c.AnimationTime += dt
-
-	switch c.State {
-	case "idle":
-		if c.Timer > 2+float32(math.Mod(float64(c.Timer), 3)) {
-			c.State = "wander"
-			c.Timer = 0
-			// Set random target nearby
-			target := mgl32.Vec3{
-				c.Position.X() + float32(math.Cos(float64(c.Timer)*10))*10,
-				c.Position.Y(),
-				c.Position.Z() + float32(math.Sin(float64(c.Timer)*10))*10,
-			}
-			c.Target = &target
-		}
-
-	case "wander":
-		if c.Target != nil {
-			dx := c.Target.X() - c.Position.X()
-			dz := c.Target.Z() - c.Position.Z()
-			dist := float32(math.Sqrt(float64(dx*dx + dz*dz)))
-
-			if dist < 0.5 || c.Timer > 5 {
-				c.State = "idle"
-				c.Timer = 0
-				c.Target = nil
-			} else {
-				c.Velocity[0] = (dx / dist) * c.Stats.Speed
-				c.Velocity[2] = (dz / dist) * c.Stats.Speed
-				c.Rotation = float32(math.Atan2(float64(dx), float64(dz)))
-			}
-		}
-
-	case "flee":
-		dx := c.Position.X() - playerPos.X()
-		dz := c.Position.Z() - playerPos.Z()
-		dist := float32(math.Sqrt(float64(dx*dx + dz*dz)))
-
-		if dist > 15 {
-			c.State = "idle"
-			c.Timer = 0
-		} else {
-			c.Velocity[0] = (dx / dist) * c.Stats.Speed * 1.5
-			c.Velocity[2] = (dz / dist) * c.Stats.Speed * 1.5
-			c.Rotation = float32(math.Atan2(float64(-dx), float64(-dz)))
-		}
-	}
-
-	// Slimes jump periodically
-	if c.Template == TemplateSlime && c.Timer > 1 {
-		if c.Position.Y() < c.GroundY+0.1 {
-			c.Velocity[1] = c.Stats.JumpForce
-			c.Timer = 0
-		}
-	}
-
-	// Apply velocity
-	c.Position = c.Position.Add(c.Velocity.Mul(dt))
-
-	// Update animation state
-	speed := float32(math.Sqrt(float64(c.Velocity[0]*c.Velocity[0] + c.Velocity[2]*c.Velocity[2])))
-	c.IsMoving = speed > 0.1
-
-	if c.IsMoving {
-		// Walk phase cycles at speed proportional to movement
-		c.WalkPhase += dt * speed * 3.0
-		if c.WalkPhase > 2*math.Pi {
-			c.WalkPhase -= 2 * math.Pi
-		}
-	}
-
-	// Dampen velocity
-	c.Velocity[0] *= 0.9
-	c.Velocity[2] *= 0.9
-
-	// Update swing animation
-	if c.IsSwinging {
-		c.SwingPhase += dt * 15.0 // Speed of swing
-		if c.SwingPhase > math.Pi {
-			c.SwingPhase = 0 // Reset or loop? Reset is better for clicks.
-			// Ideally main logic controls IsSwinging, but we wrap phase here.
-			// Actually, let's keep it simple: if swinging, increment phase.
-			// If phase wraps, we can either stop swinging or loop.
-			// For continuous breaking, looping is good.
-			c.SwingPhase -= math.Pi
-		}
-	} else {
-		// Reset to 0 when not swinging
-		if c.SwingPhase > 0 {
-			c.SwingPhase += dt * 10.0
-			if c.SwingPhase > math.Pi {
-				c.SwingPhase = 0
-			}
-		}
-	}
-}
-
 func min32(a, b float32) float32 {
 	if a < b {
 		return a
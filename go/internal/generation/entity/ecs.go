@@ -0,0 +1,118 @@
+// Package entity provides the entity-component system
+package entity
+
+// ID identifies an entity. Entities are nothing but an ID; what they are
+// and do comes from the components added to them.
+type ID uint32
+
+// Store holds one kind of component for the entities that have it, in a
+// dense list so systems iterate without hashing
+type Store[T any] struct {
+	index map[ID]int
+	ids   []ID
+	items []*T
+}
+
+// Add gives an entity a component, replacing any it had, and returns it
+func (s *Store[T]) Add(id ID, c T) *T {
+	if s.index == nil {
+		s.index = make(map[ID]int)
+	}
+	if i, ok := s.index[id]; ok {
+		*s.items[i] = c
+		return s.items[i]
+	}
+	s.index[id] = len(s.ids)
+	s.ids = append(s.ids, id)
+	s.items = append(s.items, &c)
+	return &c
+}
+
+// Get returns an entity's component, or nil if it has none
+func (s *Store[T]) Get(id ID) *T {
+	if i, ok := s.index[id]; ok {
+		return s.items[i]
+	}
+	return nil
+}
+
+// Has reports whether an entity has the component
+func (s *Store[T]) Has(id ID) bool {
+	_, ok := s.index[id]
+	return ok
+}
+
+// Remove takes the component from an entity
+func (s *Store[T]) Remove(id ID) {
+	i, ok := s.index[id]
+	if !ok {
+		return
+	}
+	last := len(s.ids) - 1
+	s.ids[i], s.items[i] = s.ids[last], s.items[last]
+	s.index[s.ids[i]] = i
+	s.ids, s.items = s.ids[:last], s.items[:last]
+	delete(s.index, id)
+}
+
+// Len returns the number of entities with the component
+func (s *Store[T]) Len() int {
+	return len(s.ids)
+}
+
+// Each calls fn for every entity with the component. Entities destroyed
+// meanwhile keep their components until the registry is flushed, so fn
+// may destroy any entity.
+func (s *Store[T]) Each(fn func(id ID, c *T)) {
+	for i := 0; i < len(s.ids); i++ {
+		fn(s.ids[i], s.items[i])
+	}
+}
+
+// Registry holds the entities of a world and their components
+type Registry struct {
+	next      ID
+	destroyed []ID
+
+	Transforms  Store[Transform]
+	Bodies      Store[Body]
+	Healths     Store[Health]
+	Brains      Store[Brain]
+	Renderables Store[Renderable]
+	Inventories Store[Inventory]
+	Items       Store[Item]
+	Projectiles Store[Projectile]
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{next: 1}
+}
+
+// Create returns a new entity without components
+func (r *Registry) Create() ID {
+	id := r.next
+	r.next++
+	return id
+}
+
+// Destroy removes an entity with all its components when the registry is
+// next flushed
+func (r *Registry) Destroy(id ID) {
+	r.destroyed = append(r.destroyed, id)
+}
+
+// Flush removes the entities destroyed since the last flush
+func (r *Registry) Flush() {
+	for _, id := range r.destroyed {
+		r.Transforms.Remove(id)
+		r.Bodies.Remove(id)
+		r.Healths.Remove(id)
+		r.Brains.Remove(id)
+		r.Renderables.Remove(id)
+		r.Inventories.Remove(id)
+		r.Items.Remove(id)
+		r.Projectiles.Remove(id)
+	}
+	r.destroyed = r.destroyed[:0]
+}
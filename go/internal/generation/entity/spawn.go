@@ -0,0 +1,75 @@
+// Package entity provides the entities built from components
+package entity
+
+import (
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/physics"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	itemSize       = 0.25     // Width of a dropped item
+	projectileSize = itemSize // The same, so it drops where it fits
+	projectileLife = 5.0      // Seconds a projectile flies before falling apart
+	playerHealth   = 20
+)
+
+// SpawnPlayer creates the player's entity: a biped model moved by the
+// player's physics, which picks up dropped items
+func (g *Generator) SpawnPlayer(r *Registry, position mgl32.Vec3) ID {
+	id := r.Create()
+	r.Transforms.Add(id, Transform{Position: position})
+
+	model := Renderable{
+		Template:  TemplateBiped,
+		Size:      1,
+		Biome:     "plains",
+		BodyParts: g.generateBody(TemplateBiped, 1),
+	}
+	_, _, model.AccentColor = g.generateColors("plains")
+	model.PrimaryColor = [3]float32{0.2, 0.2, 0.8}
+	model.SecondaryColor = [3]float32{0.1, 0.1, 0.1}
+	r.Renderables.Add(id, model)
+
+	body := Body{Motion: MotionDriven}
+	body.Width = physics.PlayerWidth
+	body.Height = physics.PlayerHeight
+	r.Bodies.Add(id, body)
+	r.Healths.Add(id, Health{Current: playerHealth, Max: playerHealth})
+	r.Inventories.Add(id, Inventory{Collects: true})
+	return id
+}
+
+// SpawnItem drops items into the world at a position
+func SpawnItem(r *Registry, item block.Type, count int, position, velocity mgl32.Vec3) ID {
+	id := r.Create()
+	r.Transforms.Add(id, Transform{Position: position})
+	r.Bodies.Add(id, Body{Body: physics.Body{
+		Velocity: velocity,
+		Width:    itemSize,
+		Height:   itemSize,
+		Gravity:  20,
+		Friction: 8,
+		Drag:     0.5,
+	}})
+	r.Renderables.Add(id, Renderable{Item: item, Scale: itemSize})
+	r.Items.Add(id, Item{Type: item, Count: count})
+	return id
+}
+
+// SpawnProjectile throws an item, which hurts what it hits and drops where
+// it lands
+func SpawnProjectile(r *Registry, owner ID, item block.Type, damage int, position, velocity mgl32.Vec3) ID {
+	id := r.Create()
+	r.Transforms.Add(id, Transform{Position: position})
+	r.Bodies.Add(id, Body{Body: physics.Body{
+		Velocity: velocity,
+		Width:    projectileSize,
+		Height:   projectileSize,
+		Gravity:  20,
+	}})
+	r.Renderables.Add(id, Renderable{Item: item, Scale: projectileSize})
+	r.Projectiles.Add(id, Projectile{Owner: owner, Damage: damage, Life: projectileLife, Drops: item})
+	return id
+}
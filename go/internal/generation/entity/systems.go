@@ -0,0 +1,266 @@
+// Package entity provides the systems that update entities
+package entity
+
+import (
+	"math"
+
+	"voxelgame/internal/core/block"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Terrain is the world as the systems see it
+type Terrain interface {
+	GetBlock(x, y, z int) block.Type
+	// GroundHeight returns the floor under a position
+	GroundHeight(x, y, z int) int
+}
+
+const (
+	seaLevel = 12
+
+	itemLifetime = 300.0 // Seconds before a dropped item disappears
+	pickupDelay  = 0.5   // Seconds before a dropped item can be picked up
+	pickupRadius = 1.5
+	hitMargin    = 0.2 // Reach of projectiles around a body
+	followRate   = 6.0 // Speed hovering and swimming bodies close the gap to their height with
+)
+
+// UpdateBrains runs the AI of every entity with a brain
+func UpdateBrains(r *Registry, dt float32, playerPos mgl32.Vec3) {
+	r.Brains.Each(func(id ID, b *Brain) {
+		t, body := r.Transforms.Get(id), r.Bodies.Get(id)
+		if t == nil || body == nil {
+			return
+		}
+		b.Timer += dt
+
+		switch b.State {
+		case StateIdle:
+			if b.Timer > 2+float32(math.Mod(float64(b.Timer), 3)) {
+				// Set random target nearby
+				angle := float64(b.Timer) * 10
+				target := mgl32.Vec3{
+					t.Position.X() + float32(math.Cos(angle))*10,
+					t.Position.Y(),
+					t.Position.Z() + float32(math.Sin(angle))*10,
+				}
+				b.State = StateWander
+				b.Timer = 0
+				b.Target = &target
+			}
+
+		case StateWander:
+			if b.Target != nil {
+				dx := b.Target.X() - t.Position.X()
+				dz := b.Target.Z() - t.Position.Z()
+				dist := float32(math.Sqrt(float64(dx*dx + dz*dz)))
+
+				// Give up at walls too high to step onto
+				if dist < 0.5 || b.Timer > 5 || body.HitWall {
+					b.State = StateIdle
+					b.Timer = 0
+					b.Target = nil
+				} else {
+					body.Velocity[0] = (dx / dist) * b.Speed
+					body.Velocity[2] = (dz / dist) * b.Speed
+					t.Rotation = float32(math.Atan2(float64(dx), float64(dz)))
+				}
+			}
+
+		case StateFlee:
+			dx := t.Position.X() - playerPos.X()
+			dz := t.Position.Z() - playerPos.Z()
+			dist := float32(math.Sqrt(float64(dx*dx + dz*dz)))
+
+			if dist > 15 {
+				b.State = StateIdle
+				b.Timer = 0
+			} else if dist > 0 {
+				body.Velocity[0] = (dx / dist) * b.Speed * 1.5
+				body.Velocity[2] = (dz / dist) * b.Speed * 1.5
+				t.Rotation = float32(math.Atan2(float64(dx), float64(dz)))
+			}
+		}
+
+		// Jumpers like slimes jump periodically
+		if b.Has(BehaviorJump) && b.Timer > 1 && body.OnGround {
+			body.Velocity[1] = b.JumpForce
+			b.Timer = 0
+		}
+	})
+}
+
+// UpdateBodies moves every body through the terrain. Hovering bodies
+// float toward a height above the ground and swimming ones toward the water
+// surface. Driven bodies are left to their owner.
+func UpdateBodies(r *Registry, dt float32, terrain Terrain) {
+	r.Bodies.Each(func(id ID, b *Body) {
+		t := r.Transforms.Get(id)
+		if t == nil || b.Motion == MotionDriven {
+			return
+		}
+
+		var animTime float32
+		if m := r.Renderables.Get(id); m != nil {
+			animTime = m.Time
+		}
+		pos := t.Position
+		x, y, z := int(math.Floor(float64(pos.X()))), int(math.Floor(float64(pos.Y()))), int(math.Floor(float64(pos.Z())))
+
+		switch b.Motion {
+		case MotionHover:
+			surface := float32(terrain.GroundHeight(x, y, z) + 1)
+			hoverHeight := surface + 3.0 + float32(math.Sin(float64(animTime)*2.0))*0.5
+			b.Velocity[1] = (hoverHeight - pos.Y()) * followRate
+
+		case MotionSwim:
+			// Out of water, fish fall and flop on the ground
+			if terrain.GroundHeight(x, y, z)+1 < seaLevel {
+				depth := seaLevel - 1.0 + float32(math.Sin(float64(animTime)*3.0))*0.3
+				b.Velocity[1] = (depth-pos.Y())*followRate + b.Gravity*dt // Buoyant
+			}
+		}
+
+		t.Position = b.Update(pos, dt, terrain.GetBlock)
+	})
+}
+
+// UpdateAnimations advances the animations of every model from the
+// movement of its body
+func UpdateAnimations(r *Registry, dt float32) {
+	r.Renderables.Each(func(id ID, m *Renderable) {
+		m.Time += dt
+
+		if b := r.Bodies.Get(id); b != nil {
+			speed := float32(math.Sqrt(float64(b.Velocity[0]*b.Velocity[0] + b.Velocity[2]*b.Velocity[2])))
+			// Driven bodies only walk on the ground, others whenever they move
+			m.Moving = speed > 0.1 && (b.Motion != MotionDriven || b.OnGround)
+			if m.Moving {
+				// Walk phase cycles at speed proportional to movement
+				m.WalkPhase += dt * speed * 3.0
+				if m.WalkPhase > 2*math.Pi {
+					m.WalkPhase -= 2 * math.Pi
+				}
+			} else {
+				// Decay walk phase smoothly when stopping
+				m.WalkPhase *= 0.8
+			}
+		}
+
+		if m.Swinging {
+			// Loop the swing while swinging, for continuous breaking
+			m.SwingPhase += dt * 15.0
+			if m.SwingPhase > math.Pi {
+				m.SwingPhase -= math.Pi
+			}
+		} else if m.SwingPhase > 0 {
+			// Finish the swing, then rest
+			m.SwingPhase += dt * 10.0
+			if m.SwingPhase > math.Pi {
+				m.SwingPhase = 0
+			}
+		}
+	})
+}
+
+// UpdateItems ages dropped items and lets collecting entities pick up the
+// ones they walk over
+func UpdateItems(r *Registry, dt float32) {
+	r.Items.Each(func(id ID, it *Item) {
+		it.Age += dt
+		if it.Age > itemLifetime {
+			r.Destroy(id)
+			return
+		}
+		t := r.Transforms.Get(id)
+		if t == nil || it.Age < pickupDelay {
+			return
+		}
+
+		r.Inventories.Each(func(owner ID, inv *Inventory) {
+			if !inv.Collects || it.Count == 0 {
+				return
+			}
+			ot := r.Transforms.Get(owner)
+			if ot == nil || ot.Position.Sub(t.Position).Len() > pickupRadius {
+				return
+			}
+			if inv.Add(it.Type, it.Count) {
+				it.Count = 0
+				r.Destroy(id)
+			}
+		})
+	})
+}
+
+// UpdateProjectiles lets projectiles hurt the first body they hit, and
+// drops them where they hit something or land
+func UpdateProjectiles(r *Registry, dt float32) {
+	r.Projectiles.Each(func(id ID, p *Projectile) {
+		t, b := r.Transforms.Get(id), r.Bodies.Get(id)
+		if t == nil || b == nil {
+			return
+		}
+		p.Life -= dt
+
+		if hit, ok := r.hitBy(id, p, t.Position); ok {
+			if h := r.Healths.Get(hit); h != nil {
+				h.Damage(p.Damage)
+			}
+			// Creatures that flee run from what hurt them
+			if brain := r.Brains.Get(hit); brain != nil && brain.Has(BehaviorFlee) {
+				brain.State = StateFlee
+				brain.Timer = 0
+			}
+			r.land(id, p, t.Position)
+			return
+		}
+
+		if b.OnGround || b.HitWall || p.Life <= 0 {
+			r.land(id, p, t.Position)
+		}
+	})
+}
+
+// hitBy returns the entity with health a projectile is inside of
+func (r *Registry) hitBy(id ID, p *Projectile, pos mgl32.Vec3) (ID, bool) {
+	var hit ID
+	found := false
+	r.Healths.Each(func(other ID, h *Health) {
+		if found || other == id || other == p.Owner || h.Dead() {
+			return
+		}
+		t, b := r.Transforms.Get(other), r.Bodies.Get(other)
+		if t == nil || b == nil {
+			return
+		}
+		reach := b.Width/2 + hitMargin
+		d := pos.Sub(t.Position)
+		if d.X() < -reach || d.X() > reach || d.Z() < -reach || d.Z() > reach {
+			return
+		}
+		if d.Y() < -hitMargin || d.Y() > b.Height+hitMargin {
+			return
+		}
+		hit, found = other, true
+	})
+	return hit, found
+}
+
+// land removes a projectile, dropping what it was made of
+func (r *Registry) land(id ID, p *Projectile, pos mgl32.Vec3) {
+	if p.Drops != block.Air {
+		SpawnItem(r, p.Drops, 1, pos, mgl32.Vec3{})
+	}
+	r.Destroy(id)
+}
+
+// UpdateHealth removes creatures that died
+func UpdateHealth(r *Registry) {
+	r.Brains.Each(func(id ID, _ *Brain) {
+		if h := r.Healths.Get(id); h != nil && h.Dead() {
+			r.Destroy(id)
+		}
+	})
+}
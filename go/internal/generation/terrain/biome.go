@@ -0,0 +1,354 @@
+// Package terrain provides the data-driven biome registry
+package terrain
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"voxelgame/internal/core/block"
+)
+
+//go:embed biomes.json
+var defaultBiomesJSON []byte
+
+// Range is an inclusive interval of a climate value. Climate values lie in
+// [-1, 1]; a bound at or beyond either end leaves that side open.
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Layer is a band of blocks below the surface
+type Layer struct {
+	Block block.Type `json:"block"`
+	Depth int        `json:"depth"`
+}
+
+// Weighted is a named entry of a weighted random table
+type Weighted struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+// Vegetation holds per-column chances of plants growing on a biome
+type Vegetation struct {
+	TreeChance     float64    `json:"treeChance"` // Scaled by GeneratorConfig.TreeDensity
+	Trees          []Weighted `json:"trees"`      // Species from the generator's TreeTable
+	CactusChance   float64    `json:"cactusChance"`
+	GrassChance    float64    `json:"grassChance"`  // Grows on grass only
+	FlowerChance   float64    `json:"flowerChance"` // Grows on grass only
+	MushroomChance float64    `json:"mushroomChance"`
+	DeadBushChance float64    `json:"deadBushChance"`
+}
+
+// Biome describes where a biome occurs and what it looks like
+type Biome struct {
+	Name string `json:"name"`
+
+	// Climate the biome occurs in
+	Temperature Range `json:"temperature"`
+	Humidity    Range `json:"humidity"`
+	Elevation   Range `json:"elevation"`
+
+	// Terrain shape, blended with neighbouring biomes
+	HeightOffset float64 `json:"heightOffset"` // Added to the base height
+	HeightMod    float64 `json:"heightMod"`    // Scales the terrain amplitude
+	Ridged       float64 `json:"ridged"`       // Height of ridged mountain noise
+
+	// 3D shape used by density terrain, blended like the height
+	Overhang float64 `json:"overhang"` // Blocks 3D noise moves the surface by, making cliffs and overhangs
+	Islands  float64 `json:"islands"`  // Floating island coverage from 0 to 1
+
+	// Blocks
+	Layers     []Layer    `json:"layers"`     // From the surface down, stone below
+	Underwater block.Type `json:"underwater"` // Replaces the surface block below sea level, air keeps it
+	Water      block.Type `json:"water"`      // Fills the column up to sea level, air leaves it dry
+
+	// River marks the biome of river channels. It is never chosen by
+	// climate; its climate ranges are ignored.
+	River bool `json:"river"`
+
+	Vegetation Vegetation `json:"vegetation"`
+	Creatures  []Weighted `json:"creatures"` // Creature templates spawned in the biome
+
+	// SeasonCreatures replaces the spawn table in the seasons it names
+	SeasonCreatures map[string][]Weighted `json:"seasonCreatures,omitempty"`
+
+	Waterfalls bool `json:"waterfalls"`
+	Campfires  bool `json:"campfires"`
+
+	MapColor [3]uint8 `json:"mapColor"`
+}
+
+// Climate is the set of values biomes are chosen by
+type Climate struct {
+	Temperature float64
+	Humidity    float64
+	Elevation   float64
+}
+
+// BiomeWeight is the share of a biome in a blended column
+type BiomeWeight struct {
+	Biome  *Biome
+	Weight float64
+}
+
+// BiomeRegistry holds the biomes a generator chooses from
+type BiomeRegistry struct {
+	// BlendMargin is the climate distance over which neighbouring biomes
+	// fade into each other
+	BlendMargin float64
+
+	biomes []*Biome // Chosen by climate
+	river  *Biome
+	byName map[string]*Biome
+}
+
+// biomeFile is the JSON layout of a biome registry
+type biomeFile struct {
+	BlendMargin float64  `json:"blendMargin"`
+	Biomes      []*Biome `json:"biomes"`
+}
+
+// defaultBiomes is parsed once, registries are never modified
+var defaultBiomes = func() *BiomeRegistry {
+	r, err := LoadBiomes(bytes.NewReader(defaultBiomesJSON))
+	if err != nil {
+		panic(fmt.Sprintf("terrain: invalid built-in biomes: %v", err))
+	}
+	return r
+}()
+
+// DefaultBiomes returns the built-in biomes
+func DefaultBiomes() *BiomeRegistry {
+	return defaultBiomes
+}
+
+// LoadBiomes reads a biome registry from JSON
+func LoadBiomes(r io.Reader) (*BiomeRegistry, error) {
+	var file biomeFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, err
+	}
+	return NewBiomeRegistry(file.Biomes, file.BlendMargin)
+}
+
+// NewBiomeRegistry validates biomes and builds a registry from them
+func NewBiomeRegistry(biomes []*Biome, blendMargin float64) (*BiomeRegistry, error) {
+	if len(biomes) == 0 {
+		return nil, fmt.Errorf("no biomes defined")
+	}
+	if blendMargin < 0 {
+		return nil, fmt.Errorf("blend margin must not be negative")
+	}
+
+	r := &BiomeRegistry{
+		BlendMargin: blendMargin,
+		byName:      make(map[string]*Biome, len(biomes)),
+	}
+	for _, b := range biomes {
+		if b.Name == "" {
+			return nil, fmt.Errorf("biome without a name")
+		}
+		if _, dup := r.byName[b.Name]; dup {
+			return nil, fmt.Errorf("biome %q defined twice", b.Name)
+		}
+		for _, rg := range []Range{b.Temperature, b.Humidity, b.Elevation} {
+			if rg.Min > rg.Max {
+				return nil, fmt.Errorf("biome %q: range min %v above max %v", b.Name, rg.Min, rg.Max)
+			}
+		}
+		if len(b.Layers) == 0 {
+			return nil, fmt.Errorf("biome %q has no layers", b.Name)
+		}
+		if b.Overhang < 0 || b.Islands < 0 || b.Islands > 1 {
+			return nil, fmt.Errorf("biome %q: overhang must not be negative and islands must be within [0, 1]", b.Name)
+		}
+		for _, l := range b.Layers {
+			if l.Depth < 1 {
+				return nil, fmt.Errorf("biome %q: layer depth must be at least 1", b.Name)
+			}
+		}
+		r.byName[b.Name] = b
+		if b.River {
+			if r.river != nil {
+				return nil, fmt.Errorf("biomes %q and %q are both river biomes", r.river.Name, b.Name)
+			}
+			r.river = b
+			continue
+		}
+		r.biomes = append(r.biomes, b)
+	}
+	if len(r.biomes) == 0 {
+		return nil, fmt.Errorf("no biomes chosen by climate")
+	}
+	return r, nil
+}
+
+// Biomes returns the biomes chosen by climate in definition order
+func (r *BiomeRegistry) Biomes() []*Biome {
+	return r.biomes
+}
+
+// River returns the biome of river channels, nil if rivers keep the
+// biome around them
+func (r *BiomeRegistry) River() *Biome {
+	return r.river
+}
+
+// Get returns the biome with the given name, nil if there is none
+func (r *BiomeRegistry) Get(name string) *Biome {
+	return r.byName[name]
+}
+
+// Select returns the biome that best matches a climate
+func (r *BiomeRegistry) Select(c Climate) *Biome {
+	best := r.biomes[0]
+	bestScore := best.score(c)
+	for _, b := range r.biomes[1:] {
+		if s := b.score(c); s < bestScore {
+			best, bestScore = b, s
+		}
+	}
+	return best
+}
+
+// Blend returns the biomes contributing to a climate with weights summing
+// to one, the dominant biome first. Deep inside a biome's ranges it is the
+// only entry; towards a border its neighbours fade in.
+func (r *BiomeRegistry) Blend(c Climate) []BiomeWeight {
+	var weights []BiomeWeight
+	total := 0.0
+	for _, b := range r.biomes {
+		w := 0.0
+		s := b.score(c)
+		if r.BlendMargin > 0 {
+			w = smoothstep((r.BlendMargin - s) / (2 * r.BlendMargin))
+		} else if s <= 0 {
+			w = 1
+		}
+		if w > 0 {
+			weights = append(weights, BiomeWeight{Biome: b, Weight: w})
+			total += w
+		}
+	}
+
+	// Outside every biome's ranges, the nearest one takes over
+	if total == 0 {
+		return []BiomeWeight{{Biome: r.Select(c), Weight: 1}}
+	}
+
+	for i := range weights {
+		weights[i].Weight /= total
+	}
+	sort.SliceStable(weights, func(i, j int) bool {
+		return weights[i].Weight > weights[j].Weight
+	})
+	return weights
+}
+
+// score measures how well a climate fits the biome: the distance outside
+// its ranges, or the negated distance to the nearest border when inside
+func (b *Biome) score(c Climate) float64 {
+	outside := 0.0
+	inside := math.Inf(1)
+	values := [3]float64{c.Temperature, c.Humidity, c.Elevation}
+	ranges := [3]Range{b.Temperature, b.Humidity, b.Elevation}
+
+	for i, v := range values {
+		v = math.Max(-1, math.Min(1, v))
+		rg := ranges[i]
+		switch {
+		case v < rg.Min:
+			outside += (rg.Min - v) * (rg.Min - v)
+		case v > rg.Max:
+			outside += (v - rg.Max) * (v - rg.Max)
+		default:
+			// Open sides have no border to fade across
+			if rg.Min > -1 {
+				inside = math.Min(inside, v-rg.Min)
+			}
+			if rg.Max < 1 {
+				inside = math.Min(inside, rg.Max-v)
+			}
+		}
+	}
+
+	if outside > 0 {
+		return math.Sqrt(outside)
+	}
+	if math.IsInf(inside, 1) {
+		return -1
+	}
+	return -inside
+}
+
+// LayerAt returns the block at a depth below the surface (0 is the surface
+// block) and false once the depth is past all layers
+func (b *Biome) LayerAt(depth int) (block.Type, bool) {
+	for _, l := range b.Layers {
+		if depth < l.Depth {
+			return l.Block, true
+		}
+		depth -= l.Depth
+	}
+	return block.Air, false
+}
+
+// PickCreature chooses a creature template from the biome's spawn table
+// for a season, "" if the biome spawns none. roll is a random number in
+// [0, 1).
+func (b *Biome) PickCreature(season string, roll float64) string {
+	if table, ok := b.SeasonCreatures[season]; ok {
+		return pickWeighted(table, roll)
+	}
+	return pickWeighted(b.Creatures, roll)
+}
+
+// pickWeighted chooses an entry of a weighted table
+func pickWeighted(table []Weighted, roll float64) string {
+	total := 0.0
+	for _, e := range table {
+		total += e.Weight
+	}
+	if total <= 0 {
+		return ""
+	}
+	roll *= total
+	for _, e := range table {
+		if roll < e.Weight {
+			return e.Name
+		}
+		roll -= e.Weight
+	}
+	return table[len(table)-1].Name
+}
+
+// pickBiome chooses a biome from blend weights. roll is in [0, 1).
+func pickBiome(weights []BiomeWeight, roll float64) *Biome {
+	for _, w := range weights {
+		if roll < w.Weight {
+			return w.Biome
+		}
+		roll -= w.Weight
+	}
+	return weights[0].Biome
+}
+
+// smoothstep eases x from 0 to 1, clamping outside [0, 1]
+func smoothstep(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	return x * x * (3 - 2*x)
+}
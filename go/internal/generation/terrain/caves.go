@@ -0,0 +1,331 @@
+// Package terrain provides cave carvers
+package terrain
+
+import (
+	"math"
+
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/core/chunk"
+	vmath "voxelgame/pkg/math"
+)
+
+// CarverReach is how many chunks a cave may run past the chunk it starts
+// in. Caves are cheap to plan, so they reach further than structures.
+const CarverReach = 4
+
+// Cave shape
+const (
+	// LavaLevel is the height at and below which carved blocks fill with lava
+	LavaLevel = 8
+
+	// Caves stay below this height, only tunnels break into the surface
+	caveMaxY = 52
+
+	// Caverns deeper than this hold lava seas instead of lakes
+	lavaSeaDepth = 16
+)
+
+// caveSphere is one ellipsoid carved out of the terrain
+type caveSphere struct {
+	x, y, z float64
+	rh, rv  float64 // Horizontal and vertical radius
+
+	// Carved blocks at or below liquidLevel fill with liquid instead of
+	// air, for cavern lakes and lava seas
+	liquidLevel int
+	liquid      block.Type
+}
+
+// cavePlan holds the caves starting in one chunk, as the ellipsoids they
+// are carved from
+type cavePlan struct {
+	origin  chunkPos
+	spheres []caveSphere
+}
+
+// bounds returns the block range an ellipsoid may carve
+func (s *caveSphere) bounds() (minX, minY, minZ, maxX, maxY, maxZ int) {
+	return int(math.Floor(s.x - s.rh)), int(math.Floor(s.y - s.rv)), int(math.Floor(s.z - s.rh)),
+		int(math.Ceil(s.x + s.rh)), int(math.Ceil(s.y + s.rv)), int(math.Ceil(s.z + s.rh))
+}
+
+// add plans an ellipsoid, returning false once it would reach past
+// CarverReach, where no chunk would pick it up
+func (p *cavePlan) add(s caveSphere) bool {
+	minX, _, minZ, maxX, _, maxZ := s.bounds()
+	lo := chunkPos{vmath.FloorDiv(minX, chunk.Size), vmath.FloorDiv(minZ, chunk.Size)}
+	hi := chunkPos{vmath.FloorDiv(maxX, chunk.Size), vmath.FloorDiv(maxZ, chunk.Size)}
+	if lo.cx < p.origin.cx-CarverReach || lo.cz < p.origin.cz-CarverReach ||
+		hi.cx > p.origin.cx+CarverReach || hi.cz > p.origin.cz+CarverReach {
+		return false
+	}
+	p.spheres = append(p.spheres, s)
+	return true
+}
+
+// cavePlan returns the caves starting in a chunk
+func (g *Generator) cavePlan(cx, cz int) *cavePlan {
+	pos := chunkPos{cx, cz}
+	if p, ok := g.caves.get(pos); ok {
+		return p
+	}
+	p := g.planCaves(cx, cz)
+	g.caves.put(pos, p)
+	return p
+}
+
+// planCaves plans the tunnels, ravines and caverns starting in a chunk.
+// Plans depend only on the seed, settings and base terrain, never on other
+// chunks, so caves continue seamlessly into chunks generated in any order.
+func (g *Generator) planCaves(cx, cz int) *cavePlan {
+	p := &cavePlan{origin: chunkPos{cx, cz}}
+	chunkRng := g.random.Split(PassCaves).At(cx, cz)
+	frequency := float64(g.Config.CaveFrequency) / 0.6 * g.Pipeline.Config(PassCaves).Get("frequency", 1)
+	startX := float64(cx * chunk.Size)
+	startZ := float64(cz * chunk.Size)
+
+	// Tunnel systems, winding through several chunks and sometimes branching
+	if chunkRng.Next() < 0.2*frequency {
+		tunnels := chunkRng.NextInt(1, 2)
+		for i := 0; i < tunnels; i++ {
+			x := startX + chunkRng.NextFloat(0, chunk.Size)
+			y := LavaLevel + chunkRng.Next()*chunkRng.Next()*(caveMaxY-LavaLevel)
+			z := startZ + chunkRng.NextFloat(0, chunk.Size)
+			radius := chunkRng.NextFloat(1.5, 3)
+			length := chunkRng.NextInt(60, 140)
+			p.tunnel(x, y, z, chunkRng.NextFloat(0, 2*math.Pi), radius, length, true, chunkRng)
+		}
+	}
+
+	// Ravines, deep narrow cracks open to the sky
+	if chunkRng.Next() < 0.015*frequency {
+		x := startX + chunkRng.NextFloat(0, chunk.Size)
+		y := chunkRng.NextFloat(20, 34)
+		z := startZ + chunkRng.NextFloat(0, chunk.Size)
+		p.ravine(x, y, z, chunkRng)
+	}
+
+	// Caverns, large chambers with a lake or lava sea on the floor
+	if chunkRng.Next() < 0.02*frequency {
+		x := startX + chunkRng.NextFloat(0, chunk.Size)
+		y := chunkRng.NextFloat(LavaLevel+4, 24)
+		z := startZ + chunkRng.NextFloat(0, chunk.Size)
+		if g.roofed(x, y, z) {
+			p.cavern(x, y, z, chunkRng)
+		}
+	}
+
+	return p
+}
+
+// roofed reports whether the ground around a point is high enough to keep
+// a cavern there underground, so its lake or lava sea isn't open to the sky
+func (g *Generator) roofed(x, y, z float64) bool {
+	const spread, roof = 16, 10
+	for _, d := range [][2]float64{{0, 0}, {spread, 0}, {-spread, 0}, {0, spread}, {0, -spread}} {
+		col := g.getColumn(int(math.Floor(x+d[0])), int(math.Floor(z+d[1])))
+		if float64(col.ground) < y+roof {
+			return false
+		}
+	}
+	return true
+}
+
+// tunnel plans a worm tunnel: a chain of spheres following a heading that
+// drifts randomly, swelling in the middle and narrowing at both ends
+func (p *cavePlan) tunnel(x, y, z, yaw, radius float64, length int, branch bool, rng *vmath.SeededRNG) {
+	pitch := 0.0
+	var yawDrift, pitchDrift float64
+	branchAt := -1
+	if branch && rng.Next() < 0.5 {
+		branchAt = rng.NextInt(length/4, length*3/4)
+	}
+
+	for i := 0; i < length; i++ {
+		r := radius * (0.6 + 0.6*math.Sin(math.Pi*float64(i)/float64(length)))
+		if !p.add(caveSphere{x: x, y: y, z: z, rh: r, rv: r * 0.8, liquidLevel: -1}) {
+			return
+		}
+
+		if i == branchAt {
+			// Fork off both sides, the branches replace the rest of the tunnel
+			remaining := length - i
+			p.tunnel(x, y, z, yaw+math.Pi/2, radius*0.8, remaining, false, rng)
+			p.tunnel(x, y, z, yaw-math.Pi/2, radius*0.8, remaining, false, rng)
+			return
+		}
+
+		x += math.Cos(yaw) * math.Cos(pitch)
+		z += math.Sin(yaw) * math.Cos(pitch)
+		y += math.Sin(pitch)
+
+		// Flatten out over time so tunnels don't dive straight down
+		pitch *= 0.7
+		pitch += pitchDrift * 0.1
+		yaw += yawDrift * 0.1
+		pitchDrift = pitchDrift*0.9 + (rng.Next()-rng.Next())*rng.Next()*2
+		yawDrift = yawDrift*0.75 + (rng.Next()-rng.Next())*rng.Next()*4
+
+		if y < LavaLevel-2 {
+			pitch = math.Abs(pitch)
+		} else if y > caveMaxY {
+			pitch = -math.Abs(pitch)
+		}
+	}
+}
+
+// ravine plans a long crack, narrow but many blocks tall, that reaches the
+// surface from deep underground
+func (p *cavePlan) ravine(x, y, z float64, rng *vmath.SeededRNG) {
+	yaw := rng.NextFloat(0, 2*math.Pi)
+	width := rng.NextFloat(2, 3.5)
+	depth := rng.NextFloat(14, 20)
+	length := rng.NextInt(50, 90)
+	var yawDrift float64
+
+	for i := 0; i < length; i++ {
+		profile := math.Sin(math.Pi * float64(i) / float64(length))
+		r := 1 + width*profile
+		if !p.add(caveSphere{x: x, y: y, z: z, rh: r, rv: 2 + depth*profile, liquidLevel: -1}) {
+			return
+		}
+
+		x += math.Cos(yaw)
+		z += math.Sin(yaw)
+		y += (rng.Next() - 0.5) * 0.3
+		yaw += yawDrift * 0.05
+		yawDrift = yawDrift*0.8 + (rng.Next()-rng.Next())*rng.Next()*3
+	}
+}
+
+// cavern plans a large chamber from a few overlapping ellipsoids. Its
+// lower part holds a lake, or a lava sea in deep caverns.
+func (p *cavePlan) cavern(x, y, z float64, rng *vmath.SeededRNG) {
+	liquid := block.Water
+	if y < lavaSeaDepth {
+		liquid = block.Lava
+	}
+	level := int(y) - rng.NextInt(1, 3)
+
+	blobs := rng.NextInt(2, 4)
+	for i := 0; i < blobs; i++ {
+		p.add(caveSphere{
+			x:           x + rng.NextFloat(-6, 6),
+			y:           y + rng.NextFloat(-1, 2),
+			z:           z + rng.NextFloat(-6, 6),
+			rh:          rng.NextFloat(7, 12),
+			rv:          rng.NextFloat(4, 7),
+			liquidLevel: level,
+			liquid:      liquid,
+		})
+	}
+
+	// Tunnels leading out of the cavern
+	exits := rng.NextInt(1, 3)
+	for i := 0; i < exits; i++ {
+		p.tunnel(x, y, z, rng.NextFloat(0, 2*math.Pi), rng.NextFloat(1.5, 2.5), rng.NextInt(40, 80), false, rng)
+	}
+}
+
+// applyCaves carves all caves reaching into a chunk. Plans are visited in
+// a fixed order, so where caves overlap the result doesn't depend on which
+// chunks generated first.
+func (g *Generator) applyCaves(c *chunk.Chunk) {
+	cx, cz := int(c.CX), int(c.CZ)
+	startX := cx * chunk.Size
+	startZ := cz * chunk.Size
+	endX := startX + chunk.Size - 1
+	endZ := startZ + chunk.Size - 1
+
+	var aquifers [chunk.Size * chunk.Size]int
+	for i := range aquifers {
+		aquifers[i] = -1 // Computed on first use
+	}
+	var carved [chunk.Size * chunk.Size]bool
+	var carvedBlocks [chunk.Size * chunk.Size * chunk.Height]bool
+
+	for oz := cz - CarverReach; oz <= cz+CarverReach; oz++ {
+		for ox := cx - CarverReach; ox <= cx+CarverReach; ox++ {
+			plan := g.cavePlan(ox, oz)
+			for i := range plan.spheres {
+				s := &plan.spheres[i]
+				minX, minY, minZ, maxX, maxY, maxZ := s.bounds()
+				if maxX < startX || minX > endX || maxZ < startZ || minZ > endZ {
+					continue
+				}
+
+				for wx := max(minX, startX); wx <= min(maxX, endX); wx++ {
+					for wz := max(minZ, startZ); wz <= min(maxZ, endZ); wz++ {
+						lx, lz := wx-startX, wz-startZ
+						for y := max(minY, 1); y <= min(maxY, chunk.Height-1); y++ {
+							dx := (float64(wx) + 0.5 - s.x) / s.rh
+							dy := (float64(y) + 0.5 - s.y) / s.rv
+							dz := (float64(wz) + 0.5 - s.z) / s.rh
+							if dx*dx+dy*dy+dz*dz >= 1 {
+								continue
+							}
+
+							// Carve solid ground only, but let cavern lakes
+							// fill tunnels that crossed them first
+							idx := lx + lz*chunk.Size
+							blockIdx := idx*chunk.Height + y
+							current := c.GetBlock(lx, y, lz)
+							if current == block.Bedrock || current.IsLiquid() {
+								continue
+							}
+							if current == block.Air && (!carvedBlocks[blockIdx] || y > s.liquidLevel) {
+								continue
+							}
+
+							if aquifers[idx] < 0 {
+								aquifers[idx] = g.aquiferLevel(wx, wz)
+							}
+							c.SetBlock(lx, y, lz, caveFill(s, y, aquifers[idx]))
+							carved[idx] = true
+							carvedBlocks[blockIdx] = true
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Caves that break into the surface lower it
+	for idx, ok := range carved {
+		if ok {
+			c.UpdateHeight(idx%chunk.Size, idx/chunk.Size)
+		}
+	}
+}
+
+// caveFill returns what a carved block becomes: lava at the bottom of the
+// world, the liquid of a cavern, groundwater below the aquifer, else air
+func caveFill(s *caveSphere, y, aquifer int) block.Type {
+	switch {
+	case y <= LavaLevel:
+		return block.Lava
+	case y <= s.liquidLevel:
+		return s.liquid
+	case y < aquifer:
+		return block.Water
+	}
+	return block.Air
+}
+
+// aquiferLevel returns the height groundwater rises to in caves below a
+// column. Near oceans and rivers it matches sea level, so caves under the
+// water flood instead of leaving air pockets. Inland it is usually too deep
+// to matter, but some regions hold flooded cave systems.
+func (g *Generator) aquiferLevel(wx, wz int) int {
+	seaLevel := g.Config.SeaLevel
+	continentalness := g.Continentalness(wx, wz)
+	if continentalness < OceanThreshold+0.05 || g.riverAt(wx, wz, continentalness).valley > 0 {
+		return seaLevel
+	}
+
+	n := g.caveNoise.Noise2D(float64(wx)*0.004, float64(wz)*0.004)
+	if n < 0.3 {
+		return 0
+	}
+	return min(seaLevel-2, LavaLevel+1+int((n-0.3)*40))
+}
@@ -0,0 +1,202 @@
+// Package terrain provides 3D density terrain
+package terrain
+
+import (
+	"math"
+
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/core/chunk"
+	"voxelgame/internal/core/noise"
+)
+
+// Density terrain shape
+const (
+	// Floating islands are thickest at islandLevel and taper over
+	// islandBelow blocks downwards and islandAbove blocks upwards
+	islandLevel = 44
+	islandBelow = 7
+	islandAbove = 3
+
+	// Islands keep this many blocks of air above the ground below them
+	islandClearance = 3
+
+	// Highest block density terrain fills, leaving room for trees
+	densityMaxY = chunk.Height - 8
+
+	// 3D noise varies faster vertically, stacking ledges into cliffs
+	overhangStretch = 2.0
+)
+
+// DensityGenerator generates terrain from a 3D density function instead of
+// a heightmap. The heightmap height of a column becomes a bias: blocks below
+// it tend to be solid and blocks above it air, and 3D noise scaled by the
+// biome's overhang moves the boundary, cutting cliffs, arches and overhangs.
+// Biomes with islands also grow floating islands high above the ground.
+//
+// Every pass after the base terrain (structures, decorations, dungeons) is
+// shared with Generator, which it builds on for biome and config queries.
+type DensityGenerator struct {
+	*Generator
+
+	overhangNoise *noise.SimplexNoise
+	islandNoise   *noise.SimplexNoise
+	overhangFBM   *noise.FBM
+	islandFBM     *noise.FBM
+}
+
+// densityColumn is a heightmap column with its density surface
+type densityColumn struct {
+	column
+	footprint float64 // Islands grow where it is high
+}
+
+// NewDensityGenerator creates a new density terrain generator with the given seed
+func NewDensityGenerator(seed int64) *DensityGenerator {
+	d := &DensityGenerator{
+		Generator:     NewGenerator(seed),
+		overhangNoise: noise.NewSimplexNoise(seed + 8000),
+		islandNoise:   noise.NewSimplexNoise(seed + 9000),
+	}
+
+	d.overhangFBM = noise.NewFBM(noise.FBMConfig{
+		Octaves:     3,
+		Lacunarity:  2.0,
+		Persistence: 0.5,
+		Scale:       0.03,
+	})
+
+	d.islandFBM = noise.NewFBM(noise.FBMConfig{
+		Octaves:     3,
+		Lacunarity:  2.0,
+		Persistence: 0.5,
+		Scale:       0.02,
+	})
+
+	// Structures are planned on the density surface, and the pipeline
+	// starts from it
+	d.Generator.density = d
+	d.Pipeline = d.defaultPipeline(d.generateBase)
+	return d
+}
+
+// generateBase generates the base terrain from the density function
+func (d *DensityGenerator) generateBase(c *chunk.Chunk) {
+	startX := int(c.CX) * chunk.Size
+	startZ := int(c.CZ) * chunk.Size
+	for lx := 0; lx < chunk.Size; lx++ {
+		for lz := 0; lz < chunk.Size; lz++ {
+			d.generateColumn(c, lx, lz, startX+lx, startZ+lz)
+		}
+	}
+}
+
+// densityColumn finds the surface of the ground in a heightmap column, so
+// islands can keep clear of it
+func (d *DensityGenerator) densityColumn(wx, wz int, base column) densityColumn {
+	col := densityColumn{column: base, footprint: math.Inf(-1)}
+	col.ground = 0
+	for y := min(base.height+int(math.Ceil(base.overhang)), densityMaxY); y > 0; y-- {
+		if d.ground(col, wx, y, wz) {
+			col.ground = y
+			break
+		}
+	}
+	if col.islands > 0 {
+		col.footprint = d.islandFBM.Sample2D(d.islandNoise, float64(wx), float64(wz))
+	}
+	return col
+}
+
+// generateColumn fills a vertical column of blocks from the top down. Biome
+// layers start below every stretch of air, so overhangs and islands get a
+// surface of their own. The height map follows from the blocks placed.
+func (d *DensityGenerator) generateColumn(c *chunk.Chunk, lx, lz, wx, wz int) {
+	col := d.densityColumn(wx, wz, d.heightColumn(wx, wz))
+	c.SetBlock(lx, 0, lz, block.Bedrock)
+
+	depth := -1 // Blocks below the last air, -1 in the open
+	for y := chunk.Height - 1; y > 0; y-- {
+		if !d.solid(col, wx, y, wz) {
+			depth = -1
+			if y < d.Config.SeaLevel {
+				c.SetBlock(lx, y, lz, col.biome.Water)
+			}
+			continue
+		}
+
+		depth++
+		blockType, ok := col.biome.LayerAt(depth)
+		switch {
+		case !ok:
+			blockType = block.Stone
+		case depth == 0:
+			blockType = d.getSurfaceBlock(y, blockType, col.biome)
+		}
+		if blockType != block.Air {
+			c.SetBlock(lx, y, lz, blockType)
+		}
+	}
+}
+
+// surface returns the highest solid block of a column and the highest below
+// any floating island
+func (d *DensityGenerator) surface(wx, wz int, base column) (height, ground int) {
+	col := d.densityColumn(wx, wz, base)
+
+	for y := min(islandLevel+islandAbove, densityMaxY); y > col.ground; y-- {
+		if d.island(col, wx, y, wz) {
+			return y, col.ground
+		}
+	}
+	return col.ground, col.ground
+}
+
+// solid reports whether the density function fills a block
+func (d *DensityGenerator) solid(col densityColumn, wx, y, wz int) bool {
+	return d.ground(col, wx, y, wz) || d.island(col, wx, y, wz)
+}
+
+// ground reports whether the ground fills a block. Density falls with the
+// height above the heightmap surface; noise only decides within overhang
+// blocks of it.
+func (d *DensityGenerator) ground(col densityColumn, wx, y, wz int) bool {
+	if y > densityMaxY {
+		return false
+	}
+	density := float64(col.height-y) + 0.5
+	if math.Abs(density) > col.overhang {
+		return density > 0
+	}
+	n := d.overhangFBM.Sample3D(d.overhangNoise, float64(wx), float64(y)*overhangStretch, float64(wz))
+	return density+n*col.overhang > 0
+}
+
+// island reports whether a floating island fills a block. Islands are
+// lens shaped: the further their footprint noise is above the threshold,
+// the thicker they are.
+func (d *DensityGenerator) island(col densityColumn, wx, y, wz int) bool {
+	if col.islands <= 0 || y > densityMaxY {
+		return false
+	}
+	if y <= col.ground+islandClearance {
+		return false
+	}
+
+	var taper float64
+	if y >= islandLevel {
+		taper = 1 - float64(y-islandLevel)/islandAbove
+	} else {
+		taper = 1 - float64(islandLevel-y)/islandBelow
+	}
+	if taper <= 0 {
+		return false
+	}
+
+	threshold := 0.5 - 0.4*col.islands + (1-taper)*0.15
+	if col.footprint+0.1 < threshold {
+		return false
+	}
+	// Rough undersides
+	n := d.overhangFBM.Sample3D(d.overhangNoise, float64(wx)+5000, float64(y)*overhangStretch, float64(wz)+5000)
+	return col.footprint+n*0.1 > threshold
+}
@@ -0,0 +1,532 @@
+// Package terrain provides multi-room dungeons
+package terrain
+
+import (
+	"math"
+
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/core/chunk"
+	vmath "voxelgame/pkg/math"
+)
+
+// Dungeon placement
+const (
+	// DungeonSpacing is the size in chunks of the regions dungeons are
+	// spread over. Each region holds at most one dungeon.
+	DungeonSpacing = 6
+
+	// DungeonReach is how many chunks a dungeon may extend past the chunk
+	// it is centered in
+	DungeonReach = 4
+
+	dungeonChance  = 0.6 // Default chance of a region holding a dungeon
+	dungeonRadius  = 20  // Rooms are laid out within this distance of the center
+	dungeonMinLeaf = 10  // Smallest area a room is laid out in
+	dungeonSplits  = 4   // Most times the area is split in two
+	dungeonCover   = 2   // Blocks of ground kept over rooms
+
+	// Longest staircase from the entrance room up to the surface
+	dungeonMaxStairs = 40
+)
+
+// dungeonCreatures are the creatures spawners spawn
+var dungeonCreatures = []Weighted{{"spider", 2}, {"biped", 1}, {"slime", 1}}
+
+// Spawner is a generated creature spawner
+type Spawner struct {
+	X, Y, Z  int
+	Creature string // Creature template it spawns
+}
+
+// dungeonPlan holds the dungeon of one region, if it has one
+type dungeonPlan struct {
+	exists  bool
+	start   chunkPos // Chunk the entrance room is centered in
+	x, y, z int      // Center of the entrance room, on its floor
+
+	minX, minZ, maxX, maxZ int // Footprint, inclusive
+	writes                 blockWrites
+	chests                 map[[3]int]string // Loot table of each chest
+	spawners               []Spawner
+}
+
+// dungeonRoom is a room of a dungeon
+type dungeonRoom struct {
+	x, z, w, d int // Open footprint
+	y, h       int // Floor level (lowest open block) and open height
+}
+
+// center returns the middle of a room's footprint
+func (r *dungeonRoom) center() (int, int) {
+	return r.x + r.w/2, r.z + r.d/2
+}
+
+// contains reports whether a column lies inside a room
+func (r *dungeonRoom) contains(x, z int) bool {
+	return x >= r.x && x < r.x+r.w && z >= r.z && z < r.z+r.d
+}
+
+// bspNode is an area of a dungeon, either split in two or holding a room
+type bspNode struct {
+	x, z, w, d  int
+	left, right *bspNode
+	room        *dungeonRoom
+}
+
+// rooms returns the rooms within an area
+func (n *bspNode) rooms() []*dungeonRoom {
+	if n.left == nil {
+		if n.room == nil {
+			return nil
+		}
+		return []*dungeonRoom{n.room}
+	}
+	return append(n.left.rooms(), n.right.rooms()...)
+}
+
+// dungeonPlan returns the dungeon of a region
+func (g *Generator) dungeonPlan(rx, rz int) *dungeonPlan {
+	pos := chunkPos{rx, rz}
+	if p, ok := g.dungeons.get(pos); ok {
+		return p
+	}
+	p := g.planDungeon(rx, rz)
+	g.dungeons.put(pos, p)
+	return p
+}
+
+// dungeonsNear returns the dungeons reaching into a chunk, in a fixed order
+func (g *Generator) dungeonsNear(cx, cz int) []*dungeonPlan {
+	minX, minZ := cx*chunk.Size, cz*chunk.Size
+	maxX, maxZ := minX+chunk.Size-1, minZ+chunk.Size-1
+
+	var plans []*dungeonPlan
+	forRegions(cx, cz, DungeonSpacing, DungeonReach, func(rx, rz int) {
+		p := g.dungeonPlan(rx, rz)
+		if p.exists && p.minX <= maxX && p.maxX >= minX && p.minZ <= maxZ && p.maxZ >= minZ {
+			plans = append(plans, p)
+		}
+	})
+	return plans
+}
+
+// applyDungeons writes the dungeons reaching into a chunk
+func (g *Generator) applyDungeons(c *chunk.Chunk) {
+	var touched [chunk.Size * chunk.Size]bool
+	for _, p := range g.dungeonsNear(int(c.CX), int(c.CZ)) {
+		p.writes.apply(c, &touched)
+	}
+	// Entrances open up the surface
+	updateHeights(c, &touched)
+}
+
+// Spawners returns the spawners generated in a chunk
+func (g *Generator) Spawners(cx, cz int) []Spawner {
+	var spawners []Spawner
+	for _, p := range g.dungeonsNear(cx, cz) {
+		for _, s := range p.spawners {
+			if vmath.FloorDiv(s.X, chunk.Size) == cx && vmath.FloorDiv(s.Z, chunk.Size) == cz {
+				spawners = append(spawners, s)
+			}
+		}
+	}
+	return spawners
+}
+
+// ChestLoot returns the contents of the chest generated at world
+// coordinates, or nil if no chest was generated there. The loot is rolled
+// from the seed and the chest's position, so it is the same every time.
+func (g *Generator) ChestLoot(wx, wy, wz int) []ItemStack {
+	for _, p := range g.dungeonsNear(vmath.FloorDiv(wx, chunk.Size), vmath.FloorDiv(wz, chunk.Size)) {
+		if name, ok := p.chests[[3]int{wx, wy, wz}]; ok {
+			if table := g.Loot.Get(name); table != nil {
+				return table.Roll(g.random.Split(PassDungeons).Split("loot").At3(wx, wy, wz))
+			}
+		}
+	}
+	return nil
+}
+
+// planDungeon lays out the dungeon of a region, if it has one: the area
+// around its center is split into a BSP tree with a room in every leaf,
+// the halves of every split are joined by a corridor, and a staircase
+// leads from the entrance room up to the surface.
+func (g *Generator) planDungeon(rx, rz int) *dungeonPlan {
+	p := &dungeonPlan{}
+	rng := g.random.Split(PassDungeons).At(rx, rz)
+	if rng.Next() > g.Pipeline.Config(PassDungeons).Get("chance", dungeonChance) {
+		return p
+	}
+
+	cx := rx*DungeonSpacing + rng.NextInt(0, DungeonSpacing-1)
+	cz := rz*DungeonSpacing + rng.NextInt(0, DungeonSpacing-1)
+	wx := cx*chunk.Size + rng.NextInt(0, chunk.Size-1)
+	wz := cz*chunk.Size + rng.NextInt(0, chunk.Size-1)
+
+	l := &dungeonLayout{
+		g:       g,
+		rng:     rng,
+		origin:  chunkPos{cx, cz},
+		cells:   make(map[[3]int]block.Type),
+		columns: make(map[[2]int]int),
+	}
+
+	// Sink dungeons under low ground rather than leave them out
+	baseY := min(rng.NextInt(12, 22), l.ground(wx, wz)-dungeonCover-6)
+	if baseY < 6 {
+		return p
+	}
+	root := &bspNode{x: wx - dungeonRadius, z: wz - dungeonRadius, w: 2 * dungeonRadius, d: 2 * dungeonRadius}
+	l.split(root, 0)
+	l.build(root, baseY)
+	if len(l.rooms) < 2 {
+		return p
+	}
+
+	// The entrance is in the room nearest the center that can reach the
+	// surface, or the nearest room if none can
+	byDistance := append([]*dungeonRoom(nil), l.rooms...)
+	distance := func(r *dungeonRoom, x, z int) float64 {
+		rx, rz := r.center()
+		return math.Hypot(float64(rx-x), float64(rz-z))
+	}
+	for i := 1; i < len(byDistance); i++ {
+		for j := i; j > 0 && distance(byDistance[j], wx, wz) < distance(byDistance[j-1], wx, wz); j-- {
+			byDistance[j], byDistance[j-1] = byDistance[j-1], byDistance[j]
+		}
+	}
+	entrance := byDistance[0]
+	for _, r := range byDistance {
+		if l.entrance(r) {
+			entrance = r
+			break
+		}
+	}
+
+	ex, ez := entrance.center()
+	p.exists = true
+	p.start = chunkPos{vmath.FloorDiv(ex, chunk.Size), vmath.FloorDiv(ez, chunk.Size)}
+	p.x, p.y, p.z = ex, entrance.y, ez
+	p.chests = make(map[[3]int]string)
+
+	// Treasure waits in the room furthest from the entrance
+	furthest := entrance
+	for _, r := range l.rooms {
+		if distance(r, ex, ez) > distance(furthest, ex, ez) {
+			furthest = r
+		}
+	}
+
+	for _, r := range l.rooms {
+		chests := 0
+		if rng.Next() < 0.6 {
+			chests++
+		}
+		if rng.Next() < 0.2 {
+			chests++
+		}
+		table := LootDungeon
+		if r == furthest {
+			table = LootDungeonTreasure
+			chests = max(chests, 1)
+		}
+		for i := 0; i < chests; i++ {
+			if pos, ok := l.chestSpot(r); ok {
+				l.cells[pos] = block.Chest
+				p.chests[pos] = table
+			}
+		}
+
+		if r != entrance && rng.Next() < 0.5 {
+			x, z := r.center()
+			l.cells[[3]int{x, r.y, z}] = block.Spawner
+			p.spawners = append(p.spawners, Spawner{x, r.y, z, pickWeighted(dungeonCreatures, rng.Next())})
+		}
+	}
+
+	p.writes = make(blockWrites)
+	p.minX, p.minZ, p.maxX, p.maxZ = math.MaxInt, math.MaxInt, math.MinInt, math.MinInt
+	for _, pos := range l.order {
+		t := l.cells[pos]
+		if t == block.StoneBrick && rng.Next() < 0.2 {
+			t = block.MossyStoneBrick
+		}
+		p.writes.add(structureBlock{pos[0], pos[1], pos[2], t, false, nil})
+		p.minX, p.maxX = min(p.minX, pos[0]), max(p.maxX, pos[0])
+		p.minZ, p.maxZ = min(p.minZ, pos[2]), max(p.maxZ, pos[2])
+	}
+	return p
+}
+
+// dungeonLayout collects the blocks of a dungeon while it is planned.
+// Open cells win over walls, so rooms and corridors cut doorways into each
+// other whatever order they are laid out in.
+type dungeonLayout struct {
+	g      *Generator
+	rng    *vmath.SeededRNG
+	origin chunkPos // Chunk the dungeon is centered in
+	rooms  []*dungeonRoom
+
+	cells map[[3]int]block.Type
+	order [][3]int // Cells in the order they were first set
+
+	// Ground heights looked at so far
+	columns map[[2]int]int
+}
+
+// ground returns the base terrain height of a column
+func (l *dungeonLayout) ground(x, z int) int {
+	key := [2]int{x, z}
+	h, ok := l.columns[key]
+	if !ok {
+		h = l.g.getColumn(x, z).ground
+		l.columns[key] = h
+	}
+	return h
+}
+
+// set records a cell
+func (l *dungeonLayout) set(x, y, z int, t block.Type) {
+	pos := [3]int{x, y, z}
+	if _, ok := l.cells[pos]; !ok {
+		l.order = append(l.order, pos)
+	}
+	l.cells[pos] = t
+}
+
+// open clears a cell
+func (l *dungeonLayout) open(x, y, z int) {
+	l.set(x, y, z, block.Air)
+}
+
+// wall builds a wall cell unless the cell is open or above the ground
+func (l *dungeonLayout) wall(x, y, z int) {
+	if _, ok := l.cells[[3]int{x, y, z}]; ok || y > l.ground(x, z) {
+		return
+	}
+	l.set(x, y, z, block.StoneBrick)
+}
+
+// split divides an area in two along its longer side, again and again
+// until the parts get too small
+func (l *dungeonLayout) split(n *bspNode, depth int) {
+	if depth >= dungeonSplits {
+		return
+	}
+	alongX := n.w > n.d || (n.w == n.d && l.rng.Next() < 0.5)
+	size := n.d
+	if alongX {
+		size = n.w
+	}
+	if size < 2*dungeonMinLeaf {
+		return
+	}
+
+	cut := l.rng.NextInt(dungeonMinLeaf, size-dungeonMinLeaf)
+	if alongX {
+		n.left = &bspNode{x: n.x, z: n.z, w: cut, d: n.d}
+		n.right = &bspNode{x: n.x + cut, z: n.z, w: n.w - cut, d: n.d}
+	} else {
+		n.left = &bspNode{x: n.x, z: n.z, w: n.w, d: cut}
+		n.right = &bspNode{x: n.x, z: n.z + cut, w: n.w, d: n.d - cut}
+	}
+	l.split(n.left, depth+1)
+	l.split(n.right, depth+1)
+}
+
+// build lays out a room in every leaf and joins the two halves of every
+// split with a corridor between their closest rooms
+func (l *dungeonLayout) build(n *bspNode, baseY int) {
+	if n.left == nil {
+		n.room = l.room(n, baseY)
+		if n.room != nil {
+			l.rooms = append(l.rooms, n.room)
+		}
+		return
+	}
+	l.build(n.left, baseY)
+	l.build(n.right, baseY)
+
+	var a, b *dungeonRoom
+	best := math.MaxFloat64
+	for _, ra := range n.left.rooms() {
+		for _, rb := range n.right.rooms() {
+			ax, az := ra.center()
+			bx, bz := rb.center()
+			if d := math.Hypot(float64(ax-bx), float64(az-bz)); d < best {
+				a, b, best = ra, rb, d
+			}
+		}
+	}
+	if a != nil {
+		l.corridor(a, b)
+	}
+}
+
+// room lays out a room within a leaf, on one of three levels. Rooms that
+// wouldn't stay covered by ground are left out.
+func (l *dungeonLayout) room(n *bspNode, baseY int) *dungeonRoom {
+	r := &dungeonRoom{
+		w: l.rng.NextInt(4, min(9, n.w-2)),
+		d: l.rng.NextInt(4, min(9, n.d-2)),
+		y: baseY + l.rng.NextInt(-1, 1)*2,
+		h: l.rng.NextInt(3, 4),
+	}
+	r.x = n.x + 1 + l.rng.NextInt(0, n.w-r.w-2)
+	r.z = n.z + 1 + l.rng.NextInt(0, n.d-r.d-2)
+
+	cx, cz := r.center()
+	for _, c := range [][2]int{{r.x, r.z}, {r.x + r.w - 1, r.z}, {r.x, r.z + r.d - 1}, {r.x + r.w - 1, r.z + r.d - 1}, {cx, cz}} {
+		if l.ground(c[0], c[1]) < r.y+r.h+dungeonCover {
+			return nil
+		}
+	}
+
+	for x := r.x - 1; x <= r.x+r.w; x++ {
+		for z := r.z - 1; z <= r.z+r.d; z++ {
+			for y := r.y - 1; y <= r.y+r.h; y++ {
+				if r.contains(x, z) && y >= r.y && y < r.y+r.h {
+					l.open(x, y, z)
+				} else {
+					l.wall(x, y, z)
+				}
+			}
+		}
+	}
+	return r
+}
+
+// corridor joins two rooms with an L-shaped passage. Stairs climb or
+// descend one block per step on the way to the other room's floor.
+func (l *dungeonLayout) corridor(a, b *dungeonRoom) {
+	ax, az := a.center()
+	bx, bz := b.center()
+
+	// Walk along one axis, then the other, up to the second room
+	var path [][2]int
+	x, z := ax, az
+	xFirst := l.rng.Next() < 0.5
+	for leg := 0; leg < 2; leg++ {
+		if (leg == 0) == xFirst {
+			for x != bx {
+				x += sign(bx - x)
+				path = append(path, [2]int{x, z})
+			}
+		} else {
+			for z != bz {
+				z += sign(bz - z)
+				path = append(path, [2]int{x, z})
+			}
+		}
+	}
+	for i, c := range path {
+		if b.contains(c[0], c[1]) {
+			path = path[:i]
+			break
+		}
+	}
+
+	// Take the steps just before the second room, so the stairs leave the
+	// first room's floor alone when there is space
+	steps := abs(b.y - a.y)
+	y := a.y
+	for i, c := range path {
+		if i >= len(path)-steps {
+			y += sign(b.y - a.y)
+		}
+		if y != a.y || !a.contains(c[0], c[1]) {
+			l.passage(c[0], y, c[1])
+		}
+	}
+}
+
+// entrance digs stairs from a room up to the surface, trying each
+// direction in turn. It returns false if no stairs fit: they must come out
+// on dry land within dungeonMaxStairs steps and DungeonReach chunks.
+func (l *dungeonLayout) entrance(r *dungeonRoom) bool {
+	turn := l.rng.NextInt(0, 3)
+	for i := 0; i < 4; i++ {
+		step := facingSteps[(turn+i)%4]
+		x, z := r.center()
+		y := r.y
+
+		var stairs [][3]int
+		for len(stairs) < dungeonMaxStairs {
+			x, z = x+step[0], z+step[1]
+			if r.contains(x, z) {
+				continue
+			}
+			if abs(vmath.FloorDiv(x, chunk.Size)-l.origin.cx) > DungeonReach || abs(vmath.FloorDiv(z, chunk.Size)-l.origin.cz) > DungeonReach {
+				break
+			}
+			ground := l.ground(x, z)
+			if ground <= l.g.Config.SeaLevel {
+				break
+			}
+			y++
+			stairs = append(stairs, [3]int{x, y, z})
+			if y > ground {
+				// Out in the open
+				for _, s := range stairs {
+					l.passage(s[0], s[1], s[2])
+				}
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// passage clears a cell of a corridor three blocks high, with walls,
+// floor and ceiling around it
+func (l *dungeonLayout) passage(x, y, z int) {
+	for dx := -1; dx <= 1; dx++ {
+		for dz := -1; dz <= 1; dz++ {
+			for dy := -1; dy <= 3; dy++ {
+				if dx == 0 && dz == 0 && dy >= 0 && dy <= 2 {
+					l.open(x, y+dy, z)
+				} else {
+					l.wall(x+dx, y+dy, z+dz)
+				}
+			}
+		}
+	}
+}
+
+// chestSpot finds a place on a room's floor against a wall, away from
+// doorways, for a chest
+func (l *dungeonLayout) chestSpot(r *dungeonRoom) ([3]int, bool) {
+	for attempt := 0; attempt < 8; attempt++ {
+		side := facingSteps[l.rng.NextInt(0, 3)]
+		x := r.x + l.rng.NextInt(0, r.w-1)
+		z := r.z + l.rng.NextInt(0, r.d-1)
+		switch {
+		case side[0] < 0:
+			x = r.x
+		case side[0] > 0:
+			x = r.x + r.w - 1
+		case side[1] < 0:
+			z = r.z
+		default:
+			z = r.z + r.d - 1
+		}
+
+		pos := [3]int{x, r.y, z}
+		behind := [3]int{x + side[0], r.y, z + side[1]}
+		if l.cells[pos] == block.Air && l.cells[behind] != block.Air {
+			return pos, true
+		}
+	}
+	return [3]int{}, false
+}
+
+// sign returns -1, 0 or 1 by the sign of n
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -2,6 +2,9 @@
 package terrain
 
 import (
+	"fmt"
+	"math"
+
 	"voxelgame/internal/core/block"
 	"voxelgame/internal/core/chunk"
 	"voxelgame/internal/core/noise"
@@ -15,106 +18,104 @@ const (
 	TerrainAmplitude  = 30
 )
 
-// Biome represents a terrain biome with its properties
-type Biome struct {
-	Name       string
-	Surface    block.Type
-	Subsurface block.Type
-	HeightMod  float64
-	HasWater   bool
-	WaterType  block.Type
-	HasTrees   bool
-	TreeChance float64
-	TreeType   string
-	HasFlowers bool
-	HasCactus  bool
-}
+// Generator generates procedural terrain
+type Generator struct {
+	// random seeds the random streams of chunks and regions, split by
+	// pass (see the Pass constants)
+	random vmath.PositionalRNG
 
-// Predefined biomes
-var (
-	BiomePlains = Biome{
-		Name:       "plains",
-		Surface:    block.Grass,
-		Subsurface: block.Dirt,
-		HeightMod:  0.5,
-		HasWater:   true,
-		HasTrees:   true,
-		TreeChance: 0.01,
-		TreeType:   "oak",
-		HasFlowers: true,
-	}
+	// Configuration
+	Config GeneratorConfig
 
-	BiomeDesert = Biome{
-		Name:       "desert",
-		Surface:    block.Sand,
-		Subsurface: block.Sand,
-		HeightMod:  0.3,
-		HasWater:   true,
-		HasCactus:  true,
-	}
+	// Biomes the terrain is made of
+	Biomes *BiomeRegistry
 
-	BiomeSnow = Biome{
-		Name:       "snow",
-		Surface:    block.Snow,
-		Subsurface: block.Dirt,
-		HeightMod:  0.7,
-		HasWater:   true,
-		WaterType:  block.Ice,
-		HasTrees:   true,
-		TreeChance: 0.02,
-		TreeType:   "spruce",
-	}
+	// Ores placed underground
+	Ores *OreTable
 
-	BiomeForest = Biome{
-		Name:       "forest",
-		Surface:    block.Grass,
-		Subsurface: block.Dirt,
-		HeightMod:  0.6,
-		HasWater:   true,
-		HasTrees:   true,
-		TreeChance: 0.08,
-		TreeType:   "oak",
-		HasFlowers: true,
-	}
+	// Tree species biomes grow
+	Trees *TreeTable
 
-	BiomeMountains = Biome{
-		Name:       "mountains",
-		Surface:    block.Stone,
-		Subsurface: block.Stone,
-		HeightMod:  1.5,
-		HasWater:   true,
-		HasTrees:   true,
-		TreeChance: 0.005,
-		TreeType:   "spruce",
-	}
-)
+	// Villages and ruins built from structure pieces
+	Settlements *SettlementTable
 
-// Generator generates procedural terrain
-type Generator struct {
-	seed int64
-	rng  *vmath.SeededRNG
+	// Loot tables filling generated chests
+	Loot *LootTables
 
-	// Configuration
-	Config GeneratorConfig
+	// Pipeline holds the passes GenerateChunk runs
+	Pipeline *Pipeline
+
+	// HeightGraph gives the terrain height of each column before coasts
+	// and rivers shape it, loaded from height.json
+	HeightGraph *noise.Graph
+	heightOrder []int // Index in heightInputs of each graph input
+
+	// OnFeature is called with the world position of notable generated
+	// features (see Feature constants). It runs on the generating goroutine.
+	OnFeature func(kind string, wx, wy, wz int)
 
 	// Noise generators
-	heightNoise *noise.SimplexNoise
-	biomeNoise  *noise.SimplexNoise
-	caveNoise   *noise.SimplexNoise
-	detailNoise *noise.SimplexNoise
+	biomeNoise     *noise.SimplexNoise
+	caveNoise      *noise.SimplexNoise
+	detailNoise    *noise.SimplexNoise
+	continentNoise *noise.SimplexNoise
+	riverNoise     *noise.SimplexNoise
 
 	// FBM configurations
-	heightFBM *noise.FBM
-	biomeFBM  *noise.FBM
-	caveFBM   *noise.FBM
+	biomeFBM     *noise.FBM
+	continentFBM *noise.FBM
+	riverFBM     *noise.FBM
+
+	// Structures and caves planned per chunk, settlements and dungeons
+	// planned per region, shared by the chunks they reach into
+	structures  planCache[*structurePlan]
+	caves       planCache[*cavePlan]
+	settlements planCache[*settlementPlan]
+	dungeons    planCache[*dungeonPlan]
+
+	// density shapes the terrain when generating through a DensityGenerator
+	density *DensityGenerator
+
+	// shape adjusts the heightmap for the amplified and islands presets
+	shape terrainShape
 }
 
+// GeneratorVersion identifies the terrain algorithm. Bump it whenever a
+// change makes the same seed and config produce different terrain.
+const GeneratorVersion = 11
+
+// Terrain presets
+const (
+	PresetDefault   = "default"   // Heightmap terrain
+	PresetDensity   = "density"   // 3D density terrain with overhangs and floating islands
+	PresetAmplified = "amplified" // Heightmap terrain with towering mountains and deep valleys
+	PresetIslands   = "islands"   // An archipelago of small islands in open sea
+	PresetSuperflat = "superflat" // The same layers of blocks everywhere, see GeneratorConfig.FlatLayers
+	PresetVoid      = "void"      // Empty but for a platform to spawn on
+)
+
+// Presets lists the terrain presets in the order worlds offer them
+var Presets = []string{PresetDefault, PresetDensity, PresetAmplified, PresetIslands, PresetSuperflat, PresetVoid}
+
+// Features reported through Generator.OnFeature
+const (
+	FeatureDungeon   = "dungeon"
+	FeatureWaterfall = "waterfall"
+	FeatureVillage   = "village"
+	FeatureRuin      = "ruin"
+)
+
 // GeneratorConfig holds terrain generation settings
 type GeneratorConfig struct {
 	SeaLevel         int
 	TerrainAmplitude float32
 	TreeDensity      float32
-	CaveFrequency    float32
+	CaveFrequency    float32 // Scales how many caves are carved, 0.6 is normal
+
+	// FlatLayers are the superflat layers from the bottom up, e.g.
+	// "bedrock,2*dirt,grass" (see ParseFlatLayers). Empty means
+	// DefaultFlatLayers.
+	FlatLayers string
 }
 
 // DefaultConfig returns default generation config
@@ -130,329 +131,302 @@ func DefaultConfig() GeneratorConfig {
 // NewGenerator creates a new terrain generator with the given seed
 func NewGenerator(seed int64) *Generator {
 	g := &Generator{
-		seed:        seed,
-		rng:         vmath.NewSeededRNG(seed),
-		Config:      DefaultConfig(), // Use defaults initially
-		heightNoise: noise.NewSimplexNoise(seed),
-		biomeNoise:  noise.NewSimplexNoise(seed + 1000),
-		caveNoise:   noise.NewSimplexNoise(seed + 2000),
-		detailNoise: noise.NewSimplexNoise(seed + 3000),
+		random:         vmath.NewPositionalRNG(seed),
+		Config:         DefaultConfig(), // Use defaults initially
+		Biomes:         DefaultBiomes(),
+		Ores:           DefaultOres(),
+		Trees:          DefaultTrees(),
+		Settlements:    DefaultSettlements(),
+		Loot:           DefaultLoot(),
+		shape:          defaultShape,
+		biomeNoise:     noise.NewSimplexNoise(seed + 1000),
+		caveNoise:      noise.NewSimplexNoise(seed + 2000),
+		detailNoise:    noise.NewSimplexNoise(seed + 3000),
+		continentNoise: noise.NewSimplexNoise(seed + 6000),
+		riverNoise:     noise.NewSimplexNoise(seed + 7000),
 	}
 
-	g.heightFBM = noise.NewFBM(noise.FBMConfig{
-		Octaves:     6,
+	g.Pipeline = g.defaultPipeline(g.generateBase)
+
+	if err := g.setHeightGraph(DefaultHeightGraph(seed)); err != nil {
+		panic(fmt.Sprintf("terrain: invalid built-in height graph: %v", err))
+	}
+
+	g.biomeFBM = noise.NewFBM(noise.FBMConfig{
+		Octaves:     4,
 		Lacunarity:  2.0,
 		Persistence: 0.5,
-		Scale:       0.005,
+		Scale:       0.002,
 	})
 
-	g.biomeFBM = noise.NewFBM(noise.FBMConfig{
+	g.continentFBM = noise.NewFBM(noise.FBMConfig{
 		Octaves:     4,
 		Lacunarity:  2.0,
 		Persistence: 0.5,
-		Scale:       0.002,
+		Scale:       0.0007,
 	})
 
-	g.caveFBM = noise.NewFBM(noise.FBMConfig{
-		Octaves:     3,
+	// Simplex noise is zero on its lattice, so without an offset every
+	// world would have a river through the origin
+	riverRng := g.random.Split("rivers").At(0, 0)
+	g.riverFBM = noise.NewFBM(noise.FBMConfig{
+		Octaves:     4,
 		Lacunarity:  2.0,
 		Persistence: 0.5,
-		Scale:       0.05,
+		Scale:       0.0012,
+		OffsetX:     riverRng.NextFloat(-50000, 50000),
+		OffsetZ:     riverRng.NextFloat(-50000, 50000),
 	})
 
 	return g
 }
 
-// GenerateChunk generates terrain for a chunk
+// NewPresetGenerator creates the chunk generator of a terrain preset with a
+// config, along with the Generator it builds on for biome and config queries
+func NewPresetGenerator(seed int64, preset string, config GeneratorConfig) (*Generator, chunk.ChunkGenerator, error) {
+	var g *Generator
+	var chunkGen chunk.ChunkGenerator
+	switch preset {
+	case PresetDefault:
+		g = NewGenerator(seed)
+		chunkGen = g
+	case PresetDensity:
+		d := NewDensityGenerator(seed)
+		g, chunkGen = d.Generator, d
+	case PresetAmplified:
+		g = NewGenerator(seed)
+		g.shape = amplifiedShape
+		chunkGen = g
+	case PresetIslands:
+		g = NewGenerator(seed)
+		g.shape = islandsShape
+		chunkGen = g
+	case PresetSuperflat:
+		layers, err := ParseFlatLayers(config.FlatLayers)
+		if err != nil {
+			return nil, nil, err
+		}
+		f := NewFlatGenerator(seed, layers)
+		g, chunkGen = f.Generator, f
+	case PresetVoid:
+		v := NewVoidGenerator(seed)
+		g, chunkGen = v.Generator, v
+	default:
+		return nil, nil, fmt.Errorf("unknown terrain preset %q", preset)
+	}
+	g.SetConfig(config)
+	return g, chunkGen, nil
+}
+
+// GenerateChunk generates terrain for a chunk by running the pipeline
 func (g *Generator) GenerateChunk(c *chunk.Chunk) {
+	g.Pipeline.Run(c)
+	g.reportFeatures(c)
+
+	c.IsGenerated = true
+}
+
+// generateBase generates the base terrain, dropping into oceans past the
+// coast and carved by rivers, with water filled up to sea level
+func (g *Generator) generateBase(c *chunk.Chunk) {
 	startX := int(c.CX) * chunk.Size
 	startZ := int(c.CZ) * chunk.Size
-
-	// First pass: base terrain
 	for lx := 0; lx < chunk.Size; lx++ {
 		for lz := 0; lz < chunk.Size; lz++ {
-			wx := startX + lx
-			wz := startZ + lz
-
-			g.generateColumn(c, lx, lz, wx, wz)
+			g.generateColumn(c, lx, lz, startX+lx, startZ+lz)
 		}
 	}
+}
 
-	// Second pass: structures (trees, cacti)
-	g.generateStructures(c, startX, startZ)
+// column is the base terrain of one block column. It depends only on the
+// seed and settings, so structures can be planned before chunks exist.
+type column struct {
+	height   int    // Highest solid block
+	ground   int    // Highest solid block below any floating island
+	biome    *Biome // Biome covering the surface
+	dominant *Biome
+
+	// Blended 3D shape, only used by density terrain
+	overhang float64
+	islands  float64
+}
 
-	// Third pass: decorations (flowers, grass)
-	g.generateDecorations(c, startX, startZ)
+// getColumn computes the base terrain of a block column
+func (g *Generator) getColumn(wx, wz int) column {
+	col := g.heightColumn(wx, wz)
+	if g.density != nil {
+		col.height, col.ground = g.density.surface(wx, wz, col)
+	}
+	return col
+}
 
-	// Fourth pass: waterfalls & lakes
-	g.generateWaterfalls(c, startX, startZ)
+// heightColumn computes a block column from the 2D heightmap alone
+func (g *Generator) heightColumn(wx, wz int) column {
+	climate := g.Climate(wx, wz)
+	weights := g.Biomes.Blend(climate)
+	river := g.riverAt(wx, wz, climate.Elevation)
 
-	// Fifth pass: dungeons (inside caves)
-	g.generateDungeons(c, startX, startZ)
+	col := column{
+		height:   g.getTerrainHeight(wx, wz, climate, weights, river),
+		biome:    pickBiome(weights, g.surfaceRoll(wx, wz)),
+		dominant: weights[0].Biome,
+	}
+	col.ground = col.height
+	for _, w := range weights {
+		col.overhang += w.Biome.Overhang * w.Weight
+		col.islands += w.Biome.Islands * w.Weight
+	}
+	// Rivers keep their smooth valleys so nothing grows over the water
+	col.overhang *= 1 - river.valley
+	col.islands *= 1 - river.valley
 
-	// Sixth pass: surface campfires
-	g.generateCampfires(c, startX, startZ)
+	if b := g.riverBiome(river); b != nil {
+		col.biome, col.dominant = b, b
+	}
+	return col
+}
 
-	c.IsGenerated = true
+// riverBiome returns the river biome for columns in a river channel
+func (g *Generator) riverBiome(r riverColumn) *Biome {
+	if r.channel > 0 {
+		return g.Biomes.River()
+	}
+	return nil
 }
 
 // generateColumn generates a vertical column of blocks
 func (g *Generator) generateColumn(c *chunk.Chunk, lx, lz, wx, wz int) {
-	biome := g.getBiome(wx, wz)
-	baseHeight := g.getTerrainHeight(wx, wz, biome)
+	col := g.heightColumn(wx, wz)
 
 	// Update height map
-	c.HeightMap[lx+lz*chunk.Size] = uint8(baseHeight)
+	c.HeightMap[lx+lz*chunk.Size] = uint8(col.height)
 
 	for y := 0; y < chunk.Height; y++ {
-		var blockType block.Type = block.Air
-
-		if y == 0 {
-			// Bedrock
-			blockType = block.Bedrock
-		} else if y < baseHeight-4 {
-			// Underground
-			blockType = g.getUndergroundBlock(wx, y, wz, biome)
-		} else if y < baseHeight {
-			// Subsurface layer
-			blockType = biome.Subsurface
-		} else if y == baseHeight {
-			// Surface
-			blockType = g.getSurfaceBlock(y, biome)
-		} else if y < g.Config.SeaLevel && biome.HasWater {
-			// Water
-			blockType = block.Water
-		}
-
-		if blockType != block.Air {
+		if blockType := g.columnBlock(col, wx, y, wz); blockType != block.Air {
 			c.SetBlock(lx, y, lz, blockType)
 		}
 	}
 }
 
-// getBiome determines the biome at a world position
-func (g *Generator) getBiome(wx, wz int) Biome {
-	temperature := g.biomeFBM.Sample2D(g.biomeNoise, float64(wx), float64(wz))
-	humidity := g.biomeFBM.Sample2D(g.biomeNoise, float64(wx)+5000, float64(wz)+5000)
-
-	if temperature > 0.3 {
-		if humidity < -0.2 {
-			return BiomeDesert
-		}
-		return BiomePlains
-	} else if temperature < -0.3 {
-		return BiomeSnow
-	} else {
-		if humidity > 0.2 {
-			return BiomeForest
+// columnBlock returns the base terrain block at a height of a column
+func (g *Generator) columnBlock(col column, wx, y, wz int) block.Type {
+	if y == 0 {
+		// Bedrock
+		return block.Bedrock
+	}
+	if y <= col.height {
+		layer, ok := col.biome.LayerAt(col.height - y)
+		if !ok {
+			// Underground, ores and caves come later
+			return block.Stone
 		}
-		return BiomeMountains
-	}
-}
-
-// getTerrainHeight calculates terrain height at a position
-func (g *Generator) getTerrainHeight(wx, wz int, biome Biome) int {
-	height := float64(20) // Base height hardcoded replacing TerrainBaseHeight constant
-
-	// FBM for general terrain
-	fbmValue := g.heightFBM.Sample2D(g.heightNoise, float64(wx), float64(wz))
-
-	// Smooth height modulation based on temperature
-	// This prevents sharp cliffs between biomes by blending the HeightMod
-	temperature := g.biomeFBM.Sample2D(g.biomeNoise, float64(wx), float64(wz))
-
-	// Base height mod (Plains/Forest)
-	heightMod := 0.5
-
-	// Adjust based on temperature (extreme temps = higher/rougher terrain)
-	if temperature < -0.3 {
-		// Snow/Mountains
-		factor := (temperature + 0.3) * -5.0 // 0..1
-		heightMod = 0.5 + factor*1.0         // 0.5 .. 1.5
-	} else if temperature > 0.3 {
-		// Desert/Plains - flattening
-		factor := (temperature - 0.3) * 5.0
-		heightMod = 0.5 - factor*0.2 // 0.5 .. 0.3
-	}
-
-	// Apply smoothed modulation and configured amplitude
-	height += fbmValue * float64(g.Config.TerrainAmplitude) * heightMod
-
-	// High frequency detail
-	detail := g.detailNoise.Noise2D(float64(wx)*0.1, float64(wz)*0.1) * 2
-	height += detail
-
-	// Mountains get ridged noise (blended)
-	if biome.Name == "mountains" {
-		ridged := g.heightFBM.Ridged2D(g.heightNoise, float64(wx)*2, float64(wz)*2)
-		height += ridged * 20
-	} else if temperature < -0.2 && temperature > -0.4 {
-		// Transition to mountains - add some ridged noise seamlessly
-		ridged := g.heightFBM.Ridged2D(g.heightNoise, float64(wx)*2, float64(wz)*2)
-		blend := (temperature + 0.2) / -0.2 // 0 at -0.2, 1 at -0.4
-		// Wait, math: if temp = -0.3. top = -0.1. bottom = -0.2. result = 0.5.
-		// if temp = -0.4. top = -0.2. bottom = -0.2. result = 1.0.
-		blend = absDouble(blend) // Clamp?
-		if blend > 1.0 {
-			blend = 1.0
+		if y == col.height {
+			// Surface
+			return g.getSurfaceBlock(y, layer, col.biome)
 		}
-		height += ridged * 10 * blend
+		// Subsurface layers
+		return layer
 	}
-
-	result := int(height)
-	if result < 1 {
-		result = 1
+	if y < g.Config.SeaLevel {
+		// Water
+		return col.biome.Water
 	}
-	if result > chunk.Height-10 {
-		result = chunk.Height - 10
-	}
-	return result
+	return block.Air
 }
 
-func absDouble(n float64) float64 {
-	if n < 0 {
-		return -n
+// Climate returns the climate values biomes are chosen by at world coordinates
+func (g *Generator) Climate(wx, wz int) Climate {
+	return Climate{
+		Temperature: g.biomeFBM.Sample2D(g.biomeNoise, float64(wx), float64(wz)),
+		Humidity:    g.biomeFBM.Sample2D(g.biomeNoise, float64(wx)+5000, float64(wz)+5000),
+		Elevation:   g.Continentalness(wx, wz),
 	}
-	return n
 }
 
-// getUndergroundBlock determines block type underground
-func (g *Generator) getUndergroundBlock(wx, y, wz int, biome Biome) block.Type {
-	// Caves
-	caveValue := g.caveFBM.Sample3D(g.caveNoise, float64(wx), float64(y), float64(wz))
-	if caveValue > float64(g.Config.CaveFrequency) && y > 5 {
-		// Lava at the bottom of deep caves
-		if y < 10 && caveValue > float64(g.Config.CaveFrequency)+0.05 {
-			return block.Lava
-		}
-		return block.Air
-	}
-
-	// Ores
-	oreChance := g.detailNoise.Noise3D(float64(wx)*0.2, float64(y)*0.2, float64(wz)*0.2)
-
-	if y < 15 && oreChance > 0.85 {
-		return block.DiamondOre
-	} else if y < 30 && oreChance > 0.8 {
-		return block.GoldOre
-	} else if y < 45 && oreChance > 0.75 {
-		return block.IronOre
-	} else if oreChance > 0.7 {
-		return block.CoalOre
+// getBiome determines the dominant biome at a world position
+func (g *Generator) getBiome(wx, wz int) *Biome {
+	climate := g.Climate(wx, wz)
+	if b := g.riverBiome(g.riverAt(wx, wz, climate.Elevation)); b != nil {
+		return b
 	}
-
-	return block.Stone
+	return g.Biomes.Select(climate)
 }
 
-// getSurfaceBlock determines the surface block
-func (g *Generator) getSurfaceBlock(height int, biome Biome) block.Type {
-	if height <= g.Config.SeaLevel+2 && biome.Name != "desert" {
-		return block.Sand
+// getSurfaceBiome determines the biome whose blocks and plants cover a
+// column
+func (g *Generator) getSurfaceBiome(wx, wz int) *Biome {
+	climate := g.Climate(wx, wz)
+	if b := g.riverBiome(g.riverAt(wx, wz, climate.Elevation)); b != nil {
+		return b
 	}
-	return biome.Surface
+	return pickBiome(g.Biomes.Blend(climate), g.surfaceRoll(wx, wz))
 }
 
-// generateStructures generates trees and cacti
-func (g *Generator) generateStructures(c *chunk.Chunk, startX, startZ int) {
-	chunkRng := vmath.NewSeededRNG(g.seed + int64(c.CX)*1000 + int64(c.CZ))
-
-	for lx := 2; lx < chunk.Size-2; lx++ {
-		for lz := 2; lz < chunk.Size-2; lz++ {
-			wx := startX + lx
-			wz := startZ + lz
-			height := c.GetHeight(lx, lz)
-
-			if height <= g.Config.SeaLevel {
-				continue
-			}
-
-			biome := g.getBiome(wx, wz)
-
-			// Trees - scale chance by tree density config
-			// Base chance is for density 0.05. Scaling: config / 0.05
-			densityMultiplier := g.Config.TreeDensity / 0.05
-
-			if biome.HasTrees && chunkRng.Next() < biome.TreeChance*float64(densityMultiplier) {
-				g.generateTree(c, lx, height+1, lz, biome.TreeType, chunkRng)
-			}
-
-			// Cacti
-			if biome.HasCactus && chunkRng.Next() < 0.005 {
-				g.generateCactus(c, lx, height+1, lz, chunkRng)
-			}
-		}
-	}
+// surfaceRoll returns a patchy value in [0, 1) used to pick surface biomes.
+// Near borders the surface biome is picked from the blended biomes, so the
+// surfaces interleave instead of meeting in a straight line.
+func (g *Generator) surfaceRoll(wx, wz int) float64 {
+	n := g.detailNoise.Noise2D(float64(wx)*0.3+7000, float64(wz)*0.3+7000)
+	return math.Max(0, math.Min(0.999, n*0.5+0.5))
 }
 
-// generateTree generates a tree at the given position
-func (g *Generator) generateTree(c *chunk.Chunk, lx, ly, lz int, treeType string, rng *vmath.SeededRNG) {
-	height := 4 + rng.NextInt(0, 2)
-
-	var logType, leafType block.Type
-	switch treeType {
-	case "birch":
-		logType = block.BirchLog
-		leafType = block.BirchLeaves
-	case "spruce":
-		logType = block.SpruceLog
-		leafType = block.SpruceLeaves
-	default: // oak
-		logType = block.OakLog
-		leafType = block.OakLeaves
+// getTerrainHeight calculates terrain height at a position, blending the
+// shape of every biome contributing to the column
+func (g *Generator) getTerrainHeight(wx, wz int, climate Climate, weights []BiomeWeight, river riverColumn) int {
+	var offset, heightMod, ridgedHeight float64
+	for _, w := range weights {
+		offset += w.Biome.HeightOffset * w.Weight
+		heightMod += w.Biome.HeightMod * w.Weight
+		ridgedHeight += w.Biome.Ridged * w.Weight
 	}
 
-	// Trunk
-	for i := 0; i < height; i++ {
-		if ly+i < chunk.Height {
-			c.SetBlock(lx, ly+i, lz, logType)
-		}
-	}
+	// Hills, detail and mountains from the height graph
+	height := g.heightSample(wx, wz, offset, heightMod, ridgedHeight)
 
-	// Leaves
-	leafStart := height - 2
-	for dy := leafStart; dy <= height+1; dy++ {
-		radius := 2
-		if dy == height+1 {
-			radius = 1
-		}
+	// Oceans and rivers
+	height = g.shapeCoast(height, climate.Elevation)
+	height = g.carveRiver(height, river)
+	height = g.shape.amplifyHeight(height, float64(g.Config.SeaLevel))
 
-		for dx := -radius; dx <= radius; dx++ {
-			for dz := -radius; dz <= radius; dz++ {
-				if abs(dx)+abs(dz) <= radius+1 {
-					nlx := lx + dx
-					nlz := lz + dz
-					nly := ly + dy
-
-					if nlx >= 0 && nlx < chunk.Size && nlz >= 0 && nlz < chunk.Size && nly < chunk.Height {
-						if c.GetBlock(nlx, nly, nlz) == block.Air {
-							c.SetBlock(nlx, nly, nlz, leafType)
-						}
-					}
-				}
-			}
-		}
+	result := int(height)
+	if result < 1 {
+		result = 1
+	}
+	if result > chunk.Height-10 {
+		result = chunk.Height - 10
 	}
+	return result
 }
 
-// generateCactus generates a cactus
-func (g *Generator) generateCactus(c *chunk.Chunk, lx, ly, lz int, rng *vmath.SeededRNG) {
-	height := 2 + rng.NextInt(0, 2)
-
-	for i := 0; i < height; i++ {
-		if ly+i < chunk.Height {
-			c.SetBlock(lx, ly+i, lz, block.Cactus)
-		}
+// getSurfaceBlock determines the surface block
+func (g *Generator) getSurfaceBlock(height int, surface block.Type, biome *Biome) block.Type {
+	if height < g.Config.SeaLevel && biome.Underwater != block.Air {
+		return biome.Underwater
 	}
+	return surface
 }
 
 // SetConfig updates the generator configuration
 func (g *Generator) SetConfig(config GeneratorConfig) {
 	g.Config = config
+	g.resetPlans()
+}
+
+// resetPlans drops cached plans after a setting they were built from changed
+func (g *Generator) resetPlans() {
+	g.structures.reset()
+	g.caves.reset()
+	g.settlements.reset()
+	g.dungeons.reset()
 }
 
 // generateDecorations generates flowers and tall grass
-func (g *Generator) generateDecorations(c *chunk.Chunk, startX, startZ int) {
-	chunkRng := vmath.NewSeededRNG(g.seed + int64(c.CX)*2000 + int64(c.CZ))
+func (g *Generator) generateDecorations(c *chunk.Chunk, config PassConfig) {
+	startX := int(c.CX) * chunk.Size
+	startZ := int(c.CZ) * chunk.Size
+	density := config.Get("density", 1)
+	chunkRng := g.random.Split(PassDecorations).At(int(c.CX), int(c.CZ))
+	settlements := g.settlementsNear(int(c.CX), int(c.CZ))
 
 	for lx := 0; lx < chunk.Size; lx++ {
 		for lz := 0; lz < chunk.Size; lz++ {
@@ -460,191 +434,58 @@ func (g *Generator) generateDecorations(c *chunk.Chunk, startX, startZ int) {
 			wz := startZ + lz
 			height := c.GetHeight(lx, lz)
 
-			if height <= g.Config.SeaLevel {
+			if height <= g.Config.SeaLevel || nearSettlement(settlements, wx, wz, 0) {
 				continue
 			}
 
-			biome := g.getBiome(wx, wz)
+			vegetation := g.getSurfaceBiome(wx, wz).Vegetation
 			surfaceBlock := c.GetBlock(lx, height, lz)
 
-			if surfaceBlock != block.Grass {
+			// Don't replace tree trunks and cacti
+			if c.GetBlock(lx, height+1, lz) != block.Air {
 				continue
 			}
 
-			if biome.HasFlowers {
-				// Tall grass
-				if chunkRng.Next() < 0.15 {
-					c.SetBlock(lx, height+1, lz, block.TallGrass)
-				} else if chunkRng.Next() < 0.02 {
+			var plant block.Type = block.Air
+			if surfaceBlock == block.Grass {
+				if chunkRng.Next() < vegetation.GrassChance*density {
+					// Tall grass
+					plant = block.TallGrass
+				} else if chunkRng.Next() < vegetation.FlowerChance*density {
 					// Flowers
-					var flowerType block.Type
 					if chunkRng.Next() > 0.5 {
-						flowerType = block.FlowerRed
+						plant = block.FlowerRed
 					} else {
-						flowerType = block.FlowerYellow
+						plant = block.FlowerYellow
 					}
-					c.SetBlock(lx, height+1, lz, flowerType)
-				}
-			}
-
-			// Mushrooms (rare)
-			if chunkRng.Next() < 0.005 {
-				var mushroom block.Type
-				if chunkRng.Next() > 0.5 {
-					mushroom = block.MushroomRed
-				} else {
-					mushroom = block.MushroomBrown
-				}
-				c.SetBlock(lx, height+1, lz, mushroom)
-			}
-		}
-	}
-}
-
-// generateWaterfalls generates waterfalls in mountain biomes
-func (g *Generator) generateWaterfalls(c *chunk.Chunk, startX, startZ int) {
-	chunkRng := vmath.NewSeededRNG(g.seed + int64(c.CX)*3000 + int64(c.CZ))
-
-	// 15% chance per chunk
-	if chunkRng.Next() > 0.15 {
-		return
-	}
-
-	for lx := 3; lx < chunk.Size-3; lx++ {
-		for lz := 3; lz < chunk.Size-3; lz++ {
-			wx := startX + lx
-			wz := startZ + lz
-			biome := g.getBiome(wx, wz)
-
-			if biome.Name != "mountains" {
-				continue
-			}
-
-			height := c.GetHeight(lx, lz)
-			if height < 35 {
-				continue
-			}
-
-			// Check for cliffs
-			directions := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
-
-			for _, dir := range directions {
-				if lx+dir[0]*2 < 0 || lx+dir[0]*2 >= chunk.Size ||
-					lz+dir[1]*2 < 0 || lz+dir[1]*2 >= chunk.Size {
-					continue
-				}
-
-				neighborHeight := c.GetHeight(lx+dir[0]*2, lz+dir[1]*2)
-				heightDiff := height - neighborHeight
-
-				if heightDiff >= 8 && chunkRng.Next() < 0.3 {
-					// Place water source at top
-					c.SetBlock(lx, height, lz, block.Water)
-
-					// Create cascade
-					currentY := height - 1
-					currentX := lx + dir[0]
-					currentZ := lz + dir[1]
-
-					for currentY > neighborHeight && currentY > g.Config.SeaLevel {
-						if currentX >= 0 && currentX < chunk.Size &&
-							currentZ >= 0 && currentZ < chunk.Size {
-							if c.GetBlock(currentX, currentY, currentZ) == block.Air {
-								c.SetBlock(currentX, currentY, currentZ, block.Water)
-							}
-						}
-						currentY--
-					}
-
-					// Create a lake at the base
-					g.generateLake(c, currentX, neighborHeight, currentZ, 3, block.Water)
-
-					// Only one waterfall per chunk
-					return
-				}
-			}
-		}
-	}
-}
-
-// generateLake creates a small circular pool
-func (g *Generator) generateLake(c *chunk.Chunk, lx, ly, lz, radius int, liquid block.Type) {
-	for dx := -radius; dx <= radius; dx++ {
-		for dz := -radius; dz <= radius; dz++ {
-			if dx*dx+dz*dz <= radius*radius {
-				nx, nz := lx+dx, lz+dz
-				if nx >= 0 && nx < chunk.Size && nz >= 0 && nz < chunk.Size {
-					// Carve out a bit of the shore if needed, and fill with liquid
-					for dy := -1; dy <= 0; dy++ {
-						ny := ly + dy
-						if ny > 0 && ny < chunk.Height {
-							c.SetBlock(nx, ny, nz, liquid)
-						}
+				} else if chunkRng.Next() < vegetation.MushroomChance*density {
+					// Mushrooms (rare)
+					if chunkRng.Next() > 0.5 {
+						plant = block.MushroomRed
+					} else {
+						plant = block.MushroomBrown
 					}
 				}
+			} else if !surfaceBlock.IsLiquid() && chunkRng.Next() < vegetation.DeadBushChance*density {
+				// Dead bushes on dry ground
+				plant = block.DeadBush
 			}
-		}
-	}
-}
-
-// generateDungeons places stone brick structures in large cave pockets
-func (g *Generator) generateDungeons(c *chunk.Chunk, startX, startZ int) {
-	chunkRng := vmath.NewSeededRNG(g.seed + int64(c.CX)*4000 + int64(c.CZ))
-
-	// 5% chance per chunk for a dungeon
-	if chunkRng.Next() > 0.05 {
-		return
-	}
-
-	// Try to find a suitable cave location
-	for attempt := 0; attempt < 10; attempt++ {
-		lx := chunkRng.NextInt(4, chunk.Size-4)
-		lz := chunkRng.NextInt(4, chunk.Size-4)
-		ly := chunkRng.NextInt(10, 30)
-
-		// Check if it's in a cave
-		if c.GetBlock(lx, ly, lz) == block.Air {
-			g.buildDungeonRoom(c, lx, ly, lz, chunkRng)
-			return
-		}
-	}
-}
-
-func (g *Generator) buildDungeonRoom(c *chunk.Chunk, x, y, z int, rng *vmath.SeededRNG) {
-	width := rng.NextInt(5, 8)
-	height := rng.NextInt(4, 6)
-	depth := rng.NextInt(5, 8)
-
-	for dx := -width / 2; dx <= width/2; dx++ {
-		for dy := 0; dy < height; dy++ {
-			for dz := -depth / 2; dz <= depth/2; dz++ {
-				nx, ny, nz := x+dx, y+dy, z+dz
-				if nx < 0 || nx >= chunk.Size || ny < 0 || ny >= chunk.Height || nz < 0 || nz >= chunk.Size {
-					continue
-				}
-
-				isWall := dx == -width/2 || dx == width/2 || dy == 0 || dy == height-1 || dz == -depth/2 || dz == depth/2
 
-				if isWall {
-					brick := block.StoneBrick
-					if rng.Next() < 0.2 {
-						brick = block.MossyStoneBrick
-					}
-					c.SetBlock(nx, ny, nz, brick)
-				} else {
-					c.SetBlock(nx, ny, nz, block.Air)
-				}
+			if plant != block.Air {
+				c.SetBlock(lx, height+1, lz, plant)
 			}
 		}
 	}
 }
 
 // generateCampfires places fogueiras on the surface
-func (g *Generator) generateCampfires(c *chunk.Chunk, startX, startZ int) {
-	chunkRng := vmath.NewSeededRNG(g.seed + int64(c.CX)*5000 + int64(c.CZ))
+func (g *Generator) generateCampfires(c *chunk.Chunk, config PassConfig) {
+	startX := int(c.CX) * chunk.Size
+	startZ := int(c.CZ) * chunk.Size
+	chunkRng := g.random.Split(PassCampfires).At(int(c.CX), int(c.CZ))
 
-	// Very rare: 2% chance per chunk
-	if chunkRng.Next() > 0.02 {
+	// Very rare: 2% chance per chunk by default
+	if chunkRng.Next() > config.Get("chance", 0.02) {
 		return
 	}
 
@@ -654,7 +495,7 @@ func (g *Generator) generateCampfires(c *chunk.Chunk, startX, startZ int) {
 	wz := startZ + lz
 	biome := g.getBiome(wx, wz)
 
-	if biome.Name == "plains" || biome.Name == "forest" {
+	if biome.Campfires && !nearSettlement(g.settlementsNear(int(c.CX), int(c.CZ)), wx, wz, 0) {
 		height := c.GetHeight(lx, lz)
 		if height > g.Config.SeaLevel {
 			c.SetBlock(lx, height+1, lz, block.Campfire)
@@ -673,3 +514,8 @@ func abs(n int) int {
 func (g *Generator) GetBiomeName(wx, wz int) string {
 	return g.getBiome(wx, wz).Name
 }
+
+// GetBiome returns the dominant biome at world coordinates
+func (g *Generator) GetBiome(wx, wz int) *Biome {
+	return g.getBiome(wx, wz)
+}
@@ -0,0 +1,87 @@
+// Package terrain provides the noise graph behind terrain height
+package terrain
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+
+	"voxelgame/internal/core/noise"
+)
+
+//go:embed height.json
+var defaultHeightJSON []byte
+
+// Inputs a height graph may declare, passed for every column
+const (
+	HeightInputOffset    = "offset"    // Biome height offset
+	HeightInputHeightMod = "heightMod" // Biome hilliness
+	HeightInputRidged    = "ridged"    // Biome mountain height
+	HeightInputAmplitude = "amplitude" // GeneratorConfig.TerrainAmplitude
+)
+
+// heightInputs are the inputs in the order heightSample fills them
+var heightInputs = []string{HeightInputOffset, HeightInputHeightMod, HeightInputRidged, HeightInputAmplitude}
+
+// DefaultHeightGraph returns the built-in height graph seeded for a world
+func DefaultHeightGraph(seed int64) *noise.Graph {
+	g, err := LoadHeightGraph(bytes.NewReader(defaultHeightJSON), seed)
+	if err != nil {
+		panic(fmt.Sprintf("terrain: invalid built-in height graph: %v", err))
+	}
+	return g
+}
+
+// LoadHeightGraph reads a height graph from JSON (see noise.Graph), seeded
+// for a world. The graph gives the terrain height before coasts, rivers and
+// presets shape it, and may only declare the HeightInput inputs.
+func LoadHeightGraph(r io.Reader, seed int64) (*noise.Graph, error) {
+	g, err := noise.LoadGraph(r, seed)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := heightInputOrder(g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// heightInputOrder maps each input the graph declares to its index in
+// heightInputs
+func heightInputOrder(g *noise.Graph) ([]int, error) {
+	order := make([]int, len(g.Inputs()))
+next:
+	for i, name := range g.Inputs() {
+		for j, known := range heightInputs {
+			if name == known {
+				order[i] = j
+				continue next
+			}
+		}
+		return nil, fmt.Errorf("height graph: unknown input %q", name)
+	}
+	return order, nil
+}
+
+// setHeightGraph replaces the height graph, checking its inputs
+func (g *Generator) setHeightGraph(graph *noise.Graph) error {
+	order, err := heightInputOrder(graph)
+	if err != nil {
+		return err
+	}
+	g.HeightGraph = graph
+	g.heightOrder = order
+	g.resetPlans()
+	return nil
+}
+
+// heightSample evaluates the height graph for a column
+func (g *Generator) heightSample(wx, wz int, offset, heightMod, ridged float64) float64 {
+	values := [...]float64{offset, heightMod, ridged, float64(g.Config.TerrainAmplitude)}
+	var args [len(values)]float64
+	for i, j := range g.heightOrder {
+		args[i] = values[j]
+	}
+	return g.HeightGraph.Sample2D(float64(wx), float64(wz), args[:len(g.heightOrder)]...)
+}
@@ -0,0 +1,158 @@
+// Package terrain provides data-driven loot tables
+package terrain
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"voxelgame/internal/core/block"
+	vmath "voxelgame/pkg/math"
+)
+
+//go:embed loot.json
+var defaultLootJSON []byte
+
+// Loot tables used by generated containers
+const (
+	LootDungeon         = "dungeon"          // Chests in dungeon rooms
+	LootDungeonTreasure = "dungeon_treasure" // Chests in the room furthest from a dungeon's entrance
+)
+
+// CountRange is an inclusive range of counts
+type CountRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// LootEntry is an item a loot table may roll
+type LootEntry struct {
+	Item   block.Type `json:"item"`
+	Weight float64    `json:"weight"`
+	Count  CountRange `json:"count"` // Items per roll
+}
+
+// LootTable describes what a container holds
+type LootTable struct {
+	Name    string      `json:"name"`
+	Rolls   CountRange  `json:"rolls"` // Entries picked per container
+	Entries []LootEntry `json:"entries"`
+}
+
+// ItemStack is a number of items of one type
+type ItemStack struct {
+	Item  block.Type
+	Count int
+}
+
+// LootTables holds the loot tables of generated containers
+type LootTables struct {
+	tables map[string]*LootTable
+}
+
+// lootFile is the JSON layout of loot tables
+type lootFile struct {
+	Tables []*LootTable `json:"tables"`
+}
+
+// defaultLoot is parsed once, tables are never modified
+var defaultLoot = func() *LootTables {
+	t, err := LoadLoot(bytes.NewReader(defaultLootJSON))
+	if err != nil {
+		panic(fmt.Sprintf("terrain: invalid built-in loot tables: %v", err))
+	}
+	return t
+}()
+
+// DefaultLoot returns the built-in loot tables
+func DefaultLoot() *LootTables {
+	return defaultLoot
+}
+
+// LoadLoot reads loot tables from JSON
+func LoadLoot(r io.Reader) (*LootTables, error) {
+	var file lootFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, err
+	}
+	return NewLootTables(file.Tables)
+}
+
+// NewLootTables validates loot tables. Containers whose table is missing
+// are empty.
+func NewLootTables(tables []*LootTable) (*LootTables, error) {
+	t := &LootTables{tables: make(map[string]*LootTable, len(tables))}
+	for _, lt := range tables {
+		if lt.Name == "" {
+			return nil, fmt.Errorf("loot table without a name")
+		}
+		if t.tables[lt.Name] != nil {
+			return nil, fmt.Errorf("loot table %q defined twice", lt.Name)
+		}
+		if lt.Rolls.Min < 0 || lt.Rolls.Min > lt.Rolls.Max {
+			return nil, fmt.Errorf("loot table %q: invalid rolls", lt.Name)
+		}
+		if len(lt.Entries) == 0 {
+			return nil, fmt.Errorf("loot table %q has no entries", lt.Name)
+		}
+		for _, e := range lt.Entries {
+			if e.Item == block.Air {
+				return nil, fmt.Errorf("loot table %q: entry without an item", lt.Name)
+			}
+			if e.Weight <= 0 {
+				return nil, fmt.Errorf("loot table %q: %s needs a positive weight", lt.Name, e.Item.Key())
+			}
+			if e.Count.Min < 1 || e.Count.Min > e.Count.Max {
+				return nil, fmt.Errorf("loot table %q: invalid count for %s", lt.Name, e.Item.Key())
+			}
+		}
+		t.tables[lt.Name] = lt
+	}
+	return t, nil
+}
+
+// Get returns a loot table, or nil if there is none with the name
+func (t *LootTables) Get(name string) *LootTable {
+	return t.tables[name]
+}
+
+// Roll picks the contents of a container. Items rolled more than once are
+// merged into one stack.
+func (lt *LootTable) Roll(rng *vmath.SeededRNG) []ItemStack {
+	total := 0.0
+	for _, e := range lt.Entries {
+		total += e.Weight
+	}
+
+	var stacks []ItemStack
+	rolls := rng.NextInt(lt.Rolls.Min, lt.Rolls.Max)
+	for i := 0; i < rolls; i++ {
+		pick := rng.Next() * total
+		e := lt.Entries[len(lt.Entries)-1]
+		for _, candidate := range lt.Entries {
+			if pick < candidate.Weight {
+				e = candidate
+				break
+			}
+			pick -= candidate.Weight
+		}
+
+		count := rng.NextInt(e.Count.Min, e.Count.Max)
+		merged := false
+		for j := range stacks {
+			if stacks[j].Item == e.Item {
+				stacks[j].Count += count
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			stacks = append(stacks, ItemStack{e.Item, count})
+		}
+	}
+	return stacks
+}
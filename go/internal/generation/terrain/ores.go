@@ -0,0 +1,194 @@
+// Package terrain provides data-driven ore distribution
+package terrain
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/core/chunk"
+	vmath "voxelgame/pkg/math"
+)
+
+//go:embed ores.json
+var defaultOresJSON []byte
+
+// Height distributions of ore veins
+const (
+	DistributionUniform  = "uniform"  // Equally likely across the range
+	DistributionTriangle = "triangle" // Most likely in the middle of the range
+)
+
+// HeightRange is an inclusive range of block heights
+type HeightRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// Ore describes veins of a block placed underground
+type Ore struct {
+	Name  string     `json:"name"`
+	Block block.Type `json:"block"`
+
+	VeinSize      int     `json:"veinSize"`      // Most blocks per vein, fewer where there is no host
+	VeinsPerChunk float64 `json:"veinsPerChunk"` // A fraction is the chance of one more vein
+
+	Height       HeightRange `json:"height"`       // Heights vein centers lie in
+	Distribution string      `json:"distribution"` // How vein centers spread over Height
+
+	Hosts  []block.Type `json:"hosts"`  // Blocks a vein may replace
+	Biomes []string     `json:"biomes"` // Biomes the vein center must be in, any if empty
+}
+
+// OreTable holds the ores a generator places
+type OreTable struct {
+	ores []*Ore
+}
+
+// oreFile is the JSON layout of an ore table
+type oreFile struct {
+	Ores []*Ore `json:"ores"`
+}
+
+// defaultOres is parsed once, tables are never modified
+var defaultOres = func() *OreTable {
+	t, err := LoadOres(bytes.NewReader(defaultOresJSON))
+	if err != nil {
+		panic(fmt.Sprintf("terrain: invalid built-in ores: %v", err))
+	}
+	return t
+}()
+
+// DefaultOres returns the built-in ore table
+func DefaultOres() *OreTable {
+	return defaultOres
+}
+
+// LoadOres reads an ore table from JSON
+func LoadOres(r io.Reader) (*OreTable, error) {
+	var file oreFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, err
+	}
+	return NewOreTable(file.Ores)
+}
+
+// NewOreTable validates ores and builds a table from them. An empty table
+// places no ores.
+func NewOreTable(ores []*Ore) (*OreTable, error) {
+	names := make(map[string]bool, len(ores))
+	for _, o := range ores {
+		if o.Name == "" {
+			return nil, fmt.Errorf("ore without a name")
+		}
+		if names[o.Name] {
+			return nil, fmt.Errorf("ore %q defined twice", o.Name)
+		}
+		names[o.Name] = true
+
+		if o.VeinSize < 1 {
+			return nil, fmt.Errorf("ore %q: vein size must be at least 1", o.Name)
+		}
+		if o.VeinsPerChunk < 0 {
+			return nil, fmt.Errorf("ore %q: veins per chunk must not be negative", o.Name)
+		}
+		if o.Height.Min < 1 || o.Height.Max >= chunk.Height || o.Height.Min > o.Height.Max {
+			return nil, fmt.Errorf("ore %q: height range must lie within [1, %d]", o.Name, chunk.Height-1)
+		}
+		switch o.Distribution {
+		case DistributionUniform, DistributionTriangle:
+		default:
+			return nil, fmt.Errorf("ore %q: unknown distribution %q", o.Name, o.Distribution)
+		}
+		if len(o.Hosts) == 0 {
+			return nil, fmt.Errorf("ore %q has no host blocks", o.Name)
+		}
+	}
+	return &OreTable{ores: ores}, nil
+}
+
+// Ores returns the ores in definition order
+func (t *OreTable) Ores() []*Ore {
+	return t.ores
+}
+
+// inBiome reports whether veins of the ore may start in a biome
+func (o *Ore) inBiome(b *Biome) bool {
+	if len(o.Biomes) == 0 {
+		return true
+	}
+	for _, name := range o.Biomes {
+		if name == b.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// veinHeight picks the height of a vein center
+func (o *Ore) veinHeight(rng *vmath.SeededRNG) int {
+	span := float64(o.Height.Max - o.Height.Min + 1)
+	r := rng.Next()
+	if o.Distribution == DistributionTriangle {
+		r = (r + rng.Next()) / 2
+	}
+	return o.Height.Min + int(r*span)
+}
+
+// planOres plans the veins of every ore starting in a chunk. Each ore has
+// its own random sequence keyed by its name, so changing, adding or
+// removing one ore leaves the others in place.
+func (g *Generator) planOres(p *structurePlan, cx, cz int, columns []column) {
+	random := g.random.Split(PassOres)
+	multiplier := g.Pipeline.Config(PassOres).Get("veins", 1)
+	for _, o := range g.Ores.Ores() {
+		oreRng := random.Split(o.Name).At(cx, cz)
+
+		perChunk := o.VeinsPerChunk * multiplier
+		veins := int(perChunk)
+		if oreRng.Next() < perChunk-float64(veins) {
+			veins++
+		}
+
+		for v := 0; v < veins; v++ {
+			lx := oreRng.NextInt(0, chunk.Size-1)
+			lz := oreRng.NextInt(0, chunk.Size-1)
+			y := o.veinHeight(oreRng)
+			if !o.inBiome(columns[lx+lz*chunk.Size].dominant) {
+				continue
+			}
+			p.vein(cx*chunk.Size+lx, y, cz*chunk.Size+lz, o, oreRng)
+		}
+	}
+}
+
+// vein plans a cluster of ore grown by a random walk from its center
+func (p *structurePlan) vein(wx, wy, wz int, o *Ore, rng *vmath.SeededRNG) {
+	x, y, z := wx, wy, wz
+	for i := 0; i < o.VeinSize; i++ {
+		p.setHosted(stageOres, x, y, z, o.Block, o.Hosts)
+
+		// Step to a neighbour, starting over from the center if the vein
+		// wanders too far
+		step := 1
+		if rng.Next() < 0.5 {
+			step = -1
+		}
+		switch rng.NextInt(0, 2) {
+		case 0:
+			x += step
+		case 1:
+			y += step
+		default:
+			z += step
+		}
+		if abs(x-wx) > 2 || abs(y-wy) > 2 || abs(z-wz) > 2 {
+			x, y, z = wx, wy, wz
+		}
+	}
+}
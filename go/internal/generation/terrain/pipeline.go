@@ -0,0 +1,298 @@
+// Package terrain provides the pipeline of chunk generation passes
+package terrain
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"voxelgame/internal/core/chunk"
+)
+
+// Built-in generation passes, in the order they run
+const (
+	PassBase        = "base"        // Base terrain, oceans, rivers and water
+	PassCaves       = "caves"       // Tunnels, ravines and caverns
+	PassOres        = "ores"        // Ore veins in the remaining ground
+	PassSettlements = "settlements" // Villages and ruins
+	PassVegetation  = "vegetation"  // Trees and cacti
+	PassDecorations = "decorations" // Flowers, grass and mushrooms
+	PassWaterfalls  = "waterfalls"  // Waterfalls on cliffs
+	PassDungeons    = "dungeons"    // Rooms and corridors underground
+	PassCampfires   = "campfires"   // Rare surface campfires
+)
+
+// PassConfig holds the settings of a pass by key. Passes read them with
+// Get, so unset keys fall back to the pass's defaults.
+type PassConfig map[string]float64
+
+// Get returns a setting, or def if it is unset
+func (c PassConfig) Get(key string, def float64) float64 {
+	if v, ok := c[key]; ok {
+		return v
+	}
+	return def
+}
+
+// Pass is one step of chunk generation. Passes run on the generating
+// goroutine, concurrently for different chunks, and must give the same
+// blocks whatever order chunks generate in.
+type Pass struct {
+	Name string
+
+	// After and Before name passes this one must run after or before
+	After  []string
+	Before []string
+
+	// Run generates the pass into a chunk with the pass's config
+	Run func(c *chunk.Chunk, config PassConfig)
+
+	// Config is the pass's initial config, see Pipeline.Configure
+	Config PassConfig
+}
+
+// PassTiming is the time spent in a pass
+type PassTiming struct {
+	Name    string
+	Enabled bool
+	Chunks  int64         // Chunks the pass ran on
+	Total   time.Duration // Time spent over all of them
+}
+
+// Average returns the mean time the pass took per chunk
+func (t PassTiming) Average() time.Duration {
+	if t.Chunks == 0 {
+		return 0
+	}
+	return t.Total / time.Duration(t.Chunks)
+}
+
+// Pipeline runs the passes of chunk generation in an order satisfying
+// their constraints. Passes may be registered, toggled and configured
+// before chunks are generated; timings are safe to read at any time.
+type Pipeline struct {
+	passes []*pipelinePass // In registration order
+	order  []*pipelinePass // In run order
+
+	// onConfigure is called after a setting changes, so cached plans
+	// built from the old settings are dropped
+	onConfigure func()
+}
+
+// pipelinePass is a registered pass with its state
+type pipelinePass struct {
+	Pass
+	disabled bool
+	chunks   atomic.Int64
+	nanos    atomic.Int64
+}
+
+// NewPipeline creates an empty pipeline
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Register adds a pass. Passes without constraints between them run in
+// the order they were registered.
+func (p *Pipeline) Register(pass Pass) error {
+	if pass.Name == "" {
+		return fmt.Errorf("pass has no name")
+	}
+	if pass.Run == nil {
+		return fmt.Errorf("pass %q has no Run function", pass.Name)
+	}
+	if p.find(pass.Name) != nil {
+		return fmt.Errorf("pass %q is already registered", pass.Name)
+	}
+	if pass.Config == nil {
+		pass.Config = PassConfig{}
+	}
+
+	passes := append(p.passes, &pipelinePass{Pass: pass})
+	order, err := sortPasses(passes)
+	if err != nil {
+		return err
+	}
+	p.passes, p.order = passes, order
+	return nil
+}
+
+// SetEnabled turns a pass on or off. Like Configure it is for previews
+// and debugging: saves don't record pass settings, so a world must be
+// played with the passes it was created with.
+func (p *Pipeline) SetEnabled(name string, enabled bool) error {
+	pass := p.find(name)
+	if pass == nil {
+		return fmt.Errorf("unknown pass %q", name)
+	}
+	pass.disabled = !enabled
+	return nil
+}
+
+// Enabled reports whether a pass is registered and turned on
+func (p *Pipeline) Enabled(name string) bool {
+	pass := p.find(name)
+	return pass != nil && !pass.disabled
+}
+
+// Configure sets one setting of a pass, see SetEnabled
+func (p *Pipeline) Configure(name, key string, value float64) error {
+	pass := p.find(name)
+	if pass == nil {
+		return fmt.Errorf("unknown pass %q", name)
+	}
+	pass.Config[key] = value
+	if p.onConfigure != nil {
+		p.onConfigure()
+	}
+	return nil
+}
+
+// Config returns the settings of a pass, or nil if there is no such pass.
+// Passes that plan ahead, like caves and ores, read their settings here
+// while planning rather than from the config they are run with.
+func (p *Pipeline) Config(name string) PassConfig {
+	if pass := p.find(name); pass != nil {
+		return pass.Config
+	}
+	return nil
+}
+
+// Passes returns the names of the passes in run order
+func (p *Pipeline) Passes() []string {
+	names := make([]string, len(p.order))
+	for i, pass := range p.order {
+		names[i] = pass.Name
+	}
+	return names
+}
+
+// Timings returns the time spent in each pass, in run order
+func (p *Pipeline) Timings() []PassTiming {
+	timings := make([]PassTiming, len(p.order))
+	for i, pass := range p.order {
+		timings[i] = PassTiming{
+			Name:    pass.Name,
+			Enabled: !pass.disabled,
+			Chunks:  pass.chunks.Load(),
+			Total:   time.Duration(pass.nanos.Load()),
+		}
+	}
+	return timings
+}
+
+// Run generates a chunk by running the enabled passes in order
+func (p *Pipeline) Run(c *chunk.Chunk) {
+	for _, pass := range p.order {
+		if pass.disabled {
+			continue
+		}
+		start := time.Now()
+		pass.Run(c, pass.Config)
+		pass.nanos.Add(int64(time.Since(start)))
+		pass.chunks.Add(1)
+	}
+}
+
+// find returns a registered pass by name
+func (p *Pipeline) find(name string) *pipelinePass {
+	for _, pass := range p.passes {
+		if pass.Name == name {
+			return pass
+		}
+	}
+	return nil
+}
+
+// sortPasses orders passes so each runs after the ones it must follow,
+// taking the earliest registered pass whenever several are free to run
+func sortPasses(passes []*pipelinePass) ([]*pipelinePass, error) {
+	byName := make(map[string]*pipelinePass, len(passes))
+	for _, pass := range passes {
+		byName[pass.Name] = pass
+	}
+
+	// Passes each one must wait for
+	waits := make(map[*pipelinePass][]*pipelinePass, len(passes))
+	for _, pass := range passes {
+		for _, name := range pass.After {
+			other, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("pass %q runs after unknown pass %q", pass.Name, name)
+			}
+			waits[pass] = append(waits[pass], other)
+		}
+		for _, name := range pass.Before {
+			other, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("pass %q runs before unknown pass %q", pass.Name, name)
+			}
+			waits[other] = append(waits[other], pass)
+		}
+	}
+
+	done := make(map[*pipelinePass]bool, len(passes))
+	order := make([]*pipelinePass, 0, len(passes))
+	for len(order) < len(passes) {
+		var next *pipelinePass
+		for _, pass := range passes {
+			if done[pass] {
+				continue
+			}
+			ready := true
+			for _, w := range waits[pass] {
+				if !done[w] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = pass
+				break
+			}
+		}
+		if next == nil {
+			var stuck []string
+			for _, pass := range passes {
+				if !done[pass] {
+					stuck = append(stuck, pass.Name)
+				}
+			}
+			sort.Strings(stuck)
+			return nil, fmt.Errorf("passes %v have circular ordering constraints", stuck)
+		}
+		done[next] = true
+		order = append(order, next)
+	}
+	return order, nil
+}
+
+// defaultPipeline creates the built-in passes around a base terrain pass
+func (g *Generator) defaultPipeline(base func(c *chunk.Chunk)) *Pipeline {
+	p := NewPipeline()
+	// The base pass is shaped by the GeneratorConfig instead. Passes built
+	// from plans spanning several chunks read their settings while
+	// planning, see Pipeline.Config.
+	passes := []Pass{
+		{Name: PassBase, Run: func(c *chunk.Chunk, _ PassConfig) { base(c) }},
+		{Name: PassCaves, Run: func(c *chunk.Chunk, _ PassConfig) { g.applyCaves(c) }, Config: PassConfig{"frequency": 1}},
+		{Name: PassOres, Run: func(c *chunk.Chunk, _ PassConfig) { g.applyStructures(c, stageOres) }, Config: PassConfig{"veins": 1}},
+		{Name: PassSettlements, Run: func(c *chunk.Chunk, _ PassConfig) { g.applySettlements(c) }, Config: PassConfig{"chance": 1}},
+		{Name: PassVegetation, Run: func(c *chunk.Chunk, _ PassConfig) { g.applyStructures(c, stageVegetation) }, Config: PassConfig{"density": 1}},
+		{Name: PassDecorations, Run: g.generateDecorations, Config: PassConfig{"density": 1}},
+		{Name: PassWaterfalls, Run: func(c *chunk.Chunk, _ PassConfig) { g.applyStructures(c, stageWaterfalls) }, Config: PassConfig{"chance": 0.15}},
+		{Name: PassDungeons, Run: func(c *chunk.Chunk, _ PassConfig) { g.applyDungeons(c) }, Config: PassConfig{"chance": dungeonChance}},
+		{Name: PassCampfires, Run: g.generateCampfires, Config: PassConfig{"chance": 0.02}},
+	}
+	for i, pass := range passes {
+		if i > 0 {
+			pass.After = []string{passes[i-1].Name}
+		}
+		if err := p.Register(pass); err != nil {
+			panic(fmt.Sprintf("terrain: invalid built-in pass: %v", err))
+		}
+	}
+	p.onConfigure = g.resetPlans
+	return p
+}
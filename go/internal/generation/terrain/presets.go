@@ -0,0 +1,162 @@
+// Package terrain provides the amplified, islands, superflat and void presets
+package terrain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/core/chunk"
+)
+
+// DefaultFlatLayers are the superflat layers used when none are configured
+const DefaultFlatLayers = "bedrock,2*dirt,grass"
+
+// Void spawn platform
+const (
+	VoidPlatformY      = 32 // Height of the platform's top
+	voidPlatformRadius = 3
+)
+
+// SpawnFinder is implemented by chunk generators that choose the column
+// new players start in. Every preset's chunk generator implements it.
+type SpawnFinder interface {
+	SpawnPoint() (wx, wz int)
+}
+
+// terrainShape adjusts the heightmap of Generator for presets built on it
+type terrainShape struct {
+	continentScale  float64 // Scales continent noise frequency, higher for smaller landmasses
+	continentOffset float64 // Added to continentalness, negative for more sea
+	amplify         float64 // Scales heights above sea level
+}
+
+var (
+	defaultShape   = terrainShape{continentScale: 1, amplify: 1}
+	amplifiedShape = terrainShape{continentScale: 1, amplify: 2.2}
+	islandsShape   = terrainShape{continentScale: 3.5, continentOffset: -0.3, amplify: 1}
+)
+
+// amplifyHeight stretches a terrain height away from sea level, easing off
+// towards the highest height terrain may reach
+func (s terrainShape) amplifyHeight(height, seaLevel float64) float64 {
+	if s.amplify == 1 || height <= seaLevel {
+		return height
+	}
+	height = seaLevel + (height-seaLevel)*s.amplify
+
+	const ease = 12
+	if knee := float64(chunk.Height - 10 - ease); height > knee {
+		height = knee + ease*math.Tanh((height-knee)/ease)
+	}
+	return height
+}
+
+// SpawnPoint returns the land column nearest to the origin
+func (g *Generator) SpawnPoint() (int, int) {
+	return g.FindLand(0, 0, 2048)
+}
+
+// FlatGenerator generates superflat terrain: the same layers of blocks in
+// every column, without caves or structures. Biome queries still come from
+// the Generator it builds on.
+type FlatGenerator struct {
+	*Generator
+
+	layers []block.Type // From the bottom up
+}
+
+// NewFlatGenerator creates a superflat generator with layers from the
+// bottom up
+func NewFlatGenerator(seed int64, layers []block.Type) *FlatGenerator {
+	return &FlatGenerator{
+		Generator: NewGenerator(seed),
+		layers:    layers,
+	}
+}
+
+// GenerateChunk fills a chunk with the layers
+func (f *FlatGenerator) GenerateChunk(c *chunk.Chunk) {
+	for lx := 0; lx < chunk.Size; lx++ {
+		for lz := 0; lz < chunk.Size; lz++ {
+			for y, t := range f.layers {
+				if t != block.Air {
+					c.SetBlock(lx, y, lz, t)
+				}
+			}
+			c.UpdateHeight(lx, lz)
+		}
+	}
+}
+
+// SpawnPoint returns the origin, every column is alike
+func (f *FlatGenerator) SpawnPoint() (int, int) {
+	return 0, 0
+}
+
+// ParseFlatLayers parses superflat layers from the bottom up, written as
+// comma-separated block keys each with an optional count, e.g.
+// "bedrock,2*dirt,grass". An empty spec gives DefaultFlatLayers.
+func ParseFlatLayers(spec string) ([]block.Type, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = DefaultFlatLayers
+	}
+
+	var layers []block.Type
+	for _, part := range strings.Split(spec, ",") {
+		key := strings.TrimSpace(part)
+		count := 1
+		if n, rest, ok := strings.Cut(key, "*"); ok {
+			c, err := strconv.Atoi(strings.TrimSpace(n))
+			if err != nil || c < 1 {
+				return nil, fmt.Errorf("superflat layer %q: invalid count", part)
+			}
+			count, key = c, strings.TrimSpace(rest)
+		}
+		t, err := block.ParseKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("superflat layer %q: %w", part, err)
+		}
+		for i := 0; i < count; i++ {
+			layers = append(layers, t)
+		}
+	}
+	if len(layers) >= chunk.Height {
+		return nil, fmt.Errorf("superflat layers are %d blocks high, at most %d fit", len(layers), chunk.Height-1)
+	}
+	return layers, nil
+}
+
+// VoidGenerator generates empty chunks, apart from a small platform at the
+// origin for players to spawn on
+type VoidGenerator struct {
+	*Generator
+}
+
+// NewVoidGenerator creates a void generator
+func NewVoidGenerator(seed int64) *VoidGenerator {
+	return &VoidGenerator{Generator: NewGenerator(seed)}
+}
+
+// GenerateChunk leaves a chunk empty unless the platform reaches into it
+func (v *VoidGenerator) GenerateChunk(c *chunk.Chunk) {
+	for lx := 0; lx < chunk.Size; lx++ {
+		for lz := 0; lz < chunk.Size; lz++ {
+			wx := int(c.CX)*chunk.Size + lx
+			wz := int(c.CZ)*chunk.Size + lz
+			if abs(wx) > voidPlatformRadius || abs(wz) > voidPlatformRadius {
+				continue
+			}
+			c.SetBlock(lx, VoidPlatformY-1, lz, block.Stone)
+			c.SetBlock(lx, VoidPlatformY, lz, block.Grass)
+			c.UpdateHeight(lx, lz)
+		}
+	}
+}
+
+// SpawnPoint returns the middle of the platform
+func (v *VoidGenerator) SpawnPoint() (int, int) {
+	return 0, 0
+}
@@ -0,0 +1,707 @@
+// Package terrain provides template-based villages and ruins
+package terrain
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/core/chunk"
+	vmath "voxelgame/pkg/math"
+)
+
+//go:embed settlements.json
+var defaultSettlementsJSON []byte
+
+// Settlement placement
+const (
+	// SettlementSpacing is the size in chunks of the regions settlements
+	// are spread over. Each region holds at most one settlement.
+	SettlementSpacing = 16
+
+	// SettlementReach is how many chunks a settlement may extend past the
+	// chunk its first piece is centered in
+	SettlementReach = 4
+
+	// Largest height difference under a building or along a path
+	maxPieceSlope = 4
+	maxPathSlope  = 3
+
+	// Trees keep this many blocks away from settlement pieces
+	settlementClearance = 2
+)
+
+// facings are the sides of a piece, clockwise from north (-Z)
+var facings = [4]string{"north", "east", "south", "west"}
+
+// facingSteps are the unit steps out of each side of a piece
+var facingSteps = [4][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+
+// Connector is a point on the side of a piece another piece attaches to
+type Connector struct {
+	X      int    `json:"x"`
+	Z      int    `json:"z"`
+	Facing string `json:"facing"` // Side of the piece: north, east, south or west
+	Pool   string `json:"pool"`   // Pool of pieces attached here, none if empty
+
+	facing int
+}
+
+// Piece is a structure template joined to other pieces by connectors
+type Piece struct {
+	Name string `json:"name"`
+
+	// Layers hold the blocks from the bottom up, each as rows along Z of
+	// palette characters along X. Layer 0 replaces the surface and a space
+	// leaves the terrain as it is.
+	Layers     [][]string  `json:"layers"`
+	Connectors []Connector `json:"connectors"`
+
+	// Follow places every column on the terrain below it instead of
+	// levelling the piece, for paths
+	Follow bool `json:"follow"`
+
+	width, depth int
+}
+
+// SettlementType describes a village or ruin and where it appears
+type SettlementType struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"`      // FeatureVillage or FeatureRuin
+	Biomes    []string `json:"biomes"`    // Biomes the first piece may stand in
+	Chance    float64  `json:"chance"`    // Chance per region starting in one of the biomes
+	Start     string   `json:"start"`     // Pool of the first piece
+	Depth     int      `json:"depth"`     // Most connectors between the first piece and any other
+	Integrity float64  `json:"integrity"` // Share of blocks kept above the floor, below 1 for ruins
+
+	// Materials palette names stand for, other names are block keys. The
+	// foundation fills the gap under levelled pieces.
+	Materials map[string]block.Type `json:"materials"`
+
+	palette    [256]block.Type
+	foundation block.Type
+}
+
+// SettlementTable holds the pieces and settlement types a generator places
+type SettlementTable struct {
+	pieces map[string]*Piece
+	pools  map[string][]Weighted
+	types  []*SettlementType
+}
+
+// settlementFile is the JSON layout of a settlement table
+type settlementFile struct {
+	Palette     map[string]string     `json:"palette"`
+	Pieces      []*Piece              `json:"pieces"`
+	Pools       map[string][]Weighted `json:"pools"`
+	Settlements []*SettlementType     `json:"settlements"`
+}
+
+// defaultSettlements is parsed once, tables are never modified
+var defaultSettlements = func() *SettlementTable {
+	t, err := LoadSettlements(bytes.NewReader(defaultSettlementsJSON))
+	if err != nil {
+		panic(fmt.Sprintf("terrain: invalid built-in settlements: %v", err))
+	}
+	return t
+}()
+
+// DefaultSettlements returns the built-in settlement table
+func DefaultSettlements() *SettlementTable {
+	return defaultSettlements
+}
+
+// LoadSettlements reads a settlement table from JSON
+func LoadSettlements(r io.Reader) (*SettlementTable, error) {
+	var file settlementFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, err
+	}
+	return newSettlementTable(&file)
+}
+
+// newSettlementTable validates a settlement file and resolves its names
+func newSettlementTable(file *settlementFile) (*SettlementTable, error) {
+	t := &SettlementTable{
+		pieces: make(map[string]*Piece, len(file.Pieces)),
+		pools:  file.Pools,
+		types:  file.Settlements,
+	}
+
+	for key := range file.Palette {
+		if len(key) != 1 || key == " " {
+			return nil, fmt.Errorf("palette key %q must be a single character other than a space", key)
+		}
+	}
+
+	for _, p := range file.Pieces {
+		if p.Name == "" {
+			return nil, fmt.Errorf("piece without a name")
+		}
+		if t.pieces[p.Name] != nil {
+			return nil, fmt.Errorf("piece %q defined twice", p.Name)
+		}
+		if err := p.validate(file); err != nil {
+			return nil, fmt.Errorf("piece %q: %w", p.Name, err)
+		}
+		t.pieces[p.Name] = p
+	}
+
+	for name, pool := range t.pools {
+		if len(pool) == 0 {
+			return nil, fmt.Errorf("pool %q is empty", name)
+		}
+		for _, e := range pool {
+			if t.pieces[e.Name] == nil {
+				return nil, fmt.Errorf("pool %q: unknown piece %q", name, e.Name)
+			}
+			if e.Weight <= 0 {
+				return nil, fmt.Errorf("pool %q: piece %q needs a positive weight", name, e.Name)
+			}
+		}
+	}
+
+	names := make(map[string]bool, len(t.types))
+	for _, s := range t.types {
+		if s.Name == "" {
+			return nil, fmt.Errorf("settlement without a name")
+		}
+		if names[s.Name] {
+			return nil, fmt.Errorf("settlement %q defined twice", s.Name)
+		}
+		names[s.Name] = true
+		if err := s.validate(file); err != nil {
+			return nil, fmt.Errorf("settlement %q: %w", s.Name, err)
+		}
+	}
+	return t, nil
+}
+
+// validate checks a piece's layers and connectors and records its size
+func (p *Piece) validate(file *settlementFile) error {
+	if len(p.Layers) == 0 || len(p.Layers[0]) == 0 || len(p.Layers[0][0]) == 0 {
+		return fmt.Errorf("no blocks")
+	}
+	if len(p.Layers) >= chunk.Height {
+		return fmt.Errorf("taller than a chunk")
+	}
+	p.depth = len(p.Layers[0])
+	p.width = len(p.Layers[0][0])
+
+	for y, layer := range p.Layers {
+		if len(layer) != p.depth {
+			return fmt.Errorf("layer %d has %d rows, want %d", y, len(layer), p.depth)
+		}
+		for z, row := range layer {
+			if len(row) != p.width {
+				return fmt.Errorf("layer %d row %d has %d blocks, want %d", y, z, len(row), p.width)
+			}
+			for i := 0; i < len(row); i++ {
+				if _, ok := file.Palette[row[i:i+1]]; !ok && row[i] != ' ' {
+					return fmt.Errorf("layer %d row %d: %q is not in the palette", y, z, row[i])
+				}
+			}
+		}
+	}
+
+	for i := range p.Connectors {
+		c := &p.Connectors[i]
+		c.facing = -1
+		for f, name := range facings {
+			if c.Facing == name {
+				c.facing = f
+			}
+		}
+		if c.facing < 0 {
+			return fmt.Errorf("connector %d: unknown facing %q", i, c.Facing)
+		}
+
+		// Connectors sit on the side they face
+		onSide := [4]bool{c.Z == 0, c.X == p.width-1, c.Z == p.depth-1, c.X == 0}
+		if c.X < 0 || c.X >= p.width || c.Z < 0 || c.Z >= p.depth || !onSide[c.facing] {
+			return fmt.Errorf("connector %d is not on the %s side", i, c.Facing)
+		}
+		if _, ok := file.Pools[c.Pool]; c.Pool != "" && !ok {
+			return fmt.Errorf("connector %d: unknown pool %q", i, c.Pool)
+		}
+	}
+	return nil
+}
+
+// validate checks a settlement type and resolves its palette
+func (s *SettlementType) validate(file *settlementFile) error {
+	if s.Kind != FeatureVillage && s.Kind != FeatureRuin {
+		return fmt.Errorf("unknown kind %q", s.Kind)
+	}
+	if s.Chance < 0 || s.Chance > 1 {
+		return fmt.Errorf("chance must lie within [0, 1]")
+	}
+	if _, ok := file.Pools[s.Start]; !ok {
+		return fmt.Errorf("unknown start pool %q", s.Start)
+	}
+	if s.Depth < 0 {
+		return fmt.Errorf("depth must not be negative")
+	}
+	if s.Integrity <= 0 || s.Integrity > 1 {
+		return fmt.Errorf("integrity must lie within (0, 1]")
+	}
+
+	var ok bool
+	if s.foundation, ok = s.Materials["foundation"]; !ok {
+		return fmt.Errorf("no foundation material")
+	}
+	for key, name := range file.Palette {
+		t, ok := s.Materials[name]
+		if !ok {
+			var err error
+			if t, err = block.ParseKey(name); err != nil {
+				return fmt.Errorf("no material %q", name)
+			}
+		}
+		s.palette[key[0]] = t
+	}
+	return nil
+}
+
+// inBiome reports whether a settlement may start in a biome
+func (s *SettlementType) inBiome(b *Biome) bool {
+	for _, name := range s.Biomes {
+		if name == b.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// Types returns the settlement types in the order they are tried
+func (t *SettlementTable) Types() []*SettlementType {
+	return t.types
+}
+
+// size returns the footprint of a piece turned by quarter turns
+func (p *Piece) size(rotation int) (int, int) {
+	if rotation%2 == 1 {
+		return p.depth, p.width
+	}
+	return p.width, p.depth
+}
+
+// rotate turns a cell of a piece by quarter turns clockwise
+func (p *Piece) rotate(x, z, rotation int) (int, int) {
+	switch rotation {
+	case 1:
+		return p.depth - 1 - z, x
+	case 2:
+		return p.width - 1 - x, p.depth - 1 - z
+	case 3:
+		return z, p.width - 1 - x
+	}
+	return x, z
+}
+
+// Settlement is a village or ruin placed in the world
+type Settlement struct {
+	Name    string // Settlement type
+	Kind    string // FeatureVillage or FeatureRuin
+	X, Y, Z int    // Center of the first piece, on its floor
+
+	// Bounding box of all blocks placed, inclusive
+	MinX, MinY, MinZ int
+	MaxX, MaxY, MaxZ int
+}
+
+// settlementPiece is a piece placed in world coordinates
+type settlementPiece struct {
+	piece    *Piece
+	rotation int // Quarter turns clockwise
+	y        int // Height of layer 0, unless the piece follows the terrain
+
+	minX, minZ, maxX, maxZ int // Footprint, inclusive
+}
+
+// settlementPlan holds the settlement of one region, if it has one
+type settlementPlan struct {
+	settlement *Settlement
+	start      chunkPos // Chunk the first piece is centered in
+	pieces     []settlementPiece
+	writes     blockWrites
+}
+
+// set plans a block in world coordinates
+func (p *settlementPlan) set(wx, wy, wz int, t block.Type) {
+	if wy < 1 || wy >= chunk.Height {
+		return
+	}
+	p.writes.add(structureBlock{wx, wy, wz, t, false, nil})
+
+	s := p.settlement
+	s.MinX, s.MaxX = min(s.MinX, wx), max(s.MaxX, wx)
+	s.MinY, s.MaxY = min(s.MinY, wy), max(s.MaxY, wy)
+	s.MinZ, s.MaxZ = min(s.MinZ, wz), max(s.MaxZ, wz)
+}
+
+// near reports whether a column lies within margin blocks of a piece
+func (p *settlementPlan) near(wx, wz, margin int) bool {
+	for _, sp := range p.pieces {
+		if wx >= sp.minX-margin && wx <= sp.maxX+margin && wz >= sp.minZ-margin && wz <= sp.maxZ+margin {
+			return true
+		}
+	}
+	return false
+}
+
+// settlementPlan returns the settlement of a region
+func (g *Generator) settlementPlan(rx, rz int) *settlementPlan {
+	pos := chunkPos{rx, rz}
+	if p, ok := g.settlements.get(pos); ok {
+		return p
+	}
+	p := g.planSettlement(rx, rz)
+	g.settlements.put(pos, p)
+	return p
+}
+
+// settlementsNear returns the settlements within settlementClearance
+// blocks of a chunk, in a fixed order
+func (g *Generator) settlementsNear(cx, cz int) []*settlementPlan {
+	return g.settlementsWithin(cx, cz, settlementClearance)
+}
+
+// settlementsWithin returns the settlements within margin blocks of a
+// chunk, in a fixed order
+func (g *Generator) settlementsWithin(cx, cz, margin int) []*settlementPlan {
+	minX := cx*chunk.Size - margin
+	minZ := cz*chunk.Size - margin
+	maxX := minX + chunk.Size + 2*margin - 1
+	maxZ := minZ + chunk.Size + 2*margin - 1
+
+	var plans []*settlementPlan
+	forRegions(cx, cz, SettlementSpacing, SettlementReach, func(rx, rz int) {
+		p := g.settlementPlan(rx, rz)
+		s := p.settlement
+		if s != nil && s.MinX <= maxX && s.MaxX >= minX && s.MinZ <= maxZ && s.MaxZ >= minZ {
+			plans = append(plans, p)
+		}
+	})
+	return plans
+}
+
+// nearSettlement reports whether a column lies within margin blocks of a
+// piece of any of the settlements
+func nearSettlement(plans []*settlementPlan, wx, wz, margin int) bool {
+	for _, p := range plans {
+		if p.near(wx, wz, margin) {
+			return true
+		}
+	}
+	return false
+}
+
+// applySettlements writes the villages and ruins reaching into a chunk
+func (g *Generator) applySettlements(c *chunk.Chunk) {
+	var touched [chunk.Size * chunk.Size]bool
+	for _, p := range g.settlementsNear(int(c.CX), int(c.CZ)) {
+		p.writes.apply(c, &touched)
+	}
+	// Pieces clear the terrain above them, which SetBlock doesn't track
+	updateHeights(c, &touched)
+}
+
+// planSettlement picks the settlement of a region, if any, and grows it
+// piece by piece from its first piece. Like other plans it depends only on
+// the seed, settings and base terrain.
+func (g *Generator) planSettlement(rx, rz int) *settlementPlan {
+	p := &settlementPlan{}
+	rng := g.random.Split(PassSettlements).At(rx, rz)
+
+	cx := rx*SettlementSpacing + rng.NextInt(0, SettlementSpacing-1)
+	cz := rz*SettlementSpacing + rng.NextInt(0, SettlementSpacing-1)
+	wx := cx*chunk.Size + rng.NextInt(0, chunk.Size-1)
+	wz := cz*chunk.Size + rng.NextInt(0, chunk.Size-1)
+
+	biome := g.getColumn(wx, wz).dominant
+	var st *SettlementType
+	scale := g.Pipeline.Config(PassSettlements).Get("chance", 1)
+	for _, t := range g.Settlements.Types() {
+		if t.inBiome(biome) && rng.Next() < t.Chance*scale {
+			st = t
+			break
+		}
+	}
+	if st == nil {
+		return p
+	}
+
+	l := &settlementLayout{
+		g:       g,
+		table:   g.Settlements,
+		st:      st,
+		rng:     rng,
+		origin:  chunkPos{cx, cz},
+		columns: make(map[[2]int]column),
+	}
+	if !l.grow(wx, wz) {
+		return p
+	}
+
+	first := l.pieces[0]
+	p.start = chunkPos{vmath.FloorDiv(wx, chunk.Size), vmath.FloorDiv(wz, chunk.Size)}
+	p.pieces = l.pieces
+	p.writes = make(blockWrites)
+	p.settlement = &Settlement{
+		Name: st.Name,
+		Kind: st.Kind,
+		X:    (first.minX + first.maxX) / 2,
+		Y:    first.y,
+		Z:    (first.minZ + first.maxZ) / 2,
+		MinX: math.MaxInt, MinY: math.MaxInt, MinZ: math.MaxInt,
+		MaxX: math.MinInt, MaxY: math.MinInt, MaxZ: math.MinInt,
+	}
+	for _, sp := range l.pieces {
+		l.place(p, sp)
+	}
+	return p
+}
+
+// openConnector is a connector of a placed piece waiting for a neighbour
+type openConnector struct {
+	x, z   int // Cell of the connector in world coordinates
+	facing int
+	pool   string
+	depth  int // Connectors between it and the first piece
+}
+
+// settlementLayout joins the pieces of a settlement while it is planned
+type settlementLayout struct {
+	g      *Generator
+	table  *SettlementTable
+	st     *SettlementType
+	rng    *vmath.SeededRNG
+	origin chunkPos // Chunk the settlement starts in
+	pieces []settlementPiece
+
+	// Base terrain looked at so far, pieces often test the same columns
+	columns map[[2]int]column
+}
+
+// column returns the base terrain of a column
+func (l *settlementLayout) column(wx, wz int) column {
+	key := [2]int{wx, wz}
+	col, ok := l.columns[key]
+	if !ok {
+		col = l.g.getColumn(wx, wz)
+		l.columns[key] = col
+	}
+	return col
+}
+
+// grow places the first piece centered on a position, then attaches pieces
+// to open connectors breadth first until the pools or depth run out.
+// It returns false if the first piece doesn't fit.
+func (l *settlementLayout) grow(wx, wz int) bool {
+	piece := l.table.pieces[pickWeighted(l.table.pools[l.st.Start], l.rng.Next())]
+	rotation := l.rng.NextInt(0, 3)
+	w, d := piece.size(rotation)
+	first, ok := l.fit(piece, rotation, wx-w/2, wz-d/2)
+	if !ok {
+		return false
+	}
+	l.pieces = append(l.pieces, first)
+	open := l.open(first, -1, 1)
+
+	for len(open) > 0 {
+		c := open[0]
+		open = open[1:]
+		if c.depth > l.st.Depth {
+			continue
+		}
+		if next, used, ok := l.attach(c); ok {
+			l.pieces = append(l.pieces, next)
+			open = append(open, l.open(next, used, c.depth+1)...)
+		}
+	}
+	return true
+}
+
+// open returns the connectors of a placed piece that lead to a pool,
+// except the one it was attached by
+func (l *settlementLayout) open(sp settlementPiece, used, depth int) []openConnector {
+	var open []openConnector
+	for i, c := range sp.piece.Connectors {
+		if i == used || c.Pool == "" {
+			continue
+		}
+		x, z := sp.piece.rotate(c.X, c.Z, sp.rotation)
+		open = append(open, openConnector{sp.minX + x, sp.minZ + z, (c.facing + sp.rotation) % 4, c.Pool, depth})
+	}
+	return open
+}
+
+// attach tries the pieces of a connector's pool, in weighted random order,
+// in every rotation that turns one of their connectors to face it. It
+// returns the piece placed and the index of the connector it hangs from.
+func (l *settlementLayout) attach(c openConnector) (settlementPiece, int, bool) {
+	// The new piece's connector sits on the next cell, facing back
+	tx, tz := c.x+facingSteps[c.facing][0], c.z+facingSteps[c.facing][1]
+	want := (c.facing + 2) % 4
+
+	candidates := append([]Weighted(nil), l.table.pools[c.pool]...)
+	for len(candidates) > 0 {
+		name := pickWeighted(candidates, l.rng.Next())
+		for i := range candidates {
+			if candidates[i].Name == name {
+				candidates = append(candidates[:i], candidates[i+1:]...)
+				break
+			}
+		}
+
+		piece := l.table.pieces[name]
+		turn := l.rng.NextInt(0, 3)
+		for r := 0; r < 4; r++ {
+			rotation := (turn + r) % 4
+			for i, pc := range piece.Connectors {
+				if (pc.facing+rotation)%4 != want {
+					continue
+				}
+				x, z := piece.rotate(pc.X, pc.Z, rotation)
+				if sp, ok := l.fit(piece, rotation, tx-x, tz-z); ok {
+					return sp, i, true
+				}
+			}
+		}
+	}
+	return settlementPiece{}, 0, false
+}
+
+// fit checks that a piece fits at a position: within reach, clear of the
+// other pieces, on dry land without floating islands overhead and not too
+// steep. It returns the piece with its level set.
+func (l *settlementLayout) fit(piece *Piece, rotation, minX, minZ int) (settlementPiece, bool) {
+	w, d := piece.size(rotation)
+	sp := settlementPiece{piece: piece, rotation: rotation, minX: minX, minZ: minZ, maxX: minX + w - 1, maxZ: minZ + d - 1}
+
+	// Chunks don't look further than SettlementReach for settlements
+	if vmath.FloorDiv(sp.minX, chunk.Size) < l.origin.cx-SettlementReach || vmath.FloorDiv(sp.maxX, chunk.Size) > l.origin.cx+SettlementReach ||
+		vmath.FloorDiv(sp.minZ, chunk.Size) < l.origin.cz-SettlementReach || vmath.FloorDiv(sp.maxZ, chunk.Size) > l.origin.cz+SettlementReach {
+		return sp, false
+	}
+
+	for _, o := range l.pieces {
+		if sp.minX <= o.maxX && sp.maxX >= o.minX && sp.minZ <= o.maxZ && sp.maxZ >= o.minZ {
+			return sp, false
+		}
+	}
+
+	lo, hi, sum := math.MaxInt, math.MinInt, 0
+	for x := sp.minX; x <= sp.maxX; x++ {
+		for z := sp.minZ; z <= sp.maxZ; z++ {
+			col := l.column(x, z)
+			if col.ground <= l.g.Config.SeaLevel || col.height != col.ground {
+				return sp, false
+			}
+			lo, hi, sum = min(lo, col.ground), max(hi, col.ground), sum+col.ground
+		}
+	}
+
+	slope := maxPieceSlope
+	if piece.Follow {
+		slope = maxPathSlope
+	}
+	if hi-lo > slope {
+		return sp, false
+	}
+
+	// Level buildings at the average ground height
+	sp.y = int(math.Round(float64(sum) / float64(w*d)))
+	if piece.Follow {
+		sp.y = lo
+	}
+	if hi+len(piece.Layers) >= chunk.Height {
+		return sp, false
+	}
+	return sp, true
+}
+
+// place plans the blocks of a piece. Levelled pieces stand on a foundation
+// and have the terrain above them cleared; ruins lose some blocks.
+func (l *settlementLayout) place(p *settlementPlan, sp settlementPiece) {
+	piece := sp.piece
+	for pz := 0; pz < piece.depth; pz++ {
+		for px := 0; px < piece.width; px++ {
+			rx, rz := piece.rotate(px, pz, sp.rotation)
+			wx, wz := sp.minX+rx, sp.minZ+rz
+			ground := l.column(wx, wz).ground
+
+			base := sp.y
+			if piece.Follow {
+				base = ground
+			}
+
+			for y, layer := range piece.Layers {
+				ch := layer[pz][px]
+				if ch == ' ' {
+					continue
+				}
+				t := l.st.palette[ch]
+				if y > 0 && t != block.Air && l.st.Integrity < 1 && l.rng.Next() >= l.st.Integrity {
+					t = block.Air
+				}
+				p.set(wx, base+y, wz, t)
+			}
+
+			if piece.Follow {
+				continue
+			}
+			if floor := piece.Layers[0][pz][px]; floor != ' ' && l.st.palette[floor] != block.Air {
+				for y := ground + 1; y < base; y++ {
+					p.set(wx, y, wz, l.st.foundation)
+				}
+			}
+			for y := base + len(piece.Layers); y <= ground; y++ {
+				p.set(wx, y, wz, block.Air)
+			}
+		}
+	}
+}
+
+// Locate finds the settlement nearest to world coordinates whose type name
+// or kind matches, at most radius blocks away. An empty name matches any
+// settlement. Only the regions searched are planned, no chunks generate.
+func (g *Generator) Locate(name string, wx, wz, radius int) (Settlement, bool) {
+	regionSize := SettlementSpacing * chunk.Size
+	rx0, rz0 := vmath.FloorDiv(wx, regionSize), vmath.FloorDiv(wz, regionSize)
+
+	var best *Settlement
+	bestDist := float64(radius)
+	for ring := 0; ; ring++ {
+		// Settlements in this ring and beyond are at least this far away
+		if float64((ring-1)*regionSize) > bestDist {
+			break
+		}
+		for rz := rz0 - ring; rz <= rz0+ring; rz++ {
+			for rx := rx0 - ring; rx <= rx0+ring; rx++ {
+				if abs(rx-rx0) != ring && abs(rz-rz0) != ring {
+					continue
+				}
+				s := g.settlementPlan(rx, rz).settlement
+				if s == nil || (name != "" && s.Name != name && s.Kind != name) {
+					continue
+				}
+				if dist := math.Hypot(float64(s.X-wx), float64(s.Z-wz)); dist <= bestDist {
+					best, bestDist = s, dist
+				}
+			}
+		}
+	}
+
+	if best == nil {
+		return Settlement{}, false
+	}
+	return *best, true
+}
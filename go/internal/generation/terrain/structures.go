@@ -0,0 +1,398 @@
+// Package terrain provides structure placement across chunk borders
+package terrain
+
+import (
+	"sync"
+
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/core/chunk"
+	vmath "voxelgame/pkg/math"
+)
+
+// StructureReach is how many chunks a structure may extend past the chunk
+// it is planned in. Each chunk collects blocks from all structures planned
+// within this distance, so raising it makes generation slower.
+const StructureReach = 1
+
+// planCacheSize is the number of planned chunks kept in memory per cache
+const planCacheSize = 256
+
+// structureStage orders structure blocks relative to the other passes
+type structureStage int
+
+const (
+	stageOres       structureStage = iota // Ore veins
+	stageVegetation                       // Trees and cacti
+	stageWaterfalls                       // Waterfalls and their lakes
+	stageCount
+)
+
+// chunkPos identifies a chunk by its chunk coordinates
+type chunkPos struct {
+	cx, cz int
+}
+
+// structureBlock is one planned block in world coordinates
+type structureBlock struct {
+	x, y, z int
+	t       block.Type
+	onlyAir bool         // Only fills air, e.g. leaves and falling water
+	hosts   []block.Type // Only replaces these blocks if set, e.g. ore in stone
+}
+
+// plannedFeature is a feature reported once its chunk generates
+type plannedFeature struct {
+	kind    string
+	x, y, z int
+}
+
+// structurePlan holds the structures starting in one chunk. Blocks are
+// grouped by the chunk they land in; each chunk picks up its share when it
+// generates, so writes into neighbours are deferred until they exist.
+type structurePlan struct {
+	origin   chunkPos
+	writes   [stageCount]map[chunkPos][]structureBlock
+	features []plannedFeature
+}
+
+// set plans a block in world coordinates
+func (p *structurePlan) set(stage structureStage, wx, wy, wz int, t block.Type, onlyAir bool) {
+	p.add(stage, structureBlock{wx, wy, wz, t, onlyAir, nil})
+}
+
+// setHosted plans a block that only replaces one of the host blocks
+func (p *structurePlan) setHosted(stage structureStage, wx, wy, wz int, t block.Type, hosts []block.Type) {
+	p.add(stage, structureBlock{wx, wy, wz, t, false, hosts})
+}
+
+// add plans a structure block
+func (p *structurePlan) add(stage structureStage, b structureBlock) {
+	wx, wy, wz := b.x, b.y, b.z
+	if wy < 0 || wy >= chunk.Height {
+		return
+	}
+	target := chunkPos{vmath.FloorDiv(wx, chunk.Size), vmath.FloorDiv(wz, chunk.Size)}
+
+	// Chunks don't look further than StructureReach for structures
+	if abs(target.cx-p.origin.cx) > StructureReach || abs(target.cz-p.origin.cz) > StructureReach {
+		return
+	}
+
+	if p.writes[stage] == nil {
+		p.writes[stage] = make(map[chunkPos][]structureBlock)
+	}
+	p.writes[stage][target] = append(p.writes[stage][target], b)
+}
+
+// blockWrites holds planned blocks grouped by the chunk they land in, for
+// plans that span more chunks than StructureReach
+type blockWrites map[chunkPos][]structureBlock
+
+// add plans a block in world coordinates
+func (w blockWrites) add(b structureBlock) {
+	if b.y < 1 || b.y >= chunk.Height {
+		return
+	}
+	target := chunkPos{vmath.FloorDiv(b.x, chunk.Size), vmath.FloorDiv(b.z, chunk.Size)}
+	w[target] = append(w[target], b)
+}
+
+// apply writes the planned blocks landing in a chunk. Blocks may replace
+// the top of a column, so touched columns have their height updated.
+func (w blockWrites) apply(c *chunk.Chunk, touched *[chunk.Size * chunk.Size]bool) {
+	startX := int(c.CX) * chunk.Size
+	startZ := int(c.CZ) * chunk.Size
+	for _, b := range w[chunkPos{int(c.CX), int(c.CZ)}] {
+		lx, lz := b.x-startX, b.z-startZ
+		c.SetBlock(lx, b.y, lz, b.t)
+		touched[lx+lz*chunk.Size] = true
+	}
+}
+
+// updateHeights recomputes the height map of the columns written to
+func updateHeights(c *chunk.Chunk, touched *[chunk.Size * chunk.Size]bool) {
+	for i, t := range touched {
+		if t {
+			c.UpdateHeight(i%chunk.Size, i/chunk.Size)
+		}
+	}
+}
+
+// forRegions calls fn with every region of spacing chunks whose plans may
+// reach reach chunks into a chunk, in a fixed order
+func forRegions(cx, cz, spacing, reach int, fn func(rx, rz int)) {
+	for rz := vmath.FloorDiv(cz-reach, spacing); rz <= vmath.FloorDiv(cz+reach, spacing); rz++ {
+		for rx := vmath.FloorDiv(cx-reach, spacing); rx <= vmath.FloorDiv(cx+reach, spacing); rx++ {
+			fn(rx, rz)
+		}
+	}
+}
+
+// feature records a feature at a world position
+func (p *structurePlan) feature(kind string, wx, wy, wz int) {
+	p.features = append(p.features, plannedFeature{kind, wx, wy, wz})
+}
+
+// planCache keeps recently planned chunks, since every plan is needed
+// by all chunks it reaches into
+type planCache[P any] struct {
+	mu    sync.Mutex
+	plans map[chunkPos]P
+	order []chunkPos
+}
+
+// get returns a cached plan
+func (sc *planCache[P]) get(pos chunkPos) (P, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	p, ok := sc.plans[pos]
+	return p, ok
+}
+
+// put caches a plan, evicting the oldest one when full
+func (sc *planCache[P]) put(pos chunkPos, p P) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if sc.plans == nil {
+		sc.plans = make(map[chunkPos]P)
+	}
+	if _, ok := sc.plans[pos]; ok {
+		return
+	}
+	if len(sc.order) >= planCacheSize {
+		delete(sc.plans, sc.order[0])
+		sc.order = sc.order[1:]
+	}
+	sc.plans[pos] = p
+	sc.order = append(sc.order, pos)
+}
+
+// reset drops all plans, after settings that shape terrain changed
+func (sc *planCache[P]) reset() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.plans = nil
+	sc.order = nil
+}
+
+// structurePlan returns the structures starting in a chunk. Plans depend
+// only on the seed and settings, so the same blocks land in a chunk no
+// matter which of its neighbours generated first.
+func (g *Generator) structurePlan(cx, cz int) *structurePlan {
+	pos := chunkPos{cx, cz}
+	if p, ok := g.structures.get(pos); ok {
+		return p
+	}
+	p := g.planStructures(cx, cz)
+	g.structures.put(pos, p)
+	return p
+}
+
+// applyStructures writes a stage of all structures reaching into a chunk.
+// Plans are visited in a fixed order so overlapping structures always
+// resolve the same way.
+func (g *Generator) applyStructures(c *chunk.Chunk, stage structureStage) {
+	cx, cz := int(c.CX), int(c.CZ)
+	target := chunkPos{cx, cz}
+	startX := cx * chunk.Size
+	startZ := cz * chunk.Size
+
+	for oz := cz - StructureReach; oz <= cz+StructureReach; oz++ {
+		for ox := cx - StructureReach; ox <= cx+StructureReach; ox++ {
+			for _, b := range g.structurePlan(ox, oz).writes[stage][target] {
+				lx, lz := b.x-startX, b.z-startZ
+				if b.onlyAir && c.GetBlock(lx, b.y, lz) != block.Air {
+					continue
+				}
+				if b.hosts != nil && !hosts(b.hosts, c.GetBlock(lx, b.y, lz)) {
+					continue
+				}
+				c.SetBlock(lx, b.y, lz, b.t)
+			}
+		}
+	}
+}
+
+// hosts reports whether a block is one of the host blocks
+func hosts(hosts []block.Type, t block.Type) bool {
+	for _, h := range hosts {
+		if h == t {
+			return true
+		}
+	}
+	return false
+}
+
+// reportFeatures calls OnFeature for the features planned in a chunk and
+// the settlements and dungeons centered in it, if their passes are enabled
+func (g *Generator) reportFeatures(c *chunk.Chunk) {
+	if g.OnFeature == nil {
+		return
+	}
+	if g.Pipeline.Enabled(PassWaterfalls) {
+		for _, f := range g.structurePlan(int(c.CX), int(c.CZ)).features {
+			g.OnFeature(f.kind, f.x, f.y, f.z)
+		}
+	}
+	if g.Pipeline.Enabled(PassSettlements) {
+		for _, p := range g.settlementsNear(int(c.CX), int(c.CZ)) {
+			if s := p.settlement; p.start == (chunkPos{int(c.CX), int(c.CZ)}) {
+				g.OnFeature(s.Kind, s.X, s.Y, s.Z)
+			}
+		}
+	}
+	if g.Pipeline.Enabled(PassDungeons) {
+		for _, p := range g.dungeonsNear(int(c.CX), int(c.CZ)) {
+			if p.start == (chunkPos{int(c.CX), int(c.CZ)}) {
+				g.OnFeature(FeatureDungeon, p.x, p.y, p.z)
+			}
+		}
+	}
+}
+
+// planStructures plans all structures starting in a chunk
+func (g *Generator) planStructures(cx, cz int) *structurePlan {
+	p := &structurePlan{origin: chunkPos{cx, cz}}
+	startX := cx * chunk.Size
+	startZ := cz * chunk.Size
+
+	var columns [chunk.Size * chunk.Size]column
+	for lx := 0; lx < chunk.Size; lx++ {
+		for lz := 0; lz < chunk.Size; lz++ {
+			columns[lx+lz*chunk.Size] = g.getColumn(startX+lx, startZ+lz)
+		}
+	}
+
+	g.planOres(p, cx, cz, columns[:])
+	g.planVegetation(p, cx, cz, columns[:])
+	g.planWaterfall(p, cx, cz, columns[:])
+	return p
+}
+
+// planVegetation plans trees and cacti
+func (g *Generator) planVegetation(p *structurePlan, cx, cz int, columns []column) {
+	chunkRng := g.random.Split(PassVegetation).At(cx, cz)
+	settlements := g.settlementsWithin(cx, cz, settlementClearance+maxTreeReach)
+
+	// Ground height of columns, for trees lying on it
+	heightAt := func(wx, wz int) int {
+		if vmath.FloorDiv(wx, chunk.Size) == cx && vmath.FloorDiv(wz, chunk.Size) == cz {
+			return columns[wx-cx*chunk.Size+(wz-cz*chunk.Size)*chunk.Size].height
+		}
+		return g.getColumn(wx, wz).height
+	}
+
+	// Trees - scale chance by tree density config
+	// Base chance is for density 0.05. Scaling: config / 0.05
+	densityMultiplier := float64(g.Config.TreeDensity/0.05) * g.Pipeline.Config(PassVegetation).Get("density", 1)
+
+	for lx := 0; lx < chunk.Size; lx++ {
+		for lz := 0; lz < chunk.Size; lz++ {
+			wx := cx*chunk.Size + lx
+			wz := cz*chunk.Size + lz
+			col := columns[lx+lz*chunk.Size]
+
+			// Nothing grows in or over villages and ruins
+			if col.height <= g.Config.SeaLevel || nearSettlement(settlements, wx, wz, settlementClearance) {
+				continue
+			}
+
+			vegetation := col.biome.Vegetation
+
+			if vegetation.TreeChance > 0 && chunkRng.Next() < vegetation.TreeChance*densityMultiplier {
+				species := g.Trees.Get(pickWeighted(vegetation.Trees, chunkRng.Next()))
+				if species != nil && !nearSettlement(settlements, wx, wz, settlementClearance+species.reach()) {
+					p.planTree(species, wx, col.height+1, wz, chunkRng, heightAt)
+				}
+			}
+
+			// Cacti
+			if vegetation.CactusChance > 0 && chunkRng.Next() < vegetation.CactusChance {
+				p.cactus(wx, col.height+1, wz, chunkRng)
+			}
+		}
+	}
+}
+
+// cactus plans a cactus
+func (p *structurePlan) cactus(wx, wy, wz int, rng *vmath.SeededRNG) {
+	height := 2 + rng.NextInt(0, 2)
+
+	for i := 0; i < height; i++ {
+		p.set(stageVegetation, wx, wy+i, wz, block.Cactus, false)
+	}
+}
+
+// planWaterfall plans a waterfall down a cliff in biomes that allow them
+func (g *Generator) planWaterfall(p *structurePlan, cx, cz int, columns []column) {
+	chunkRng := g.random.Split(PassWaterfalls).At(cx, cz)
+
+	// 15% chance per chunk by default
+	if chunkRng.Next() > g.Pipeline.Config(PassWaterfalls).Get("chance", 0.15) {
+		return
+	}
+
+	for lx := 3; lx < chunk.Size-3; lx++ {
+		for lz := 3; lz < chunk.Size-3; lz++ {
+			wx := cx*chunk.Size + lx
+			wz := cz*chunk.Size + lz
+			col := columns[lx+lz*chunk.Size]
+
+			if !col.dominant.Waterfalls {
+				continue
+			}
+
+			height := col.height
+			if height < 25 {
+				continue
+			}
+
+			// Check for cliffs
+			directions := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+			for _, dir := range directions {
+				neighborHeight := columns[(lx+dir[0]*2)+(lz+dir[1]*2)*chunk.Size].height
+				heightDiff := height - neighborHeight
+
+				if heightDiff >= 5 && chunkRng.Next() < 0.3 {
+					// Place water source at top
+					p.set(stageWaterfalls, wx, height, wz, block.Water, false)
+
+					// Create cascade
+					currentY := height - 1
+					currentX := wx + dir[0]
+					currentZ := wz + dir[1]
+
+					for currentY > neighborHeight && currentY > g.Config.SeaLevel {
+						p.set(stageWaterfalls, currentX, currentY, currentZ, block.Water, true)
+						currentY--
+					}
+
+					// Create a lake at the base
+					p.lake(stageWaterfalls, currentX, neighborHeight, currentZ, 3, block.Water)
+					p.feature(FeatureWaterfall, wx, height, wz)
+
+					// Only one waterfall per chunk
+					return
+				}
+			}
+		}
+	}
+}
+
+// lake plans a small circular pool
+func (p *structurePlan) lake(stage structureStage, wx, wy, wz, radius int, liquid block.Type) {
+	for dx := -radius; dx <= radius; dx++ {
+		for dz := -radius; dz <= radius; dz++ {
+			if dx*dx+dz*dz <= radius*radius {
+				// Carve out a bit of the shore if needed, and fill with liquid
+				for dy := -1; dy <= 0; dy++ {
+					if wy+dy > 0 {
+						p.set(stage, wx+dx, wy+dy, wz+dz, liquid, false)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,325 @@
+// Package terrain provides data-driven tree species
+package terrain
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/core/chunk"
+	vmath "voxelgame/pkg/math"
+)
+
+//go:embed trees.json
+var defaultTreesJSON []byte
+
+// Tree shapes
+const (
+	TreeShapeBlob      = "blob"      // Straight trunk under a round crown
+	TreeShapeBranching = "branching" // Trunk forking into branches, each ending in a leaf cluster
+	TreeShapeConical   = "conical"   // Tiers of leaves narrowing towards the top
+	TreeShapeDead      = "dead"      // Bare trunk with a few stubby branches
+	TreeShapeFallen    = "fallen"    // Log lying on the ground beside its stump
+)
+
+// maxTreeReach is how far a tree may spread from its trunk, keeping it
+// within StructureReach chunks of the chunk it is planned in
+const maxTreeReach = chunk.Size * StructureReach
+
+// TreeSpecies describes how a kind of tree grows. Biomes pick species by
+// name from their vegetation's weighted tree table.
+type TreeSpecies struct {
+	Name   string     `json:"name"`
+	Shape  string     `json:"shape"`  // One of the TreeShape constants
+	Log    block.Type `json:"log"`    // Trunk and branches
+	Leaves block.Type `json:"leaves"` // None for bare shapes
+
+	Height CountRange `json:"height"` // Trunk height, or log length when fallen
+	Trunk  int        `json:"trunk"`  // Trunk width, 1 if unset or 2 for giants
+	Crown  int        `json:"crown"`  // Radius of the crown or leaf clusters
+
+	// Branches leave the upper trunk and fork Forks times, halving
+	// their length each time
+	Branches CountRange `json:"branches"`
+	Forks    int        `json:"forks"`
+}
+
+// TreeTable holds the tree species of a generator
+type TreeTable struct {
+	species map[string]*TreeSpecies
+}
+
+// treeFile is the JSON layout of a tree table
+type treeFile struct {
+	Species []*TreeSpecies `json:"species"`
+}
+
+// defaultTrees is parsed once, tables are never modified
+var defaultTrees = func() *TreeTable {
+	t, err := LoadTrees(bytes.NewReader(defaultTreesJSON))
+	if err != nil {
+		panic(fmt.Sprintf("terrain: invalid built-in tree species: %v", err))
+	}
+	return t
+}()
+
+// DefaultTrees returns the built-in tree species
+func DefaultTrees() *TreeTable {
+	return defaultTrees
+}
+
+// LoadTrees reads tree species from JSON
+func LoadTrees(r io.Reader) (*TreeTable, error) {
+	var file treeFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, err
+	}
+	return NewTreeTable(file.Species)
+}
+
+// NewTreeTable validates tree species. Biomes naming a species missing
+// from the table grow nothing in its place.
+func NewTreeTable(species []*TreeSpecies) (*TreeTable, error) {
+	t := &TreeTable{species: make(map[string]*TreeSpecies, len(species))}
+	for _, s := range species {
+		if s.Name == "" {
+			return nil, fmt.Errorf("tree species without a name")
+		}
+		if t.species[s.Name] != nil {
+			return nil, fmt.Errorf("tree species %q defined twice", s.Name)
+		}
+		switch s.Shape {
+		case TreeShapeBlob, TreeShapeBranching, TreeShapeConical:
+			if s.Leaves == block.Air {
+				return nil, fmt.Errorf("tree species %q: %s trees need leaves", s.Name, s.Shape)
+			}
+		case TreeShapeDead, TreeShapeFallen:
+		default:
+			return nil, fmt.Errorf("tree species %q: unknown shape %q", s.Name, s.Shape)
+		}
+		if s.Log == block.Air {
+			return nil, fmt.Errorf("tree species %q has no log", s.Name)
+		}
+		if s.Height.Min < 1 || s.Height.Min > s.Height.Max || s.Height.Max > chunk.Height/2 {
+			return nil, fmt.Errorf("tree species %q: height must lie within [1, %d]", s.Name, chunk.Height/2)
+		}
+		if s.Trunk == 0 {
+			s.Trunk = 1
+		}
+		if s.Trunk < 1 || s.Trunk > 2 {
+			return nil, fmt.Errorf("tree species %q: trunk width must be 1 or 2", s.Name)
+		}
+		if s.Crown < 0 || s.Branches.Min < 0 || s.Branches.Min > s.Branches.Max || s.Forks < 0 {
+			return nil, fmt.Errorf("tree species %q: invalid crown or branches", s.Name)
+		}
+		if s.reach() > maxTreeReach {
+			return nil, fmt.Errorf("tree species %q spreads %d blocks from its trunk, at most %d fit", s.Name, s.reach(), maxTreeReach)
+		}
+		t.species[s.Name] = s
+	}
+	return t, nil
+}
+
+// Get returns a tree species, or nil if there is none with the name
+func (t *TreeTable) Get(name string) *TreeSpecies {
+	return t.species[name]
+}
+
+// reach returns how far the species may spread from its trunk
+func (s *TreeSpecies) reach() int {
+	switch s.Shape {
+	case TreeShapeFallen:
+		return s.Height.Max + 1
+	case TreeShapeBranching, TreeShapeDead:
+		// Forks add up to less than the first branch again, plus rounding
+		return s.branchLength(s.Height.Max)*2 + s.Forks + s.Crown + s.Trunk
+	default:
+		return s.Crown + s.Trunk
+	}
+}
+
+// branchLength is the length of a branch leaving a trunk of height h
+func (s *TreeSpecies) branchLength(h int) int {
+	return max(2, h/4)
+}
+
+// planTree plans a tree of a species rooted on the ground at wy. heightAt
+// gives the ground height of nearby columns, for shapes lying on it.
+func (p *structurePlan) planTree(s *TreeSpecies, wx, wy, wz int, rng *vmath.SeededRNG, heightAt func(wx, wz int) int) {
+	height := rng.NextInt(s.Height.Min, s.Height.Max)
+	switch s.Shape {
+	case TreeShapeBlob:
+		p.trunk(s, wx, wy, wz, height)
+		p.blobCrown(s, wx, wy, wz, height)
+	case TreeShapeConical:
+		p.trunk(s, wx, wy, wz, height)
+		p.conicalCrown(s, wx, wy, wz, height)
+	case TreeShapeBranching, TreeShapeDead:
+		p.trunk(s, wx, wy, wz, height)
+		p.branches(s, wx, wy, wz, height, rng)
+	case TreeShapeFallen:
+		p.fallen(s, wx, wy, wz, height, rng, heightAt)
+	}
+}
+
+// trunk plans a straight trunk, Trunk blocks wide
+func (p *structurePlan) trunk(s *TreeSpecies, wx, wy, wz, height int) {
+	for dx := 0; dx < s.Trunk; dx++ {
+		for dz := 0; dz < s.Trunk; dz++ {
+			for i := 0; i < height; i++ {
+				p.set(stageVegetation, wx+dx, wy+i, wz+dz, s.Log, false)
+			}
+		}
+	}
+}
+
+// blobCrown plans a round crown around the top of the trunk, narrowing at
+// the very top
+func (p *structurePlan) blobCrown(s *TreeSpecies, wx, wy, wz, height int) {
+	for dy := height - s.Crown; dy <= height+1; dy++ {
+		radius := s.Crown
+		if dy == height+1 {
+			radius = max(1, s.Crown-1)
+		}
+		for dx := -radius; dx <= radius+s.Trunk-1; dx++ {
+			for dz := -radius; dz <= radius+s.Trunk-1; dz++ {
+				if trunkDist(dx, s.Trunk)+trunkDist(dz, s.Trunk) <= radius+1 {
+					p.set(stageVegetation, wx+dx, wy+dy, wz+dz, s.Leaves, true)
+				}
+			}
+		}
+	}
+}
+
+// conicalCrown plans tiers of leaves from a third of the way up the trunk
+// to a point above it. Each tier starts wide and narrows, so the outline is
+// jagged like a spruce.
+func (p *structurePlan) conicalCrown(s *TreeSpecies, wx, wy, wz, height int) {
+	bottom := max(1, height/3)
+	top := height + 1
+	for dy := bottom; dy <= top; dy++ {
+		// Radius shrinks towards the top, stepping back out every few
+		// layers to start the next tier
+		along := float64(dy-bottom) / float64(top-bottom)
+		radius := int(math.Round(float64(s.Crown) * (1 - along)))
+		if (top-dy)%3 == 2 && radius > 1 {
+			radius--
+		}
+		if dy == top {
+			radius = 0
+		}
+		for dx := -radius; dx <= radius+s.Trunk-1; dx++ {
+			for dz := -radius; dz <= radius+s.Trunk-1; dz++ {
+				ex, ez := trunkDist(dx, s.Trunk), trunkDist(dz, s.Trunk)
+				if ex*ex+ez*ez <= radius*radius+1 {
+					p.set(stageVegetation, wx+dx, wy+dy, wz+dz, s.Leaves, true)
+				}
+			}
+		}
+	}
+}
+
+// branches plans branches leaving the upper trunk in evenly spread
+// directions. Each forks into two shorter ones, L-system style, and leafy
+// species grow a leaf cluster at every tip and on top of the trunk.
+func (p *structurePlan) branches(s *TreeSpecies, wx, wy, wz, height int, rng *vmath.SeededRNG) {
+	// Centre of the trunk top
+	cx := float64(wx) + float64(s.Trunk-1)/2
+	cz := float64(wz) + float64(s.Trunk-1)/2
+
+	count := rng.NextInt(s.Branches.Min, s.Branches.Max)
+	turn := rng.NextFloat(0, 2*math.Pi)
+	for i := 0; i < count; i++ {
+		angle := turn + 2*math.Pi*float64(i)/float64(max(count, 1)) + rng.NextFloat(-0.4, 0.4)
+		y := float64(wy) + float64(height)*rng.NextFloat(0.55, 0.9)
+		rise := rng.NextFloat(0.3, 0.9)
+		p.branch(s, cx, y, cz, math.Cos(angle), rise, math.Sin(angle), s.branchLength(height), s.Forks, rng)
+	}
+
+	if s.Leaves != block.Air {
+		p.leafCluster(s, cx, float64(wy+height), cz, s.Crown+s.Trunk-1)
+	}
+}
+
+// branch plans a branch of logs along a direction, forking at its tip
+func (p *structurePlan) branch(s *TreeSpecies, x, y, z, dx, dy, dz float64, length, forks int, rng *vmath.SeededRNG) {
+	n := math.Sqrt(dx*dx + dy*dy + dz*dz)
+	dx, dy, dz = dx/n, dy/n, dz/n
+	for i := 0; i < length; i++ {
+		x, y, z = x+dx, y+dy, z+dz
+		p.set(stageVegetation, int(math.Round(x)), int(math.Round(y)), int(math.Round(z)), s.Log, false)
+	}
+
+	if forks > 0 && length >= 2 {
+		for i := 0; i < 2; i++ {
+			// Swing each fork to one side and a little further upwards
+			side := float64(i*2 - 1)
+			fx := dx - side*dz*rng.NextFloat(0.4, 0.9)
+			fz := dz + side*dx*rng.NextFloat(0.4, 0.9)
+			p.branch(s, x, y, z, fx, dy+rng.NextFloat(0.1, 0.5), fz, length/2+1, forks-1, rng)
+		}
+		return
+	}
+	if s.Leaves != block.Air {
+		p.leafCluster(s, x, y, z, s.Crown)
+	}
+}
+
+// leafCluster plans a flattened ball of leaves
+func (p *structurePlan) leafCluster(s *TreeSpecies, x, y, z float64, radius int) {
+	bx, by, bz := int(math.Round(x)), int(math.Round(y)), int(math.Round(z))
+	r := float64(radius) + 0.5
+	for dy := -radius / 2; dy <= radius/2+1; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			for dz := -radius; dz <= radius; dz++ {
+				// Squashed vertically, so clusters read as canopies
+				d := float64(dx*dx+dz*dz) + float64(dy*dy)*2.5
+				if d <= r*r {
+					p.set(stageVegetation, bx+dx, by+dy, bz+dz, s.Leaves, true)
+				}
+			}
+		}
+	}
+}
+
+// fallen plans a log lying on flat ground along X or Z, next to a short
+// stump. Nothing is planned unless the ground is level under the whole log.
+func (p *structurePlan) fallen(s *TreeSpecies, wx, wy, wz, length int, rng *vmath.SeededRNG, heightAt func(wx, wz int) int) {
+	stepX, stepZ := 1, 0
+	if rng.Next() < 0.5 {
+		stepX, stepZ = 0, 1
+	}
+	if rng.Next() < 0.5 {
+		stepX, stepZ = -stepX, -stepZ
+	}
+
+	// The log starts one block away from the stump
+	for i := 2; i <= length+1; i++ {
+		if heightAt(wx+stepX*i, wz+stepZ*i) != wy-1 {
+			return
+		}
+	}
+
+	p.set(stageVegetation, wx, wy, wz, s.Log, false)
+	for i := 2; i <= length+1; i++ {
+		p.set(stageVegetation, wx+stepX*i, wy, wz+stepZ*i, s.Log, false)
+	}
+}
+
+// trunkDist returns how far an offset lies outside a trunk width wide
+// starting at offset 0
+func trunkDist(d, width int) int {
+	if d < 0 {
+		return -d
+	}
+	if d >= width {
+		return d - width + 1
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+// Package terrain provides oceans, coasts and rivers
+package terrain
+
+import (
+	"math"
+)
+
+// Coast and river shape
+const (
+	// OceanThreshold is the continentalness below which land gives way to
+	// open sea; the sea floor keeps dropping the further below it gets
+	OceanThreshold  = -0.2
+	oceanDepthScale = 30.0
+
+	// River channel and valley half widths, in river noise units
+	riverWidth  = 0.012
+	valleyWidth = 0.045
+
+	// Depth of a river bed below sea level at the middle of the channel
+	riverBedDepth = 3.0
+)
+
+// riverColumn describes where a column lies relative to the nearest river
+type riverColumn struct {
+	channel float64 // 0 outside the channel, rising to 1 in its middle
+	valley  float64 // 0 outside the valley, rising to 1 at the channel's edge
+}
+
+// Continentalness returns the continent-scale elevation at world
+// coordinates. Below OceanThreshold is ocean; biomes use it as their
+// elevation climate value.
+func (g *Generator) Continentalness(wx, wz int) float64 {
+	scale := g.shape.continentScale
+	return g.continentFBM.Sample2D(g.continentNoise, float64(wx)*scale, float64(wz)*scale) + g.shape.continentOffset
+}
+
+// IsRiver reports whether world coordinates lie in a river channel
+func (g *Generator) IsRiver(wx, wz int) bool {
+	return g.riverAt(wx, wz, g.Continentalness(wx, wz)).channel > 0
+}
+
+// riverAt locates a column relative to the river network. Rivers follow
+// the zero line of a noise field, so they wind, branch and never end
+// inland; where lake noise is high they swell into lakes.
+func (g *Generator) riverAt(wx, wz int, continentalness float64) riverColumn {
+	if continentalness < OceanThreshold {
+		return riverColumn{}
+	}
+	x, z := float64(wx), float64(wz)
+	d := math.Abs(g.riverFBM.Sample2D(g.riverNoise, x, z))
+
+	lake := smoothstep((g.detailNoise.Noise2D(x*0.008+9000, z*0.008+9000) - 0.45) / 0.25)
+	width := riverWidth * (1 + 3*lake)
+	valley := valleyWidth + width - riverWidth
+
+	var r riverColumn
+	if d < width {
+		r.channel = 1 - d/width
+	}
+	if d < valley {
+		r.valley = smoothstep((valley - d) / (valley - width))
+	}
+	return r
+}
+
+// shapeCoast drops the terrain towards the sea floor beyond the coast
+func (g *Generator) shapeCoast(height, continentalness float64) float64 {
+	if continentalness < OceanThreshold {
+		height -= (OceanThreshold - continentalness) * oceanDepthScale
+	}
+	return height
+}
+
+// carveRiver lowers a river valley to just above sea level and cuts the
+// channel below it, so rivers fill with water up to sea level and meet
+// the lakes and oceans they cross
+func (g *Generator) carveRiver(height float64, r riverColumn) float64 {
+	seaLevel := float64(g.Config.SeaLevel)
+	if banks := seaLevel + 1; r.valley > 0 && height > banks {
+		height += (banks - height) * r.valley
+	}
+	if r.channel > 0 {
+		bed := seaLevel - 2 - r.channel*riverBedDepth
+		height = math.Min(height, bed)
+	}
+	return height
+}
+
+// FindLand returns the land column nearest to world coordinates, searching
+// outwards in rings of chunk-sized steps up to maxRadius blocks. Beaches
+// and rivers don't count as land. If none is found the start is returned.
+func (g *Generator) FindLand(wx, wz, maxRadius int) (int, int) {
+	const step = 16
+	isLand := func(x, z int) bool {
+		return g.Continentalness(x, z) > OceanThreshold+0.1 && !g.IsRiver(x, z)
+	}
+
+	for r := 0; r <= maxRadius; r += step {
+		for dx := -r; dx <= r; dx += step {
+			for dz := -r; dz <= r; dz += step {
+				// Only the ring, inner squares were searched already
+				if abs(dx) != r && abs(dz) != r {
+					continue
+				}
+				if isLand(wx+dx, wz+dz) {
+					return wx + dx, wz + dz
+				}
+			}
+		}
+	}
+	return wx, wz
+}
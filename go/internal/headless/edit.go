@@ -0,0 +1,255 @@
+// Package headless provides offline inspection and editing of saved block modifications
+package headless
+
+import (
+	"sort"
+
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/save"
+)
+
+// BlockPos is a block position in world coordinates
+type BlockPos struct {
+	X, Y, Z int
+}
+
+// Chunk returns the chunk containing the position
+func (p BlockPos) Chunk() (int, int) {
+	return ChunkCoords(p.X, p.Z)
+}
+
+// Edits maps each modified position to its final block type
+type Edits map[BlockPos]block.Type
+
+// EditsFromSave flattens saved modifications. If a position was recorded
+// more than once, the last entry wins, as when the chunk is loaded.
+func EditsFromSave(mods map[string]save.ChunkModSave) Edits {
+	edits := make(Edits)
+	for _, chunkMods := range mods {
+		for _, m := range chunkMods.Modifications {
+			edits[BlockPos{m.X, m.Y, m.Z}] = block.Type(m.Type)
+		}
+	}
+	return edits
+}
+
+// ToSave groups edits by chunk in save format, ordered by position so
+// output is stable between runs
+func (e Edits) ToSave() map[string]save.ChunkModSave {
+	mods := make(map[string]save.ChunkModSave)
+	for _, pos := range e.Positions() {
+		cx, cz := pos.Chunk()
+		id := ChunkID(cx, cz)
+
+		chunkMods := mods[id]
+		chunkMods.CX = cx
+		chunkMods.CZ = cz
+		chunkMods.Modifications = append(chunkMods.Modifications, save.BlockModSave{
+			X:    pos.X,
+			Y:    pos.Y,
+			Z:    pos.Z,
+			Type: uint8(e[pos]),
+		})
+		mods[id] = chunkMods
+	}
+	return mods
+}
+
+// Positions returns the edited positions sorted by chunk, then Y, Z and X
+func (e Edits) Positions() []BlockPos {
+	positions := make([]BlockPos, 0, len(e))
+	for pos := range e {
+		positions = append(positions, pos)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return lessPos(positions[i], positions[j])
+	})
+	return positions
+}
+
+// ChunkCount is the number of modifications in one chunk
+type ChunkCount struct {
+	CX, CZ int
+	Count  int
+}
+
+// CountByChunk returns the number of distinct modified blocks per chunk,
+// most modified first
+func (e Edits) CountByChunk() []ChunkCount {
+	counts := make(map[[2]int]int)
+	for pos := range e {
+		cx, cz := pos.Chunk()
+		counts[[2]int{cx, cz}]++
+	}
+
+	result := make([]ChunkCount, 0, len(counts))
+	for c, n := range counts {
+		result = append(result, ChunkCount{CX: c[0], CZ: c[1], Count: n})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		if result[i].CX != result[j].CX {
+			return result[i].CX < result[j].CX
+		}
+		return result[i].CZ < result[j].CZ
+	})
+	return result
+}
+
+// Edits returns the flattened modifications of the loaded save
+func (w *World) Edits() Edits {
+	if w.Data == nil {
+		return make(Edits)
+	}
+	return EditsFromSave(w.Data.World.ModifiedChunks)
+}
+
+// SetEdits replaces the modifications in the loaded save and the chunk manager
+func (w *World) SetEdits(edits Edits) {
+	mods := edits.ToSave()
+	if w.Data != nil {
+		w.Data.World.ModifiedChunks = mods
+	}
+	w.Chunks.Clear()
+	w.Chunks.SetModifications(ModificationsFromSave(mods))
+}
+
+// EffectiveBlock returns the block at a position as it appears in game:
+// the saved modification if there is one, the generated block otherwise
+func (w *World) EffectiveBlock(edits Edits, pos BlockPos) block.Type {
+	if t, ok := edits[pos]; ok {
+		return t
+	}
+	return w.BaselineBlock(pos.X, pos.Y, pos.Z)
+}
+
+// Prune removes modifications that set a block to what the generator
+// produces anyway, along with duplicate entries. Returns the number of
+// saved entries removed.
+func (w *World) Prune() int {
+	if w.Data == nil {
+		return 0
+	}
+
+	before := 0
+	for _, chunkMods := range w.Data.World.ModifiedChunks {
+		before += len(chunkMods.Modifications)
+	}
+
+	edits := w.Edits()
+	for pos, t := range edits {
+		if w.BaselineBlock(pos.X, pos.Y, pos.Z) == t {
+			delete(edits, pos)
+		}
+	}
+	w.SetEdits(edits)
+
+	return before - len(edits)
+}
+
+// BlockDiff is a position where two worlds have different blocks
+type BlockDiff struct {
+	Pos  BlockPos
+	A, B block.Type
+}
+
+// Diff compares the modified blocks of two worlds. Every position modified
+// in either world is compared using the block each world actually shows
+// there, so an edit that restores the generated block isn't a difference.
+func Diff(a, b *World) []BlockDiff {
+	editsA := a.Edits()
+	editsB := b.Edits()
+
+	positions := make(map[BlockPos]bool)
+	for pos := range editsA {
+		positions[pos] = true
+	}
+	for pos := range editsB {
+		positions[pos] = true
+	}
+
+	var diffs []BlockDiff
+	for pos := range positions {
+		ta := a.EffectiveBlock(editsA, pos)
+		tb := b.EffectiveBlock(editsB, pos)
+		if ta != tb {
+			diffs = append(diffs, BlockDiff{Pos: pos, A: ta, B: tb})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return lessPos(diffs[i].Pos, diffs[j].Pos)
+	})
+	return diffs
+}
+
+// MergeOptions controls how edits are merged between worlds
+type MergeOptions struct {
+	// KeepExisting leaves blocks the destination already modified untouched
+	KeepExisting bool
+
+	// Filter limits the merge to chunks it returns true for (nil merges everything)
+	Filter func(cx, cz int) bool
+}
+
+// MergeResult counts what a merge did
+type MergeResult struct {
+	Added    int // Positions the destination hadn't modified
+	Replaced int // Positions the destination had modified differently
+	Kept     int // Conflicts left as they were because of KeepExisting
+}
+
+// Merge copies the modifications of src into dst
+func Merge(dst, src *World, opts MergeOptions) MergeResult {
+	var result MergeResult
+
+	edits := dst.Edits()
+	for pos, t := range src.Edits() {
+		if opts.Filter != nil {
+			if cx, cz := pos.Chunk(); !opts.Filter(cx, cz) {
+				continue
+			}
+		}
+
+		existing, modified := edits[pos]
+		switch {
+		case !modified:
+			if dst.BaselineBlock(pos.X, pos.Y, pos.Z) == t {
+				continue
+			}
+			edits[pos] = t
+			result.Added++
+		case existing == t:
+			continue
+		case opts.KeepExisting:
+			result.Kept++
+		default:
+			edits[pos] = t
+			result.Replaced++
+		}
+	}
+
+	dst.SetEdits(edits)
+	return result
+}
+
+// lessPos orders positions by chunk, then Y, Z and X
+func lessPos(a, b BlockPos) bool {
+	acx, acz := a.Chunk()
+	bcx, bcz := b.Chunk()
+	if acx != bcx {
+		return acx < bcx
+	}
+	if acz != bcz {
+		return acz < bcz
+	}
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+	if a.Z != b.Z {
+		return a.Z < b.Z
+	}
+	return a.X < b.X
+}
@@ -0,0 +1,224 @@
+// Package headless provides GL-free access to saved worlds for command-line tools
+package headless
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/core/chunk"
+	"voxelgame/internal/generation/terrain"
+	"voxelgame/internal/save"
+	vmath "voxelgame/pkg/math"
+)
+
+// World is a terrain generator and chunk manager rebuilt from a save,
+// without any rendering
+type World struct {
+	Seed      int64
+	Preset    string
+	Generator *terrain.Generator
+	Chunks    *chunk.Manager
+
+	// ChunkGenerator generates the preset's chunks. It is Generator itself
+	// or builds on it.
+	ChunkGenerator chunk.ChunkGenerator
+
+	// Save the world was loaded from, nil for a freshly generated world
+	Data *save.SaveData
+
+	// Chunks without saved modifications, created on first use
+	baseline *chunk.Manager
+}
+
+// NewWorld creates a world that only contains generated terrain
+func NewWorld(seed int64, preset string, config terrain.GeneratorConfig) *World {
+	gen, chunkGen := NewGenerator(seed, preset, config)
+
+	return &World{
+		Seed:           seed,
+		Preset:         preset,
+		Generator:      gen,
+		ChunkGenerator: chunkGen,
+		Chunks:         chunk.NewManager(chunk.DefaultManagerConfig(), chunkGen),
+	}
+}
+
+// FromSave creates a world with the saved seed, preset, generator config and modifications
+func FromSave(data *save.SaveData) *World {
+	w := NewWorld(data.World.Seed, GeneratorPreset(data.World.Generator), GeneratorConfig(data.World.Generator))
+	w.Chunks.SetModifications(ModificationsFromSave(data.World.ModifiedChunks))
+	w.Data = data
+	return w
+}
+
+// LoadRegion loads all chunks in the inclusive chunk coordinate range
+func (w *World) LoadRegion(minCX, minCZ, maxCX, maxCZ int) []*chunk.Chunk {
+	var chunks []*chunk.Chunk
+	for cz := minCZ; cz <= maxCZ; cz++ {
+		for cx := minCX; cx <= maxCX; cx++ {
+			chunks = append(chunks, w.Chunks.LoadChunk(cx, cz))
+		}
+	}
+	return chunks
+}
+
+// GetBlock returns the block at world coordinates (air in unloaded chunks)
+func (w *World) GetBlock(wx, wy, wz int) block.Type {
+	return w.Chunks.GetBlock(wx, wy, wz)
+}
+
+// BaselineBlock returns the generated block at world coordinates, ignoring
+// saved modifications. The chunk is generated if needed.
+func (w *World) BaselineBlock(wx, wy, wz int) block.Type {
+	if w.baseline == nil {
+		w.baseline = chunk.NewManager(chunk.DefaultManagerConfig(), w.ChunkGenerator)
+	}
+	cx, cz := ChunkCoords(wx, wz)
+	w.baseline.LoadChunk(cx, cz)
+	return w.baseline.GetBlock(wx, wy, wz)
+}
+
+// SurfaceHeight returns the Y of the highest solid or liquid block at world
+// coordinates, including saved modifications, or -1 for an empty column. Over
+// water it is the water surface, not the bed. The chunk is loaded if needed.
+func (w *World) SurfaceHeight(wx, wz int) int {
+	cx, cz := ChunkCoords(wx, wz)
+	w.Chunks.LoadChunk(cx, cz)
+	for y := chunk.Height - 1; y >= 0; y-- {
+		def := block.GetDefinition(w.Chunks.GetBlock(wx, y, wz))
+		if def.Solid || def.Liquid {
+			return y
+		}
+	}
+	return -1
+}
+
+// ChunkCoords returns the chunk containing world coordinates
+func ChunkCoords(wx, wz int) (int, int) {
+	return vmath.FloorDiv(wx, chunk.Size), vmath.FloorDiv(wz, chunk.Size)
+}
+
+// ChunkID returns the chunk key used in saves
+func ChunkID(cx, cz int) string {
+	return fmt.Sprintf("%d,%d", cx, cz)
+}
+
+// NewGenerator creates the terrain generator of a preset with a config.
+// Unknown presets, such as those of newer versions, fall back to the
+// default terrain.
+func NewGenerator(seed int64, preset string, config terrain.GeneratorConfig) (*terrain.Generator, chunk.ChunkGenerator) {
+	gen, chunkGen, err := terrain.NewPresetGenerator(seed, preset, config)
+	if err != nil {
+		fmt.Printf("[Terrain] %v, using %s terrain\n", err, terrain.PresetDefault)
+		gen, chunkGen, _ = terrain.NewPresetGenerator(seed, terrain.PresetDefault, config)
+	}
+	return gen, chunkGen
+}
+
+// GeneratorPreset returns the terrain preset stored in a save.
+// Saves from before presets were stored use the default preset.
+func GeneratorPreset(gen *save.GeneratorSave) string {
+	if gen == nil || gen.Preset == "" {
+		return terrain.PresetDefault
+	}
+	return gen.Preset
+}
+
+// GeneratorConfig returns the terrain config stored in a save.
+// Saves from before the config was stored were generated with the defaults.
+func GeneratorConfig(gen *save.GeneratorSave) terrain.GeneratorConfig {
+	if gen == nil {
+		return terrain.DefaultConfig()
+	}
+	return terrain.GeneratorConfig{
+		SeaLevel:         gen.SeaLevel,
+		TerrainAmplitude: gen.TerrainAmplitude,
+		TreeDensity:      gen.TreeDensity,
+		CaveFrequency:    gen.CaveFrequency,
+		FlatLayers:       gen.FlatLayers,
+	}
+}
+
+// ModificationsFromSave converts saved block modifications to chunk manager format
+func ModificationsFromSave(mods map[string]save.ChunkModSave) map[string][]chunk.BlockModificationWorld {
+	chunkMods := make(map[string][]chunk.BlockModificationWorld)
+	for id, modSave := range mods {
+		var blockMods []chunk.BlockModificationWorld
+		for _, m := range modSave.Modifications {
+			blockMods = append(blockMods, chunk.BlockModificationWorld{
+				X:    m.X,
+				Y:    m.Y,
+				Z:    m.Z,
+				Type: block.Type(m.Type),
+			})
+		}
+		chunkMods[id] = blockMods
+	}
+	return chunkMods
+}
+
+// ModificationsToSave converts chunk manager modifications to save format
+func ModificationsToSave(mods map[string][]chunk.BlockModificationWorld) map[string]save.ChunkModSave {
+	saveMods := make(map[string]save.ChunkModSave)
+	for id, chunkMods := range mods {
+		var saveBlockMods []save.BlockModSave
+		for _, m := range chunkMods {
+			saveBlockMods = append(saveBlockMods, save.BlockModSave{
+				X:    m.X,
+				Y:    m.Y,
+				Z:    m.Z,
+				Type: uint8(m.Type),
+			})
+		}
+
+		// Parse chunk ID to get CX, CZ
+		var cx, cz int
+		fmt.Sscanf(id, "%d,%d", &cx, &cz)
+
+		saveMods[id] = save.ChunkModSave{
+			CX:            cx,
+			CZ:            cz,
+			Modifications: saveBlockMods,
+		}
+	}
+	return saveMods
+}
+
+// ResolveSave returns the save manager and save name for a path, which is
+// either a world directory or a save file such as "level.json"
+func ResolveSave(path string) (*save.Manager, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if info.IsDir() {
+		return save.NewManagerAt(path), save.LevelSaveName, nil
+	}
+	if filepath.Ext(path) != ".json" {
+		return nil, "", fmt.Errorf("%s is not a world directory or .json save", path)
+	}
+	name := strings.TrimSuffix(filepath.Base(path), ".json")
+	return save.NewManagerAt(filepath.Dir(path)), name, nil
+}
+
+// LoadSave loads the save at a world directory or save file path
+func LoadSave(path string) (*save.SaveData, error) {
+	manager, name, err := ResolveSave(path)
+	if err != nil {
+		return nil, err
+	}
+	return manager.Load(name)
+}
+
+// WriteSave writes save data back to a world directory or save file path.
+// The previous save is kept as a backup.
+func WriteSave(path string, data *save.SaveData) error {
+	manager, name, err := ResolveSave(path)
+	if err != nil {
+		return err
+	}
+	return manager.Save(name, *data)
+}
@@ -0,0 +1,80 @@
+// Package headless provides pre-generated chunk directories for worlds
+package headless
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"voxelgame/internal/core/chunk"
+	"voxelgame/internal/generation/terrain"
+	"voxelgame/internal/save"
+)
+
+// pregenManifestFile records what a chunk directory was generated with
+const pregenManifestFile = "pregen.json"
+
+// PregenManifest describes a directory of pre-generated chunks
+type PregenManifest struct {
+	Seed      int64              `json:"seed"`
+	Generator save.GeneratorSave `json:"generator"`
+}
+
+// WritePregenManifest records the seed and generator settings of a chunk directory
+func WritePregenManifest(dir string, manifest PregenManifest) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, pregenManifestFile), raw, 0644)
+}
+
+// ReadPregenManifest reads the manifest of a chunk directory
+func ReadPregenManifest(dir string) (*PregenManifest, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, pregenManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var manifest PregenManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// OpenPregenerated returns the chunk store in dir if its chunks were
+// generated with the given seed and generator settings, nil otherwise.
+// Stale chunks would not line up with saved modifications, so they are
+// never used.
+func OpenPregenerated(dir string, seed int64, gen save.GeneratorSave) *chunk.Store {
+	manifest, err := ReadPregenManifest(dir)
+	if err != nil {
+		return nil
+	}
+	if manifest.Seed != seed || manifest.Generator != gen {
+		return nil
+	}
+	return chunk.NewStore(dir)
+}
+
+// GeneratorSave returns the generator settings of the world in save format
+func (w *World) GeneratorSave() save.GeneratorSave {
+	if w.Data != nil && w.Data.World.Generator != nil {
+		gen := *w.Data.World.Generator
+		gen.Preset = GeneratorPreset(&gen)
+		return gen
+	}
+	config := w.Generator.Config
+	return save.GeneratorSave{
+		Version:          terrain.GeneratorVersion,
+		Preset:           w.Preset,
+		SeaLevel:         config.SeaLevel,
+		TerrainAmplitude: config.TerrainAmplitude,
+		TreeDensity:      config.TreeDensity,
+		CaveFrequency:    config.CaveFrequency,
+		FlatLayers:       config.FlatLayers,
+	}
+}
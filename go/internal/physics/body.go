@@ -0,0 +1,231 @@
+// Package physics provides the physics body shared by players and entities
+package physics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	maxSubstep    = 0.45 // Longest move per sub-step, less than a block
+	maxSubsteps   = 32
+	contactMargin = 1e-3 // Gap kept between a body and the blocks it touches
+)
+
+// Body is an axis-aligned box moving through the voxel grid. It doesn't
+// store its position: Update takes and returns the bottom center of the
+// box, so players and entities keep positions their own way.
+type Body struct {
+	Velocity mgl32.Vec3
+	Width    float32
+	Height   float32
+
+	Gravity    float32 // Downward acceleration in blocks per second squared
+	Friction   float32 // Horizontal slowdown per second on the ground
+	Drag       float32 // Horizontal slowdown per second in the air
+	StepHeight float32 // Highest ledge walked onto without jumping
+
+	// Contacts from the last update
+	OnGround bool
+	HitWall  bool
+}
+
+// box is an axis-aligned box as its min and max corners
+type box struct {
+	min, max mgl32.Vec3
+}
+
+// bounds returns the box of a body standing at a position
+func (b *Body) bounds(pos mgl32.Vec3) box {
+	hw := b.Width / 2
+	return box{
+		min: mgl32.Vec3{pos.X() - hw, pos.Y(), pos.Z() - hw},
+		max: mgl32.Vec3{pos.X() + hw, pos.Y() + b.Height, pos.Z() + hw},
+	}
+}
+
+// Update applies gravity and friction and moves the body by its velocity,
+// stopping at blocks and stepping up ledges. Fast moves are split into
+// sub-steps of less than a block.
+func (b *Body) Update(pos mgl32.Vec3, dt float32, getBlock BlockGetter) mgl32.Vec3 {
+	pos = b.unstick(pos, getBlock)
+
+	b.Velocity[1] -= b.Gravity * dt
+
+	// Steps are only taken from the ground
+	canStep := b.OnGround
+	b.OnGround = false
+	b.HitWall = false
+
+	delta := b.Velocity.Mul(dt)
+	longest := math.Max(math.Abs(float64(delta.X())), math.Max(math.Abs(float64(delta.Y())), math.Abs(float64(delta.Z()))))
+	steps := int(math.Ceil(longest / maxSubstep))
+	if steps < 1 {
+		steps = 1
+	}
+	if steps > maxSubsteps {
+		steps = maxSubsteps
+	}
+
+	for i := 0; i < steps; i++ {
+		// Velocity may have been stopped on an axis by an earlier sub-step
+		step := b.Velocity.Mul(dt / float32(steps))
+		pos = b.moveVertical(pos, step.Y(), getBlock)
+		pos = b.moveHorizontal(pos, step.X(), step.Z(), canStep || b.OnGround, getBlock)
+	}
+
+	// Slow down sideways, on the ground or in the air
+	slow := b.Drag
+	if b.OnGround {
+		slow = b.Friction
+	}
+	keep := 1 - slow*dt
+	if keep < 0 {
+		keep = 0
+	}
+	b.Velocity[0] *= keep
+	b.Velocity[2] *= keep
+
+	return pos
+}
+
+// moveVertical moves the body up or down until it hits a block
+func (b *Body) moveVertical(pos mgl32.Vec3, dy float32, getBlock BlockGetter) mgl32.Vec3 {
+	moved := b.sweep(pos, 1, dy, getBlock)
+	if moved != dy {
+		if dy < 0 {
+			b.OnGround = true
+		}
+		b.Velocity[1] = 0
+	}
+	pos[1] += moved
+	return pos
+}
+
+// moveHorizontal moves the body sideways until it hits a block. A blocked
+// body on the ground tries the same move raised by StepHeight, and keeps
+// it if that gets further.
+func (b *Body) moveHorizontal(pos mgl32.Vec3, dx, dz float32, canStep bool, getBlock BlockGetter) mgl32.Vec3 {
+	start := pos
+	mx := b.sweep(pos, 0, dx, getBlock)
+	pos[0] += mx
+	mz := b.sweep(pos, 2, dz, getBlock)
+	pos[2] += mz
+
+	if (mx != dx || mz != dz) && canStep && b.StepHeight > 0 {
+		up := b.sweep(start, 1, b.StepHeight, getBlock)
+		raised := start
+		raised[1] += up
+		sx := b.sweep(raised, 0, dx, getBlock)
+		raised[0] += sx
+		sz := b.sweep(raised, 2, dz, getBlock)
+		raised[2] += sz
+		raised[1] += b.sweep(raised, 1, -up, getBlock)
+
+		if sx*sx+sz*sz > mx*mx+mz*mz+contactMargin {
+			pos, mx, mz = raised, sx, sz
+		}
+	}
+
+	if mx != dx {
+		b.Velocity[0] = 0
+		b.HitWall = true
+	}
+	if mz != dz {
+		b.Velocity[2] = 0
+		b.HitWall = true
+	}
+	return pos
+}
+
+// sweep returns how far the body can move along an axis (0 x, 1 y, 2 z),
+// up to d, before touching a collidable block. Blocks the body is already
+// inside don't stop it, so it can get out of them.
+func (b *Body) sweep(pos mgl32.Vec3, axis int, d float32, getBlock BlockGetter) float32 {
+	if d == 0 || getBlock == nil {
+		return 0
+	}
+	bb := b.bounds(pos)
+
+	// Blocks in the space swept through
+	reach := bb
+	if d > 0 {
+		reach.max[axis] += d
+	} else {
+		reach.min[axis] += d
+	}
+	x0, y0, z0 := cell(reach.min[0]+contactMargin), cell(reach.min[1]+contactMargin), cell(reach.min[2]+contactMargin)
+	x1, y1, z1 := cell(reach.max[0]-contactMargin), cell(reach.max[1]-contactMargin), cell(reach.max[2]-contactMargin)
+
+	hit := false
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			for z := z0; z <= z1; z++ {
+				if !getBlock(x, y, z).IsCollidable() {
+					continue
+				}
+				lo := float32([3]int{x, y, z}[axis])
+				hi := lo + 1
+				if d > 0 && lo >= bb.max[axis]-contactMargin && lo-bb.max[axis] < d {
+					d, hit = lo-bb.max[axis], true
+				} else if d < 0 && hi <= bb.min[axis]+contactMargin && hi-bb.min[axis] > d {
+					d, hit = hi-bb.min[axis], true
+				}
+			}
+		}
+	}
+	if !hit {
+		return d
+	}
+	if d > 0 {
+		return max32(0, d-contactMargin)
+	}
+	return min32(0, d+contactMargin)
+}
+
+// unstick lifts a body out of blocks its feet are inside of, such as a
+// block placed on it, up to the first free space
+func (b *Body) unstick(pos mgl32.Vec3, getBlock BlockGetter) mgl32.Vec3 {
+	if getBlock == nil {
+		return pos
+	}
+	for i := 0; i < 3 && b.overlaps(pos, getBlock); i++ {
+		pos[1] = float32(math.Floor(float64(pos.Y()))) + 1
+	}
+	return pos
+}
+
+// overlaps reports whether the bottom layer of the body is inside a
+// collidable block
+func (b *Body) overlaps(pos mgl32.Vec3, getBlock BlockGetter) bool {
+	bb := b.bounds(pos)
+	y := cell(bb.min[1] + contactMargin)
+	for x := cell(bb.min[0] + contactMargin); x <= cell(bb.max[0]-contactMargin); x++ {
+		for z := cell(bb.min[2] + contactMargin); z <= cell(bb.max[2]-contactMargin); z++ {
+			if getBlock(x, y, z).IsCollidable() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cell returns the block coordinate containing a world coordinate
+func cell(v float32) int {
+	return int(math.Floor(float64(v)))
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
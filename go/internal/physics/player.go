@@ -20,6 +20,8 @@ const (
 	PlayerWidth     = 0.6
 	PlayerHeight    = 1.8
 	PlayerEyeHeight = 1.6
+
+	PlayerStepHeight = 1.0 // Walks up single blocks without jumping
 )
 
 // BlockGetter is a function that returns the block at world coordinates
@@ -49,6 +51,10 @@ type Player struct {
 
 	// Block getter for collision
 	getBlock BlockGetter
+
+	// Body the player collides with blocks as. It has no friction or drag
+	// because movement sets the horizontal velocity every update.
+	body Body
 }
 
 // NewPlayer creates a new player at the given position
@@ -57,6 +63,12 @@ func NewPlayer(position mgl32.Vec3, getBlock BlockGetter) *Player {
 		Position: position,
 		Yaw:      -90.0,
 		getBlock: getBlock,
+		body: Body{
+			Width:      PlayerWidth,
+			Height:     PlayerHeight,
+			Gravity:    Gravity,
+			StepHeight: PlayerStepHeight,
+		},
 	}
 }
 
@@ -122,9 +134,6 @@ func (p *Player) updateNormal(dt float32) {
 	p.Velocity[0] = moveDir.X() * speed
 	p.Velocity[2] = moveDir.Z() * speed
 
-	// Apply gravity
-	p.Velocity[1] -= Gravity * dt
-
 	// Jumping
 	if p.wantJump && p.IsOnGround {
 		p.Velocity[1] = JumpForce
@@ -173,46 +182,16 @@ func (p *Player) updateFlying(dt float32) {
 	p.Position = p.Position.Add(moveDir.Mul(speed * dt))
 }
 
+// moveWithCollision moves the player's body, which applies gravity, from
+// the feet
 func (p *Player) moveWithCollision(dt float32) {
-	// Try to move in each axis separately
-	newPos := p.Position
-
-	// Move X
-	newPos[0] += p.Velocity[0] * dt
-	if p.checkCollision(newPos) {
-		newPos[0] = p.Position[0]
-		p.Velocity[0] = 0
-	}
-
-	// Move Z
-	newPos[2] += p.Velocity[2] * dt
-	if p.checkCollision(newPos) {
-		newPos[2] = p.Position[2]
-		p.Velocity[2] = 0
-	}
-
-	// Move Y
-	newPos[1] += p.Velocity[1] * dt
-
-	// Check ground collision
-	if p.Velocity[1] < 0 {
-		if p.checkGroundCollision(newPos) {
-			// Snap to ground
-			groundY := float32(math.Floor(float64(newPos[1]-PlayerEyeHeight)+0.5)) + PlayerEyeHeight
-			newPos[1] = groundY
-			p.Velocity[1] = 0
-			p.IsOnGround = true
-		} else {
-			p.IsOnGround = false
-		}
-	}
+	p.body.Velocity = p.Velocity
+	p.body.OnGround = p.IsOnGround
+	feet := p.body.Update(p.GetFeetPosition(), dt, p.getBlock)
+	p.Velocity = p.body.Velocity
+	p.IsOnGround = p.body.OnGround
 
-	// Check ceiling collision
-	if p.Velocity[1] > 0 {
-		if p.checkCeilingCollision(newPos) {
-			p.Velocity[1] = 0
-		}
-	}
+	newPos := feet.Add(mgl32.Vec3{0, PlayerEyeHeight, 0})
 
 	// Clamp to world bounds
 	if newPos[1] < 2 {
@@ -227,88 +206,6 @@ func (p *Player) moveWithCollision(dt float32) {
 	p.Position = newPos
 }
 
-// checkCollision checks if the player would collide at the given position
-func (p *Player) checkCollision(pos mgl32.Vec3) bool {
-	if p.getBlock == nil {
-		return false
-	}
-
-	// Check multiple points around the player
-	halfWidth := float32(PlayerWidth / 2)
-	checkPoints := [][3]float32{
-		{0, 0, 0},
-		{-halfWidth, 0, -halfWidth},
-		{halfWidth, 0, -halfWidth},
-		{-halfWidth, 0, halfWidth},
-		{halfWidth, 0, halfWidth},
-	}
-
-	for _, offset := range checkPoints {
-		bx := int(math.Floor(float64(pos.X() + offset[0])))
-		by := int(math.Floor(float64(pos.Y() - PlayerEyeHeight + 0.5 + offset[1])))
-		bz := int(math.Floor(float64(pos.Z() + offset[2])))
-
-		blockType := p.getBlock(bx, by, bz)
-		if blockType.IsCollidable() {
-			return true
-		}
-
-		// Also check at body height
-		by = int(math.Floor(float64(pos.Y() - PlayerEyeHeight + 1.0 + offset[1])))
-		blockType = p.getBlock(bx, by, bz)
-		if blockType.IsCollidable() {
-			return true
-		}
-	}
-
-	return false
-}
-
-// checkGroundCollision checks for ground below the player
-func (p *Player) checkGroundCollision(pos mgl32.Vec3) bool {
-	if p.getBlock == nil {
-		return false
-	}
-
-	halfWidth := float32(PlayerWidth / 2)
-	feetY := pos.Y() - PlayerEyeHeight
-
-	checkPoints := [][2]float32{
-		{0, 0},
-		{-halfWidth, -halfWidth},
-		{halfWidth, -halfWidth},
-		{-halfWidth, halfWidth},
-		{halfWidth, halfWidth},
-	}
-
-	for _, offset := range checkPoints {
-		bx := int(math.Floor(float64(pos.X() + offset[0])))
-		by := int(math.Floor(float64(feetY)))
-		bz := int(math.Floor(float64(pos.Z() + offset[1])))
-
-		blockType := p.getBlock(bx, by, bz)
-		if blockType.IsCollidable() {
-			return true
-		}
-	}
-
-	return false
-}
-
-// checkCeilingCollision checks for ceiling above the player
-func (p *Player) checkCeilingCollision(pos mgl32.Vec3) bool {
-	if p.getBlock == nil {
-		return false
-	}
-
-	bx := int(math.Floor(float64(pos.X())))
-	by := int(math.Floor(float64(pos.Y() + 0.2)))
-	bz := int(math.Floor(float64(pos.Z())))
-
-	blockType := p.getBlock(bx, by, bz)
-	return blockType.IsCollidable()
-}
-
 // GetFeetPosition returns the position of the player's feet
 func (p *Player) GetFeetPosition() mgl32.Vec3 {
 	return mgl32.Vec3{p.Position.X(), p.Position.Y() - PlayerEyeHeight, p.Position.Z()}
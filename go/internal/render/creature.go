@@ -105,9 +105,9 @@ func (cr *CreatureRenderer) createCubeMesh() {
 	gl.BindVertexArray(0)
 }
 
-// RenderCreature renders a single creature
-func (cr *CreatureRenderer) RenderCreature(creature *entity.Creature, view, projection mgl32.Mat4, sunDir mgl32.Vec3) {
-	if cr.shader == nil || creature == nil {
+// RenderModel renders an entity's model, holding an item
+func (cr *CreatureRenderer) RenderModel(t *entity.Transform, m *entity.Renderable, held block.Type, view, projection mgl32.Mat4, sunDir mgl32.Vec3) {
+	if cr.shader == nil || t == nil || m == nil {
 		return
 	}
 
@@ -116,52 +116,55 @@ func (cr *CreatureRenderer) RenderCreature(creature *entity.Creature, view, proj
 	cr.shader.SetMat4("uProjection", projection)
 	cr.shader.SetVec3("uSunDirection", sunDir)
 
+	// Stand the model on its feet
+	origin := t.Position.Add(mgl32.Vec3{0, footLift(m.BodyParts), 0})
+
 	// Calculate animation offsets
-	walkPhase := creature.WalkPhase
-	idleBreath := float32(math.Sin(float64(creature.AnimationTime)*2.0)) * 0.02
+	walkPhase := m.WalkPhase
+	idleBreath := float32(math.Sin(float64(m.Time)*2.0)) * 0.02
 	legIndex := 0
 
 	// Render each body part as a scaled cube
-	for _, part := range creature.BodyParts {
+	for _, part := range m.BodyParts {
 		offset := part.Offset
 
 		// Apply body bob when moving or idle breathing
 		if part.Type == "torso" || part.Type == "body" || part.Type == "abdomen" {
-			if creature.IsMoving {
+			if m.Moving {
 				// Walking bob
-				offset[1] += float32(math.Abs(math.Sin(float64(walkPhase)*2.0))) * 0.03 * creature.Size
+				offset[1] += float32(math.Abs(math.Sin(float64(walkPhase)*2.0))) * 0.03 * m.Size
 			} else {
 				// Idle breathing
-				offset[1] += idleBreath * creature.Size
+				offset[1] += idleBreath * m.Size
 			}
 		}
 
 		// Head slight movement
 		if part.Type == "head" {
-			if creature.IsMoving {
-				offset[1] += float32(math.Abs(math.Sin(float64(walkPhase)*2.0))) * 0.02 * creature.Size
+			if m.Moving {
+				offset[1] += float32(math.Abs(math.Sin(float64(walkPhase)*2.0))) * 0.02 * m.Size
 			} else {
-				offset[1] += idleBreath * creature.Size
+				offset[1] += idleBreath * m.Size
 			}
 		}
 
-		pos := creature.Position.Add(offset)
+		pos := origin.Add(offset)
 
 		// Model matrix: translate, rotate, scale
 		model := mgl32.Translate3D(pos.X(), pos.Y(), pos.Z())
-		model = model.Mul4(mgl32.HomogRotate3DY(creature.Rotation))
+		model = model.Mul4(mgl32.HomogRotate3DY(t.Rotation))
 
 		// Apply part-specific animations
 		switch part.Type {
 		case "leg":
-			if creature.IsMoving {
+			if m.Moving {
 				// Alternate legs based on index
 				legPhase := walkPhase
 				if legIndex%2 == 1 {
 					legPhase += math.Pi // Opposite phase
 				}
 				// Front vs back legs for quadrupeds
-				if creature.Template == entity.TemplateQuadruped && legIndex >= 2 {
+				if m.Template == entity.TemplateQuadruped && legIndex >= 2 {
 					legPhase += math.Pi * 0.5 // Offset for back legs
 				}
 				// Swing angle
@@ -182,13 +185,13 @@ func (cr *CreatureRenderer) RenderCreature(creature *entity.Creature, view, proj
 			armSwing := float32(math.Sin(float64(armPhase))) * 0.4
 
 			// Apply attack swing override for right arm
-			if legIndex%2 == 1 && creature.SwingPhase > 0 {
+			if legIndex%2 == 1 && m.SwingPhase > 0 {
 				// Overwrite arm swing with attack swing
 				// Swing down: starts high, swings down rapidly
-				swingProgress := creature.SwingPhase / math.Pi // 0 to 1
+				swingProgress := m.SwingPhase / math.Pi // 0 to 1
 				armSwing = float32(math.Sin(float64(swingProgress*math.Pi))) * 2.0
 				// Also rotate inward slightly?
-			} else if creature.IsMoving {
+			} else if m.Moving {
 				// Only apply walk swing if moving and not attacking (or for left arm)
 			} else {
 				armSwing = 0
@@ -202,7 +205,7 @@ func (cr *CreatureRenderer) RenderCreature(creature *entity.Creature, view, proj
 
 		case "wing":
 			// Wings always flap for flying creatures
-			wingFlap := float32(math.Sin(float64(creature.AnimationTime)*8.0)) * 0.6
+			wingFlap := float32(math.Sin(float64(m.Time)*8.0)) * 0.6
 			// Flap around Z axis
 			if offset.X() > 0 {
 				model = model.Mul4(mgl32.HomogRotate3DZ(-wingFlap))
@@ -212,17 +215,17 @@ func (cr *CreatureRenderer) RenderCreature(creature *entity.Creature, view, proj
 
 		case "tail":
 			// Tail wags slightly
-			tailWag := float32(math.Sin(float64(creature.AnimationTime)*4.0)) * 0.2
+			tailWag := float32(math.Sin(float64(m.Time)*4.0)) * 0.2
 			model = model.Mul4(mgl32.HomogRotate3DY(tailWag))
 
 		case "blob":
 			// Slime squish animation
-			squish := float32(math.Sin(float64(creature.AnimationTime)*3.0))*0.1 + 1.0
+			squish := float32(math.Sin(float64(m.Time)*3.0))*0.1 + 1.0
 			model = model.Mul4(mgl32.Scale3D(1.0/squish, squish, 1.0/squish))
 
 		case "fin":
 			// Fish fin flutter
-			finFlutter := float32(math.Sin(float64(creature.AnimationTime)*6.0)) * 0.3
+			finFlutter := float32(math.Sin(float64(m.Time)*6.0)) * 0.3
 			model = model.Mul4(mgl32.HomogRotate3DZ(finFlutter))
 		}
 
@@ -234,11 +237,11 @@ func (cr *CreatureRenderer) RenderCreature(creature *entity.Creature, view, proj
 		var color [3]float32
 		switch part.Type {
 		case "head", "torso", "body", "abdomen", "thorax", "blob":
-			color = creature.PrimaryColor
+			color = m.PrimaryColor
 		case "leg", "arm", "wing", "fin", "tail":
-			color = creature.SecondaryColor
+			color = m.SecondaryColor
 		default:
-			color = creature.AccentColor
+			color = m.AccentColor
 		}
 		cr.shader.SetVec3("uColor", mgl32.Vec3{color[0], color[1], color[2]})
 
@@ -247,11 +250,11 @@ func (cr *CreatureRenderer) RenderCreature(creature *entity.Creature, view, proj
 	}
 
 	// Render held item if any
-	if creature.HeldItem != block.Air {
+	if held != block.Air {
 		// Calculate item position - assume right hand side
 		var itemOffset mgl32.Vec3
 
-		switch creature.Template {
+		switch m.Template {
 		case entity.TemplateBiped:
 			itemOffset = mgl32.Vec3{0.6, 0.9, 0.5}
 		case entity.TemplateQuadruped:
@@ -260,22 +263,22 @@ func (cr *CreatureRenderer) RenderCreature(creature *entity.Creature, view, proj
 			itemOffset = mgl32.Vec3{0.5, 0.5, 0.5}
 		}
 
-		pos := creature.Position.Add(itemOffset)
+		pos := origin.Add(itemOffset)
 
 		model := mgl32.Translate3D(pos.X(), pos.Y(), pos.Z())
 		// Rotate item slightly to look held
-		model = model.Mul4(mgl32.HomogRotate3DY(creature.Rotation))
+		model = model.Mul4(mgl32.HomogRotate3DY(t.Rotation))
 
 		// Apply swing rotation to item matches arm
-		if creature.SwingPhase > 0 {
-			swingProgress := creature.SwingPhase / math.Pi
+		if m.SwingPhase > 0 {
+			swingProgress := m.SwingPhase / math.Pi
 			swingAngle := float32(math.Sin(float64(swingProgress*math.Pi))) * 2.0
 			model = model.Mul4(mgl32.Translate3D(0, 0, 0)) // Pivot?
 			model = model.Mul4(mgl32.HomogRotate3DX(swingAngle))
 		}
 
 		// Adjust orientation based on item type
-		if creature.HeldItem == block.Pickaxe || creature.HeldItem == block.Axe || creature.HeldItem == block.Shovel || creature.HeldItem == block.Sword {
+		if held == block.Pickaxe || held == block.Axe || held == block.Shovel || held == block.Sword {
 			// Tools tend to be held vertically or angled forward
 			model = model.Mul4(mgl32.HomogRotate3DX(mgl32.DegToRad(45))) // Tilt forward
 		} else {
@@ -286,15 +289,27 @@ func (cr *CreatureRenderer) RenderCreature(creature *entity.Creature, view, proj
 		}
 
 		// Set color based on block type
-		color := creature.HeldItem.GetColor()
+		color := held.GetColor()
 
 		// Use RenderItem
-		cr.RenderItem(creature.HeldItem, model, color)
+		cr.RenderItem(held, model, color)
 	}
 
 	gl.BindVertexArray(0)
 }
 
+// footLift returns how far a model is raised for its lowest part to
+// touch the ground, as parts are centered on their offsets
+func footLift(parts []entity.BodyPart) float32 {
+	lowest := float32(0)
+	for _, p := range parts {
+		if bottom := p.Offset.Y() - p.Size.Y()/2; bottom < lowest {
+			lowest = bottom
+		}
+	}
+	return -lowest
+}
+
 // RenderViewModel renders the held item in first-person view
 func (cr *CreatureRenderer) RenderViewModel(item block.Type, animationTime float32, swingPhase float32, projection mgl32.Mat4, sunDir mgl32.Vec3) {
 	if cr.shader == nil || item == block.Air {
@@ -431,15 +446,45 @@ func (cr *CreatureRenderer) renderCubePart(baseModel mgl32.Mat4, size mgl32.Vec3
 	gl.DrawArrays(gl.TRIANGLES, 0, 36)
 }
 
-// RenderCreatures renders multiple creatures
-func (cr *CreatureRenderer) RenderCreatures(creatures []*entity.Creature, view, projection mgl32.Mat4, sunDir mgl32.Vec3) {
-	if len(creatures) == 0 {
+// RenderItemEntity renders a dropped or thrown item, spinning in the air
+func (cr *CreatureRenderer) RenderItemEntity(t *entity.Transform, m *entity.Renderable, view, projection mgl32.Mat4, sunDir mgl32.Vec3) {
+	if cr.shader == nil || t == nil || m == nil || m.Item == block.Air {
 		return
 	}
 
-	for _, creature := range creatures {
-		cr.RenderCreature(creature, view, projection, sunDir)
-	}
+	cr.shader.Use()
+	cr.shader.SetMat4("uView", view)
+	cr.shader.SetMat4("uProjection", projection)
+	cr.shader.SetVec3("uSunDirection", sunDir)
+
+	bob := float32(math.Sin(float64(m.Time)*2.0)) * 0.05
+	pos := t.Position.Add(mgl32.Vec3{0, m.Scale*0.5 + bob, 0})
+	model := mgl32.Translate3D(pos.X(), pos.Y(), pos.Z())
+	model = model.Mul4(mgl32.HomogRotate3DY(m.Time * 2.0))
+	model = model.Mul4(mgl32.Scale3D(m.Scale, m.Scale, m.Scale))
+
+	cr.RenderItem(m.Item, model, m.Item.GetColor())
+	gl.BindVertexArray(0)
+}
+
+// RenderEntities renders every entity with a model, except hidden (the
+// player in first person)
+func (cr *CreatureRenderer) RenderEntities(r *entity.Registry, hidden entity.ID, view, projection mgl32.Mat4, sunDir mgl32.Vec3) {
+	r.Renderables.Each(func(id entity.ID, m *entity.Renderable) {
+		if id == hidden {
+			return
+		}
+		t := r.Transforms.Get(id)
+		if m.IsItem() {
+			cr.RenderItemEntity(t, m, view, projection, sunDir)
+			return
+		}
+		held := block.Air
+		if inv := r.Inventories.Get(id); inv != nil {
+			held = inv.Held
+		}
+		cr.RenderModel(t, m, held, view, projection, sunDir)
+	})
 }
 
 // Cleanup releases resources
@@ -124,26 +124,8 @@ func NewEngine(config Config) (*Engine, error) {
 	gl.ClearColor(0.6, 0.8, 1.0, 1.0)
 
 	tm := NewTextureManager()
-	// Define standard texture list - Mapping to IDs 0, 1, 2...
-	// Now loading from embedded assets
-	textureFiles := []string{
-		"textures/dirt.png",            // 0
-		"textures/grass_top.png",       // 1
-		"textures/grass_side.png",      // 2
-		"textures/stone.png",           // 3
-		"textures/wood.png",            // 4
-		"textures/leaves.png",          // 5
-		"textures/water.png",           // 6
-		"textures/ice.png",             // 7
-		"textures/sand.png",            // 8
-		"textures/snow.png",            // 9
-		"textures/glass.png",           // 10
-		"textures/lava.png",            // 11
-		"textures/campfire.png",        // 12
-		"textures/stonebrick.png",      // 13
-		"textures/mossystonebrick.png", // 14
-	}
-	err = tm.LoadBlockTexturesFromEmbed(textureFiles, assets.FS())
+	// Load block textures from embedded assets, mapping to IDs 0, 1, 2...
+	err = tm.LoadBlockTexturesFromEmbed(assets.BlockTextures, assets.FS())
 	if err != nil {
 		fmt.Printf("Error loading textures: %v\n", err)
 	}
@@ -166,6 +148,7 @@ func NewEngine(config Config) (*Engine, error) {
 	// Set up callbacks
 	window.SetFramebufferSizeCallback(engine.framebufferSizeCallback)
 	window.SetKeyCallback(engine.keyCallback)
+	window.SetCharCallback(engine.charCallback)
 	window.SetCursorPosCallback(engine.cursorPosCallback)
 	window.SetMouseButtonCallback(engine.mouseButtonCallback)
 	window.SetScrollCallback(engine.scrollCallback)
@@ -304,6 +287,21 @@ func (e *Engine) UpdateAtmosphericParticles(playerPos mgl32.Vec3, biome string,
 	}
 }
 
+// UpdatePrecipitation updates rain or snow around the player, see
+// ParticleSystem.UpdatePrecipitation
+func (e *Engine) UpdatePrecipitation(playerPos mgl32.Vec3, snow bool, intensity float32, heightAt func(x, z int) int) {
+	if e.particleSystem != nil {
+		e.particleSystem.UpdatePrecipitation(playerPos, snow, intensity, heightAt, e.deltaTime)
+	}
+}
+
+// EmitLightning emits a lightning bolt striking the given position
+func (e *Engine) EmitLightning(pos mgl32.Vec3) {
+	if e.particleSystem != nil {
+		e.particleSystem.EmitLightning(pos)
+	}
+}
+
 // UpdateWaterParticles updates water/underwater particle effects
 func (e *Engine) UpdateWaterParticles(playerPos mgl32.Vec3, isUnderwater bool, waterSurfaceY float32) {
 	if e.particleSystem != nil {
@@ -412,6 +410,10 @@ func (e *Engine) keyCallback(w *glfw.Window, key glfw.Key, scancode int, action
 	e.input.HandleKey(key, action)
 }
 
+func (e *Engine) charCallback(w *glfw.Window, char rune) {
+	e.input.HandleChar(char)
+}
+
 func (e *Engine) cursorPosCallback(w *glfw.Window, xpos, ypos float64) {
 	e.input.HandleMouseMove(xpos, ypos)
 }
@@ -23,6 +23,9 @@ type Input struct {
 
 	// Scroll
 	scrollX, scrollY float64
+
+	// Typed characters since the last ConsumeText
+	text []rune
 }
 
 // NewInput creates a new input handler
@@ -75,6 +78,21 @@ func (i *Input) HandleScroll(xoff, yoff float64) {
 	i.scrollY = yoff
 }
 
+// HandleChar processes typed text characters
+func (i *Input) HandleChar(char rune) {
+	// Nobody may be reading text (e.g. during gameplay), so keep it bounded
+	if len(i.text) < 256 {
+		i.text = append(i.text, char)
+	}
+}
+
+// ConsumeText returns the characters typed since the last call and resets them
+func (i *Input) ConsumeText() string {
+	text := string(i.text)
+	i.text = i.text[:0]
+	return text
+}
+
 // IsKeyPressed returns true if a key is currently pressed
 func (i *Input) IsKeyPressed(key glfw.Key) bool {
 	return i.keys[key]
@@ -15,19 +15,20 @@ import (
 type ParticleType int
 
 const (
-	ParticleTypeDefault ParticleType = iota
-	ParticleTypeDust                 // Desert/plains floating dust motes
-	ParticleTypeSpore                // Forest spores/pollen
-	ParticleTypeFirefly              // Glowing fireflies at night
-	ParticleTypeSnow                 // Falling snowflakes
-	ParticleTypeRain                 // Rain droplets
-	ParticleTypeAsh                  // Volcanic ash
-	ParticleTypeMist                 // Swamp/water mist
-	ParticleTypeFire                 // Fire/flame particles
-	ParticleTypeSmoke                // Smoke particles rising
-	ParticleTypeBubble               // Underwater bubbles
-	ParticleTypeFish                 // Fish silhouettes in water
-	ParticleTypeSpark                // Sparks from fire
+	ParticleTypeDefault   ParticleType = iota
+	ParticleTypeDust                   // Desert/plains floating dust motes
+	ParticleTypeSpore                  // Forest spores/pollen
+	ParticleTypeFirefly                // Glowing fireflies at night
+	ParticleTypeSnow                   // Falling snowflakes
+	ParticleTypeRain                   // Rain droplets
+	ParticleTypeAsh                    // Volcanic ash
+	ParticleTypeMist                   // Swamp/water mist
+	ParticleTypeFire                   // Fire/flame particles
+	ParticleTypeSmoke                  // Smoke particles rising
+	ParticleTypeBubble                 // Underwater bubbles
+	ParticleTypeFish                   // Fish silhouettes in water
+	ParticleTypeSpark                  // Sparks from fire
+	ParticleTypeLightning              // Lightning bolt segments
 )
 
 // Particle represents a single particle
@@ -51,6 +52,10 @@ type ParticleSystem struct {
 	instanceVBO uint32 // Instance buffer (positions/colors)
 	shader      *Shader
 	texture     uint32 // Particle texture (e.g. circle/gleam)
+
+	// Whether rain or snow is falling, which replaces the light snowfall
+	// of snowy biomes
+	precipitating bool
 }
 
 // NewParticleSystem creates a new particle system
@@ -242,6 +247,11 @@ func (ps *ParticleSystem) Update(dt float32) {
 				flicker := 0.7 + rand.Float32()*0.3
 				p.Color[3] = flicker * p.Life / 0.5
 
+			case ParticleTypeLightning:
+				// Lightning - stays in place and flickers out
+				flicker := 0.6 + rand.Float32()*0.4
+				p.Color[3] = flicker * p.Life / 0.4
+
 			case ParticleTypeBubble:
 				// Bubbles - rise slowly, wobble side to side
 				p.Velocity[1] = 2.0 + rand.Float32()*0.5
@@ -398,7 +408,7 @@ func (ps *ParticleSystem) UpdateAtmospheric(playerPos mgl32.Vec3, biome string,
 			rate:     2.0,
 		})
 
-	case "forest":
+	case "forest", "birch_forest":
 		// Forest spores/pollen
 		particlesToSpawn = append(particlesToSpawn, struct {
 			pType    ParticleType
@@ -435,22 +445,24 @@ func (ps *ParticleSystem) UpdateAtmospheric(playerPos mgl32.Vec3, biome string,
 		}
 
 	case "snow", "tundra", "taiga":
-		// Snowflakes falling
-		particlesToSpawn = append(particlesToSpawn, struct {
-			pType    ParticleType
-			color    mgl32.Vec4
-			size     float32
-			life     float32
-			velocity mgl32.Vec3
-			rate     float32
-		}{
-			pType:    ParticleTypeSnow,
-			color:    mgl32.Vec4{1.0, 1.0, 1.0, 0.9}, // White (brighter)
-			size:     0.5,
-			life:     12.0,
-			velocity: mgl32.Vec3{0.3, -1.0, 0.3},
-			rate:     3.0,
-		})
+		// Snowflakes falling, unless the weather brings its own
+		if !ps.precipitating {
+			particlesToSpawn = append(particlesToSpawn, struct {
+				pType    ParticleType
+				color    mgl32.Vec4
+				size     float32
+				life     float32
+				velocity mgl32.Vec3
+				rate     float32
+			}{
+				pType:    ParticleTypeSnow,
+				color:    mgl32.Vec4{1.0, 1.0, 1.0, 0.9}, // White (brighter)
+				size:     0.5,
+				life:     12.0,
+				velocity: mgl32.Vec3{0.3, -1.0, 0.3},
+				rate:     3.0,
+			})
+		}
 
 	case "swamp":
 		// Mist rising
@@ -567,6 +579,104 @@ func (ps *ParticleSystem) UpdateAtmospheric(playerPos mgl32.Vec3, biome string,
 	}
 }
 
+// UpdatePrecipitation spawns rain or snow around the player at the given
+// intensity (0-1). heightAt returns the height of the highest block in a
+// column: nothing spawns below it, and rain and snow that fall below it
+// disappear, so precipitation stops at roofs and the ground.
+func (ps *ParticleSystem) UpdatePrecipitation(playerPos mgl32.Vec3, snow bool, intensity float32, heightAt func(x, z int) int, dt float32) {
+	const (
+		spawnRadius = 20.0 // How far around player to spawn drops
+		spawnAbove  = 18.0 // Height above the player drops start at
+		rainPerSec  = 400  // Drops per second at full intensity
+		snowPerSec  = 120  // Flakes per second at full intensity
+	)
+
+	// Remove drops that hit a roof or the ground
+	alive := ps.particles[:0]
+	for _, p := range ps.particles {
+		if p.Type == ParticleTypeRain || p.Type == ParticleTypeSnow {
+			x := int(math.Floor(float64(p.Position.X())))
+			z := int(math.Floor(float64(p.Position.Z())))
+			if p.Position.Y() < float32(heightAt(x, z)+1) {
+				continue
+			}
+		}
+		alive = append(alive, p)
+	}
+	ps.particles = alive
+
+	ps.precipitating = intensity > 0
+	if intensity <= 0 {
+		return
+	}
+
+	rate := float32(rainPerSec)
+	if snow {
+		rate = snowPerSec
+	}
+	spawnChance := rate * intensity * dt
+	numToSpawn := int(spawnChance)
+	if rand.Float32() < spawnChance-float32(numToSpawn) {
+		numToSpawn++
+	}
+
+	for i := 0; i < numToSpawn; i++ {
+		pos := mgl32.Vec3{
+			playerPos.X() + (rand.Float32()*2-1)*spawnRadius,
+			playerPos.Y() + spawnAbove*(0.5+rand.Float32()*0.5),
+			playerPos.Z() + (rand.Float32()*2-1)*spawnRadius,
+		}
+		x := int(math.Floor(float64(pos.X())))
+		z := int(math.Floor(float64(pos.Z())))
+		if pos.Y() < float32(heightAt(x, z)+1) {
+			continue // Under a roof
+		}
+
+		if snow {
+			vel := mgl32.Vec3{(rand.Float32() - 0.5) * 0.6, -1.5, (rand.Float32() - 0.5) * 0.6}
+			color := mgl32.Vec4{1.0, 1.0, 1.0, 0.8 + rand.Float32()*0.2}
+			ps.EmitTyped(pos, vel, color, 14.0, 0.35+rand.Float32()*0.2, ParticleTypeSnow)
+		} else {
+			vel := mgl32.Vec3{0.6, -15.0, 0.3}
+			color := mgl32.Vec4{0.6, 0.7, 0.9, 0.5 + rand.Float32()*0.2}
+			ps.EmitTyped(pos, vel, color, 2.5, 0.12, ParticleTypeRain)
+		}
+	}
+}
+
+// EmitLightning draws a jagged bolt from the sky down to a struck block,
+// with sparks where it lands
+func (ps *ParticleSystem) EmitLightning(ground mgl32.Vec3) {
+	const (
+		boltHeight = 60.0 // Height the bolt starts above the ground
+		segment    = 0.4  // Spacing of the bolt's particles
+	)
+
+	pos := ground.Add(mgl32.Vec3{(rand.Float32() - 0.5) * 6, boltHeight, (rand.Float32() - 0.5) * 6})
+	color := mgl32.Vec4{0.9, 0.92, 1.0, 1.0}
+	for pos.Y() > ground.Y() {
+		ps.EmitTyped(pos, mgl32.Vec3{}, color, 0.4, 0.5, ParticleTypeLightning)
+
+		// Step down, drifting toward the ground point with some jitter
+		toGround := ground.Sub(pos)
+		steps := toGround.Y() / -segment
+		pos = pos.Add(mgl32.Vec3{
+			toGround.X()/steps + (rand.Float32()-0.5)*0.6,
+			-segment,
+			toGround.Z()/steps + (rand.Float32()-0.5)*0.6,
+		})
+	}
+
+	for i := 0; i < 30; i++ {
+		vel := mgl32.Vec3{
+			(rand.Float32() - 0.5) * 8,
+			rand.Float32()*6 + 2,
+			(rand.Float32() - 0.5) * 8,
+		}
+		ps.EmitTyped(ground, vel, mgl32.Vec4{1.0, 0.95, 0.7, 1.0}, 0.5, 0.15, ParticleTypeSpark)
+	}
+}
+
 // GetParticleCount returns the current number of active particles
 func (ps *ParticleSystem) GetParticleCount() int {
 	return len(ps.particles)
@@ -33,6 +33,10 @@ type Sky struct {
 
 	// Total elapsed time for animations
 	TotalTime float32
+
+	// Cloud cover from the weather (0-1) and lightning flash brightness
+	Overcast float32
+	Flash    float32
 }
 
 // NewSky creates a new sky renderer
@@ -100,6 +104,16 @@ func (s *Sky) Update(dt float32) {
 	s.updateMoonDirection()
 }
 
+// SetTime sets the time of day (0-24) and the day count, for a sky that
+// follows a clock kept elsewhere
+func (s *Sky) SetTime(hour float32, day int) {
+	s.TimeOfDay = hour
+	s.DayCount = float32(day)
+	s.MoonPhase = float32(math.Mod(float64(s.DayCount)/29.5, 1.0))
+	s.updateSunDirection()
+	s.updateMoonDirection()
+}
+
 func (s *Sky) updateSunDirection() {
 	// Calculate sun position based on time
 	// 6:00 = sunrise (east), 12:00 = zenith, 18:00 = sunset (west)
@@ -141,6 +155,8 @@ func (s *Sky) Render(invViewProj mgl32.Mat4, cameraPos mgl32.Vec3) {
 	s.shader.SetFloat("uMoonPhase", s.MoonPhase)
 	s.shader.SetFloat("uTime", s.TotalTime)
 	s.shader.SetVec2("uCloudOffset", s.CloudOffset)
+	s.shader.SetFloat("uOvercast", s.Overcast)
+	s.shader.SetFloat("uFlash", s.Flash)
 
 	gl.BindVertexArray(s.vao)
 	gl.DrawArrays(gl.TRIANGLES, 0, 6)
@@ -212,6 +228,8 @@ uniform float uTimeOfDay;
 uniform float uMoonPhase;
 uniform float uTime;
 uniform vec2 uCloudOffset;
+uniform float uOvercast;
+uniform float uFlash;
 
 out vec4 fragColor;
 
@@ -290,7 +308,8 @@ vec4 getClouds(vec3 rayDir, float dayFactor) {
     float cloud3 = fbm(cloudUV * 2.0 + vec2(200.0));
     
     float clouds = cloud1 * 0.5 + cloud2 * 0.3 + cloud3 * 0.2;
-    clouds = smoothstep(0.4, 0.7, clouds);
+    // Overcast skies fill in the gaps between clouds
+    clouds = smoothstep(0.4 - uOvercast * 0.35, 0.7 - uOvercast * 0.2, clouds);
     
     // Fade clouds at horizon and overhead
     float horizonFade = smoothstep(0.0, 0.2, rayDir.y);
@@ -317,6 +336,9 @@ vec4 getClouds(vec3 rayDir, float dayFactor) {
     float sunLight = max(0.0, dot(uSunDirection, vec3(0.0, 1.0, 0.0))) * dayFactor;
     cloudColor *= 0.7 + sunLight * 0.3;
     
+    // Rain clouds are darker
+    cloudColor *= 1.0 - uOvercast * 0.55;
+    
     return vec4(cloudColor, clouds * 0.9);
 }
 
@@ -500,6 +522,12 @@ void main() {
     vec3 hazeColor = mix(vec3(0.1, 0.1, 0.15), vec3(0.7, 0.75, 0.85), dayFactor);
     skyColor = mix(skyColor, hazeColor, haze);
     
+    // === WEATHER ===
+    
+    float gray = dot(skyColor, vec3(0.3, 0.5, 0.2)) * (1.0 - uOvercast * 0.5);
+    skyColor = mix(skyColor, vec3(gray), uOvercast * 0.8);
+    skyColor += vec3(0.8, 0.85, 1.0) * uFlash * 0.6;
+    
     fragColor = vec4(skyColor, 1.0);
 }
 ` + "\x00"
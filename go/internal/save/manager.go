@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
@@ -15,6 +16,9 @@ type SaveData struct {
 	Timestamp int64      `json:"timestamp"`
 	Player    PlayerSave `json:"player"`
 	World     WorldSave  `json:"world"`
+
+	// SHA-256 of the save with this field empty, verified on load
+	Checksum string `json:"checksum,omitempty"`
 }
 
 // PlayerSave contains player state
@@ -24,12 +28,107 @@ type PlayerSave struct {
 	PositionZ float32 `json:"z"`
 	Yaw       float32 `json:"yaw"`
 	Pitch     float32 `json:"pitch"`
+
+	// Saves from before these fields existed leave them nil,
+	// in which case the defaults of a new game are kept on load
+	Movement  *MovementSave  `json:"movement,omitempty"`
+	Inventory *InventorySave `json:"inventory,omitempty"`
+}
+
+// MovementSave contains player movement and camera state
+type MovementSave struct {
+	IsFlying    bool    `json:"flying"`
+	IsCrouching bool    `json:"crouching"`
+	Stamina     float32 `json:"stamina"`
+	ThirdPerson bool    `json:"thirdPerson"`
+}
+
+// InventorySave contains the player's inventory
+type InventorySave struct {
+	Hotbar        []ItemStackSave `json:"hotbar"`
+	Main          []ItemStackSave `json:"main"`
+	SelectedIndex int             `json:"selected"`
+}
+
+// ItemStackSave contains a single inventory slot
+type ItemStackSave struct {
+	Type  uint8 `json:"type"`
+	Count int   `json:"count"`
 }
 
 // WorldSave contains world state
 type WorldSave struct {
 	Seed           int64                   `json:"seed"`
 	ModifiedChunks map[string]ChunkModSave `json:"modifiedChunks"`
+	Generator      *GeneratorSave          `json:"generator,omitempty"`
+	Time           *TimeSave               `json:"time,omitempty"`
+	Weather        *WeatherSave            `json:"weather,omitempty"`
+	Creatures      []CreatureSave          `json:"creatures,omitempty"`
+	Items          []ItemSave              `json:"items,omitempty"`
+}
+
+// GeneratorSave contains the terrain generator settings a world was created
+// with. They are fixed for the lifetime of the world so regenerated chunks
+// always match the saved modifications next to them.
+type GeneratorSave struct {
+	Version          int     `json:"version"`
+	Preset           string  `json:"preset"`
+	SeaLevel         int     `json:"seaLevel"`
+	TerrainAmplitude float32 `json:"terrainAmplitude"`
+	TreeDensity      float32 `json:"treeDensity"`
+	CaveFrequency    float32 `json:"caveFrequency"`
+	FlatLayers       string  `json:"flatLayers,omitempty"` // Superflat worlds only
+}
+
+// TimeSave contains the day/night cycle and calendar state. The season
+// follows from the day and the season length.
+type TimeSave struct {
+	Hour          float32 `json:"hour"`
+	Day           int     `json:"day"`
+	DaysPerSeason int     `json:"daysPerSeason,omitempty"`
+}
+
+// WeatherSave contains the current spell of weather. The spells that
+// follow are drawn from the world seed.
+type WeatherSave struct {
+	State   string  `json:"state"`
+	Spell   int     `json:"spell"`
+	Elapsed float32 `json:"elapsed"` // Seconds into the spell
+}
+
+// ItemSave contains an item lying in the world. Items still flying when
+// the world was saved are stored as the item they drop.
+type ItemSave struct {
+	Type     uint8      `json:"type"`
+	Count    int        `json:"count"`
+	Position [3]float32 `json:"position"`
+	Velocity [3]float32 `json:"velocity"`
+	Age      float32    `json:"age"` // Seconds since it was dropped
+}
+
+// CreatureSave contains a single creature
+type CreatureSave struct {
+	Template string     `json:"template"`
+	Biome    string     `json:"biome"`
+	Size     float32    `json:"size"`
+	Position [3]float32 `json:"position"`
+	Velocity [3]float32 `json:"velocity"`
+	Rotation float32    `json:"rotation"`
+	State    string     `json:"state"`
+	Health   int        `json:"health"`
+	HeldItem uint8      `json:"heldItem,omitempty"`
+
+	PrimaryColor   [3]float32     `json:"primaryColor"`
+	SecondaryColor [3]float32     `json:"secondaryColor"`
+	AccentColor    [3]float32     `json:"accentColor"`
+	BodyParts      []BodyPartSave `json:"bodyParts"`
+}
+
+// BodyPartSave contains a single creature body part
+type BodyPartSave struct {
+	Type   string     `json:"type"`
+	Size   [3]float32 `json:"size"`
+	Offset [3]float32 `json:"offset"`
 }
 
 // ChunkModSave contains modifications to a chunk
@@ -47,9 +146,24 @@ type BlockModSave struct {
 	Type uint8 `json:"type"`
 }
 
+// DefaultBackupCount is the number of previous saves kept per save name
+const DefaultBackupCount = 3
+
+// DefaultBackupInterval is the shortest time between backup rotations of a
+// save, matching the default autosave interval
+const DefaultBackupInterval = 5 * time.Minute
+
 // Manager handles save/load operations
 type Manager struct {
-	saveDir string
+	saveDir        string
+	backupCount    int
+	backupInterval time.Duration
+
+	// Serializes writes so background and foreground saves don't interleave
+	writeMu sync.Mutex
+
+	// When each save name last had its backups rotated, guarded by writeMu
+	lastRotation map[string]time.Time
 }
 
 // NewManager creates a new save manager
@@ -60,28 +174,69 @@ func NewManager() *Manager {
 		home = "."
 	}
 
-	saveDir := filepath.Join(home, ".voxelgame", "saves")
+	return NewManagerAt(filepath.Join(home, ".voxelgame", "saves"))
+}
 
+// NewManagerAt creates a save manager that stores saves in the given directory
+func NewManagerAt(saveDir string) *Manager {
 	// Create save directory if it doesn't exist
 	_ = os.MkdirAll(saveDir, 0755)
 
 	return &Manager{
-		saveDir: saveDir,
+		saveDir:        saveDir,
+		backupCount:    DefaultBackupCount,
+		backupInterval: DefaultBackupInterval,
+		lastRotation:   make(map[string]time.Time),
+	}
+}
+
+// SetBackupCount sets how many rotating backups are kept per save (0 disables them)
+func (m *Manager) SetBackupCount(count int) {
+	if count < 0 {
+		count = 0
 	}
+	m.backupCount = count
+}
+
+// SetBackupInterval sets the shortest time between backup rotations of a
+// save. Saves in between, like several pauses in a row, overwrite the save
+// without pushing older backups out.
+func (m *Manager) SetBackupInterval(interval time.Duration) {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+	m.backupInterval = interval
 }
 
-// Save saves the game state
+// Save saves the game state.
+// The file is written atomically and the previous save is kept as a backup,
+// at most once per backup interval.
 func (m *Manager) Save(saveName string, data SaveData) error {
 	data.Version = "1.0"
 	data.Timestamp = time.Now().Unix()
 
+	checksum, err := computeChecksum(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal save data: %w", err)
+	}
+	data.Checksum = checksum
+
 	jsonData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal save data: %w", err)
 	}
 
-	path := filepath.Join(m.saveDir, saveName+".json")
-	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	path := m.savePath(saveName)
+	if last, ok := m.lastRotation[saveName]; !ok || time.Since(last) >= m.backupInterval {
+		if err := rotateBackups(path, m.backupCount); err != nil {
+			fmt.Printf("[SaveManager] Warning: failed to rotate backups for %s: %v\n", saveName, err)
+		} else {
+			m.lastRotation[saveName] = time.Now()
+		}
+	}
+	if err := writeFileAtomic(path, jsonData, 0644); err != nil {
 		return fmt.Errorf("failed to write save file: %w", err)
 	}
 
@@ -89,10 +244,36 @@ func (m *Manager) Save(saveName string, data SaveData) error {
 	return nil
 }
 
-// Load loads the game state
+// Load loads the game state.
+// If the main file is missing or corrupt, the newest valid backup is used instead.
 func (m *Manager) Load(saveName string) (*SaveData, error) {
-	path := filepath.Join(m.saveDir, saveName+".json")
+	path := m.savePath(saveName)
+
+	data, err := readSaveFile(path)
+	if err == nil {
+		fmt.Printf("[SaveManager] Loaded game from %s\n", path)
+		return data, nil
+	}
+
+	for i := 1; i <= m.backupCount; i++ {
+		bak := backupPath(path, i)
+		if _, statErr := os.Stat(bak); statErr != nil {
+			continue
+		}
+		data, bakErr := readSaveFile(bak)
+		if bakErr != nil {
+			fmt.Printf("[SaveManager] Backup %s is unusable: %v\n", bak, bakErr)
+			continue
+		}
+		fmt.Printf("[SaveManager] %s is unusable (%v), recovered from %s\n", path, err, bak)
+		return data, nil
+	}
 
+	return nil, err
+}
+
+// readSaveFile reads, parses and verifies a single save file
+func readSaveFile(path string) (*SaveData, error) {
 	jsonData, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read save file: %w", err)
@@ -103,7 +284,10 @@ func (m *Manager) Load(saveName string) (*SaveData, error) {
 		return nil, fmt.Errorf("failed to parse save data: %w", err)
 	}
 
-	fmt.Printf("[SaveManager] Loaded game from %s\n", path)
+	if err := verifyChecksum(data); err != nil {
+		return nil, fmt.Errorf("save file is corrupt: %w", err)
+	}
+
 	return &data, nil
 }
 
@@ -136,17 +320,32 @@ func (m *Manager) ListSaves() ([]SaveInfo, error) {
 	return saves, nil
 }
 
-// DeleteSave deletes a save file
+// DeleteSave deletes a save file and its backups
 func (m *Manager) DeleteSave(saveName string) error {
-	path := filepath.Join(m.saveDir, saveName+".json")
+	path := m.savePath(saveName)
+	for i := 1; i <= m.backupCount; i++ {
+		_ = os.Remove(backupPath(path, i))
+	}
 	return os.Remove(path)
 }
 
-// Exists checks if a save exists
+// Exists checks if a save (or a backup of it) exists
 func (m *Manager) Exists(saveName string) bool {
-	path := filepath.Join(m.saveDir, saveName+".json")
-	_, err := os.Stat(path)
-	return err == nil
+	path := m.savePath(saveName)
+	if _, err := os.Stat(path); err == nil {
+		return true
+	}
+	for i := 1; i <= m.backupCount; i++ {
+		if _, err := os.Stat(backupPath(path, i)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// savePath returns the path of the main file for a save name
+func (m *Manager) savePath(saveName string) string {
+	return filepath.Join(m.saveDir, saveName+".json")
 }
 
 // SaveInfo contains information about a save
@@ -156,14 +355,8 @@ type SaveInfo struct {
 }
 
 // QuickSave is a convenience function for quick saving
-func (m *Manager) QuickSave(player PlayerSave, worldSeed int64) error {
-	return m.Save("quicksave", SaveData{
-		Player: player,
-		World: WorldSave{
-			Seed:           worldSeed,
-			ModifiedChunks: make(map[string]ChunkModSave),
-		},
-	})
+func (m *Manager) QuickSave(data SaveData) error {
+	return m.Save("quicksave", data)
 }
 
 // QuickLoad is a convenience function for quick loading
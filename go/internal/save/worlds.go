@@ -0,0 +1,404 @@
+// Package save provides named worlds, each stored in its own directory
+package save
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LevelSaveName is the save name used for a world's game state inside its directory
+const LevelSaveName = "level"
+
+// ChunkDirName is the subdirectory of a world holding its pre-generated chunks
+const ChunkDirName = "chunks"
+
+const (
+	worldInfoFile = "world.json"
+	thumbnailFile = "thumbnail.png"
+)
+
+// WorldInfo describes a named world
+type WorldInfo struct {
+	ID         string  `json:"-"` // Directory name, stable across renames
+	Name       string  `json:"name"`
+	Seed       int64   `json:"seed"`
+	Created    int64   `json:"created"`
+	LastPlayed int64   `json:"lastPlayed"`
+	PlayTime   float64 `json:"playTime"` // Seconds
+
+	HasThumbnail bool `json:"-"`
+}
+
+// WorldStore manages named worlds. Each world lives in its own directory
+// holding its info, game state (with backups) and thumbnail.
+type WorldStore struct {
+	root string
+	mu   sync.Mutex
+}
+
+// NewWorldStore creates a world store in the user's home directory
+func NewWorldStore() *WorldStore {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return NewWorldStoreAt(filepath.Join(home, ".voxelgame", "worlds"))
+}
+
+// NewWorldStoreAt creates a world store in the given directory
+func NewWorldStoreAt(root string) *WorldStore {
+	_ = os.MkdirAll(root, 0755)
+	return &WorldStore{root: root}
+}
+
+// Create creates a new, empty world directory
+func (s *WorldStore) Create(name string, seed int64) (*WorldInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "New World"
+	}
+
+	id, err := s.allocateID(name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	info := &WorldInfo{
+		ID:         id,
+		Name:       name,
+		Seed:       seed,
+		Created:    now,
+		LastPlayed: now,
+	}
+	if err := s.writeInfo(info); err != nil {
+		os.RemoveAll(s.worldDir(id))
+		return nil, err
+	}
+
+	fmt.Printf("[WorldStore] Created world %q in %s\n", name, s.worldDir(id))
+	return info, nil
+}
+
+// List returns all worlds, most recently played first
+func (s *WorldStore) List() ([]WorldInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return nil, err
+	}
+
+	var worlds []WorldInfo
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := s.readInfo(entry.Name())
+		if err != nil {
+			continue
+		}
+		worlds = append(worlds, *info)
+	}
+
+	sort.Slice(worlds, func(i, j int) bool {
+		return worlds[i].LastPlayed > worlds[j].LastPlayed
+	})
+	return worlds, nil
+}
+
+// Get returns the info of a single world
+func (s *WorldStore) Get(id string) (*WorldInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readInfo(id)
+}
+
+// Rename changes a world's display name. The directory is left as-is.
+func (s *WorldStore) Rename(id, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("world name cannot be empty")
+	}
+
+	info, err := s.readInfo(id)
+	if err != nil {
+		return err
+	}
+	info.Name = name
+	return s.writeInfo(info)
+}
+
+// Duplicate copies a world, including its saves and thumbnail, under a new name
+func (s *WorldStore) Duplicate(id, name string) (*WorldInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	src, err := s.readInfo(id)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = src.Name + " (copy)"
+	}
+
+	newID, err := s.allocateID(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := copyDirFiles(s.worldDir(id), s.worldDir(newID)); err != nil {
+		os.RemoveAll(s.worldDir(newID))
+		return nil, fmt.Errorf("failed to copy world: %w", err)
+	}
+
+	info := *src
+	info.ID = newID
+	info.Name = name
+	info.Created = time.Now().Unix()
+	if err := s.writeInfo(&info); err != nil {
+		os.RemoveAll(s.worldDir(newID))
+		return nil, err
+	}
+
+	fmt.Printf("[WorldStore] Duplicated world %q as %q\n", src.Name, name)
+	return &info, nil
+}
+
+// Delete removes a world and everything in its directory
+func (s *WorldStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.readInfo(id); err != nil {
+		return err
+	}
+	return os.RemoveAll(s.worldDir(id))
+}
+
+// Manager returns a save manager for the world's game state
+func (s *WorldStore) Manager(id string) *Manager {
+	return NewManagerAt(s.worldDir(id))
+}
+
+// ChunkDir returns the directory holding the world's pre-generated chunks
+func (s *WorldStore) ChunkDir(id string) string {
+	return filepath.Join(s.worldDir(id), ChunkDirName)
+}
+
+// RecordPlay updates the world's last played time and total play time
+func (s *WorldStore) RecordPlay(id string, playTime float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := s.readInfo(id)
+	if err != nil {
+		return err
+	}
+	info.LastPlayed = time.Now().Unix()
+	info.PlayTime = playTime
+	return s.writeInfo(info)
+}
+
+// SaveThumbnail writes the world's thumbnail image
+func (s *WorldStore) SaveThumbnail(id string, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeFileAtomic(filepath.Join(s.worldDir(id), thumbnailFile), buf.Bytes(), 0644)
+}
+
+// LoadThumbnail reads the world's thumbnail image
+func (s *WorldStore) LoadThumbnail(id string) (image.Image, error) {
+	f, err := os.Open(filepath.Join(s.worldDir(id), thumbnailFile))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// ImportLegacySaves turns each save in the old flat saves directory into a
+// world of the same name. Imported files are renamed so they aren't imported twice.
+func (s *WorldStore) ImportLegacySaves(legacy *Manager) (int, error) {
+	saves, err := legacy.ListSaves()
+	if err != nil {
+		return 0, err
+	}
+
+	imported := 0
+	for _, sv := range saves {
+		data, err := legacy.Load(sv.Name)
+		if err != nil {
+			fmt.Printf("[WorldStore] Skipping legacy save %s: %v\n", sv.Name, err)
+			continue
+		}
+
+		info, err := s.Create(sv.Name, data.World.Seed)
+		if err != nil {
+			return imported, err
+		}
+
+		// Write through the world's manager so the checksum is refreshed
+		if err := s.Manager(info.ID).Save(LevelSaveName, *data); err != nil {
+			s.Delete(info.ID)
+			return imported, err
+		}
+
+		s.mu.Lock()
+		info.LastPlayed = sv.Timestamp
+		err = s.writeInfo(info)
+		s.mu.Unlock()
+		if err != nil {
+			return imported, err
+		}
+
+		path := legacy.savePath(sv.Name)
+		if err := os.Rename(path, path+".imported"); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+
+	if imported > 0 {
+		fmt.Printf("[WorldStore] Imported %d legacy saves\n", imported)
+	}
+	return imported, nil
+}
+
+// worldDir returns the directory of a world
+func (s *WorldStore) worldDir(id string) string {
+	return filepath.Join(s.root, id)
+}
+
+// readInfo reads a world's info file. Must be called with mu held.
+func (s *WorldStore) readInfo(id string) (*WorldInfo, error) {
+	dir := s.worldDir(id)
+	raw, err := os.ReadFile(filepath.Join(dir, worldInfoFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read world info: %w", err)
+	}
+
+	var info WorldInfo
+	if err := json.Unmarshal(raw, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse world info: %w", err)
+	}
+	info.ID = id
+
+	if _, err := os.Stat(filepath.Join(dir, thumbnailFile)); err == nil {
+		info.HasThumbnail = true
+	}
+	return &info, nil
+}
+
+// writeInfo writes a world's info file. Must be called with mu held.
+func (s *WorldStore) writeInfo(info *WorldInfo) error {
+	raw, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.worldDir(info.ID), worldInfoFile), raw, 0644)
+}
+
+// allocateID creates a new, unique world directory named after the world.
+// Must be called with mu held.
+func (s *WorldStore) allocateID(name string) (string, error) {
+	base := slugify(name)
+	for i := 1; ; i++ {
+		id := base
+		if i > 1 {
+			id = fmt.Sprintf("%s-%d", base, i)
+		}
+		err := os.Mkdir(s.worldDir(id), 0755)
+		if err == nil {
+			return id, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("failed to create world directory: %w", err)
+		}
+	}
+}
+
+// slugify turns a world name into a safe directory name
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		slug = "world"
+	}
+	if len(slug) > 32 {
+		slug = slug[:32]
+	}
+	return slug
+}
+
+// copyDirFiles copies the regular files of src into dst
+func copyDirFiles(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies a single file
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -10,6 +10,7 @@ const (
 	StatePaused
 	StateSettings
 	StateLoading
+	StateWorldSelect
 )
 
 // GameStateManager manages game states and transitions
@@ -3,6 +3,7 @@ package ui
 
 import (
 	"voxelgame/internal/core/block"
+	"voxelgame/internal/save"
 )
 
 // InventorySlot represents a slot in the inventory
@@ -310,3 +311,32 @@ func (inv *Inventory) GetAllBlocksWithCounts() []BlockInventoryInfo {
 
 	return result
 }
+
+// SaveState returns the inventory contents in save format
+func (inv *Inventory) SaveState() save.InventorySave {
+	s := save.InventorySave{
+		Hotbar:        make([]save.ItemStackSave, len(inv.Hotbar)),
+		Main:          make([]save.ItemStackSave, len(inv.Main)),
+		SelectedIndex: inv.SelectedIndex,
+	}
+	for i, slot := range inv.Hotbar {
+		s.Hotbar[i] = save.ItemStackSave{Type: uint8(slot.BlockType), Count: slot.Count}
+	}
+	for i, slot := range inv.Main {
+		s.Main[i] = save.ItemStackSave{Type: uint8(slot.BlockType), Count: slot.Count}
+	}
+	return s
+}
+
+// RestoreState replaces the inventory contents with saved ones
+func (inv *Inventory) RestoreState(s save.InventorySave) {
+	inv.Hotbar = [9]InventorySlot{}
+	inv.Main = [27]InventorySlot{}
+	for i := 0; i < len(s.Hotbar) && i < len(inv.Hotbar); i++ {
+		inv.Hotbar[i] = InventorySlot{BlockType: block.Type(s.Hotbar[i].Type), Count: s.Hotbar[i].Count}
+	}
+	for i := 0; i < len(s.Main) && i < len(inv.Main); i++ {
+		inv.Main[i] = InventorySlot{BlockType: block.Type(s.Main[i].Type), Count: s.Main[i].Count}
+	}
+	inv.SelectSlot(s.SelectedIndex)
+}
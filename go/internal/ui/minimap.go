@@ -118,8 +118,24 @@ func (m *Minimap) Update(playerPos mgl32.Vec3, getBiome func(x, z int) string, g
 				c = color.RGBA{200, 220, 255, 255}
 			case "forest":
 				c = color.RGBA{30, 90, 40, 255}
+			case "birch_forest":
+				c = color.RGBA{60, 115, 50, 255}
 			case "mountains":
 				c = color.RGBA{100, 110, 115, 255}
+			case "taiga":
+				c = color.RGBA{40, 85, 65, 255}
+			case "swamp":
+				c = color.RGBA{55, 75, 40, 255}
+			case "savanna":
+				c = color.RGBA{150, 140, 70, 255}
+			case "jungle":
+				c = color.RGBA{20, 110, 30, 255}
+			case "badlands":
+				c = color.RGBA{170, 95, 50, 255}
+			case "beach", "ocean", "deep_ocean", "river":
+				c = color.RGBA{200, 185, 120, 255}
+			case "stony_shore":
+				c = color.RGBA{120, 120, 125, 255}
 			default:
 				c = color.RGBA{50, 100, 60, 255}
 			}
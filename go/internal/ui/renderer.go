@@ -3,6 +3,8 @@ package ui
 
 import (
 	"fmt"
+	"image"
+	"image/draw"
 	"voxelgame/internal/core/block"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
@@ -458,7 +460,11 @@ func (r *Renderer) DrawTimeIndicator(timeString string) {
 		return
 	}
 
-	width := float32(120)
+	// Wide enough for the text
+	width := float32(len(timeString)*12 + 24)
+	if width < 120 {
+		width = 120
+	}
 	height := float32(36)
 
 	// Position: Top Center
@@ -482,6 +488,26 @@ func (r *Renderer) DrawTimeIndicator(timeString string) {
 	r.DrawText(textX, y+8, 1.2, timeString, [4]float32{1, 0.9, 0.6, 1})
 }
 
+// DrawSaveIndicator renders a small save status label in the bottom-right corner
+func (r *Renderer) DrawSaveIndicator(status string) {
+	if r.shader == nil || status == "" {
+		return
+	}
+
+	textWidth := float32(len(status) * 12) // Approx
+	width := textWidth + 20
+	height := float32(30)
+
+	// Position: Bottom Right
+	x := float32(r.width) - width - 10
+	y := float32(r.height) - height - 10
+
+	// Background
+	r.DrawRect(x, y, width, height, [4]float32{0, 0, 0, 0.5})
+
+	r.DrawText(x+10, y+8, 1.2, status, [4]float32{0.8, 1, 0.8, 1})
+}
+
 // DrawIsometricCube renders a fake 3D cube for UI
 func (r *Renderer) DrawIsometricCube(x, y, size float32, color [3]float32) {
 	if r.shader == nil {
@@ -655,6 +681,70 @@ func (r *Renderer) DrawMinimap(textureID uint32) {
 	r.DrawText(x+size/2-25, y+size+8, 1.2, "RADAR", [4]float32{0.4, 1.0, 0.6, 1.0})
 }
 
+// DrawTexture draws a textured rectangle
+func (r *Renderer) DrawTexture(x, y, width, height float32, textureID uint32) {
+	if r.shader == nil {
+		return
+	}
+
+	gl.UseProgram(r.shader.ID)
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, textureID)
+
+	projection := mgl32.Ortho(0, float32(r.width), float32(r.height), 0, -1, 1)
+	projLoc := gl.GetUniformLocation(r.shader.ID, gl.Str("uProjection\x00"))
+	gl.UniformMatrix4fv(projLoc, 1, false, &projection[0])
+
+	colLoc := gl.GetUniformLocation(r.shader.ID, gl.Str("uColor\x00"))
+	gl.Uniform4f(colLoc, 1, 1, 1, 1)
+
+	useTexLoc := gl.GetUniformLocation(r.shader.ID, gl.Str("uUseTexture\x00"))
+	gl.Uniform1i(useTexLoc, 1)
+
+	texLoc := gl.GetUniformLocation(r.shader.ID, gl.Str("uTexture\x00"))
+	gl.Uniform1i(texLoc, 0)
+
+	uvLoc := gl.GetUniformLocation(r.shader.ID, gl.Str("uUVBounds\x00"))
+	gl.Uniform4f(uvLoc, 0, 0, 1, 1)
+
+	model := mgl32.Translate3D(x, y, 0).Mul4(mgl32.Scale3D(width, height, 1))
+	modLoc := gl.GetUniformLocation(r.shader.ID, gl.Str("uModel\x00"))
+	gl.UniformMatrix4fv(modLoc, 1, false, &model[0])
+
+	gl.BindVertexArray(r.quadVAO)
+	gl.DrawArrays(gl.TRIANGLES, 0, 6)
+	gl.BindVertexArray(0)
+}
+
+// NewImageTexture uploads an image as a texture for DrawTexture
+func NewImageTexture(img image.Image) uint32 {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+
+	var texture uint32
+	gl.GenTextures(1, &texture)
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	// Nearest filtering keeps small pixel-art thumbnails crisp
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexImage2D(
+		gl.TEXTURE_2D,
+		0,
+		gl.RGBA,
+		int32(rgba.Rect.Dx()),
+		int32(rgba.Rect.Dy()),
+		0,
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		gl.Ptr(rgba.Pix),
+	)
+	return texture
+}
+
 // Cleanup releases resources
 func (r *Renderer) Cleanup() {
 	if r.quadVAO != 0 {
@@ -17,6 +17,11 @@ type Settings struct {
 	MouseSensitivity float32
 	InvertY          bool
 
+	// Saving
+	AutosaveEnabled  bool
+	AutosaveInterval float32 // Seconds of play between autosaves
+	SaveOnPause      bool
+
 	// Audio
 	MasterVolume float32
 	MusicVolume  float32
@@ -35,6 +40,7 @@ type Settings struct {
 	SeaLevel         int     // Water level (5-30)
 	TreeDensity      float32 // Tree spawn density (0.0-0.2)
 	CaveFrequency    float32 // Cave generation frequency (0.3-0.8)
+	WorldType        string  // Terrain preset of new worlds
 }
 
 // DefaultSettings returns default settings
@@ -54,6 +60,11 @@ func DefaultSettings() *Settings {
 		MouseSensitivity: 0.1,
 		InvertY:          false,
 
+		// Saving
+		AutosaveEnabled:  true,
+		AutosaveInterval: 300.0, // 5 minutes
+		SaveOnPause:      true,
+
 		// Audio
 		MasterVolume: 1.0,
 		MusicVolume:  0.7,
@@ -72,6 +83,7 @@ func DefaultSettings() *Settings {
 		SeaLevel:         12,
 		TreeDensity:      0.05,
 		CaveFrequency:    0.6,
+		WorldType:        "default",
 	}
 }
 
@@ -83,6 +95,10 @@ type SettingItem struct {
 	Min, Max float32
 	Options  []string
 	OnChange func(value interface{})
+
+	// NewWorldOnly settings are captured when a world is created and
+	// cannot be changed while that world is loaded
+	NewWorldOnly bool
 }
 
 // SettingType defines the type of setting
@@ -101,6 +117,9 @@ type SettingsMenu struct {
 	SelectedIndex int
 	IsVisible     bool
 	Settings      *Settings
+
+	// WorldLoaded locks NewWorldOnly settings
+	WorldLoaded bool
 }
 
 // NewSettingsMenu creates a settings menu
@@ -185,6 +204,29 @@ func NewSettingsMenu(settings *Settings) *SettingsMenu {
 				settings.VSync = v.(bool)
 			},
 		},
+		// Saving
+		{
+			Name: "Autosave",
+			Type: SettingBool,
+			OnChange: func(v interface{}) {
+				settings.AutosaveEnabled = v.(bool)
+			},
+		},
+		{
+			Name: "Autosave Interval (sec)",
+			Type: SettingFloat,
+			Min:  30, Max: 1800,
+			OnChange: func(v interface{}) {
+				settings.AutosaveInterval = v.(float32)
+			},
+		},
+		{
+			Name: "Save On Pause",
+			Type: SettingBool,
+			OnChange: func(v interface{}) {
+				settings.SaveOnPause = v.(bool)
+			},
+		},
 		// Time of Day settings
 		{
 			Name: "Day Duration (sec)",
@@ -207,6 +249,7 @@ func NewSettingsMenu(settings *Settings) *SettingsMenu {
 			Name: "Terrain Amplitude",
 			Type: SettingFloat,
 			Min:  10, Max: 60,
+			NewWorldOnly: true,
 			OnChange: func(v interface{}) {
 				settings.TerrainAmplitude = v.(float32)
 			},
@@ -215,6 +258,7 @@ func NewSettingsMenu(settings *Settings) *SettingsMenu {
 			Name: "Sea Level",
 			Type: SettingInt,
 			Min:  5, Max: 30,
+			NewWorldOnly: true,
 			OnChange: func(v interface{}) {
 				settings.SeaLevel = v.(int)
 			},
@@ -223,6 +267,7 @@ func NewSettingsMenu(settings *Settings) *SettingsMenu {
 			Name: "Tree Density",
 			Type: SettingFloat,
 			Min:  0.0, Max: 0.2,
+			NewWorldOnly: true,
 			OnChange: func(v interface{}) {
 				settings.TreeDensity = v.(float32)
 			},
@@ -231,22 +276,36 @@ func NewSettingsMenu(settings *Settings) *SettingsMenu {
 			Name: "Cave Frequency",
 			Type: SettingFloat,
 			Min:  0.3, Max: 0.8,
+			NewWorldOnly: true,
 			OnChange: func(v interface{}) {
 				settings.CaveFrequency = v.(float32)
 			},
 		},
+		{
+			Name:         "World Type",
+			Type:         SettingOption,
+			Options:      []string{"default", "density", "amplified", "islands", "superflat", "void"}, // terrain.Presets
+			NewWorldOnly: true,
+			OnChange: func(v interface{}) {
+				settings.WorldType = v.(string)
+			},
+		},
 	}
 
 	return sm
 }
 
-// ToggleCurrentSetting toggles a bool setting or adjusts numeric
+// ToggleCurrentSetting toggles a bool setting, adjusts numeric ones or
+// cycles through options
 func (sm *SettingsMenu) ToggleCurrentSetting(delta float32) {
 	if sm.SelectedIndex < 0 || sm.SelectedIndex >= len(sm.Items) {
 		return
 	}
 
 	item := &sm.Items[sm.SelectedIndex]
+	if sm.IsLocked(item) {
+		return
+	}
 
 	switch item.Type {
 	case SettingBool:
@@ -275,9 +334,30 @@ func (sm *SettingsMenu) ToggleCurrentSetting(delta float32) {
 			newVal = int(item.Max)
 		}
 		item.OnChange(newVal)
+
+	case SettingOption:
+		current := sm.getSettingValue(item.Name).(string)
+		index := 0
+		for i, option := range item.Options {
+			if option == current {
+				index = i
+			}
+		}
+		n := len(item.Options)
+		if delta < 0 {
+			index = (index + n - 1) % n
+		} else {
+			index = (index + 1) % n
+		}
+		item.OnChange(item.Options[index])
 	}
 }
 
+// IsLocked returns true if the item can't be changed right now
+func (sm *SettingsMenu) IsLocked(item *SettingItem) bool {
+	return item.NewWorldOnly && sm.WorldLoaded
+}
+
 func (sm *SettingsMenu) getSettingValue(name string) interface{} {
 	switch name {
 	case "Render Distance":
@@ -300,6 +380,13 @@ func (sm *SettingsMenu) getSettingValue(name string) interface{} {
 		return sm.Settings.InvertY
 	case "VSync":
 		return sm.Settings.VSync
+	// Saving
+	case "Autosave":
+		return sm.Settings.AutosaveEnabled
+	case "Autosave Interval (sec)":
+		return sm.Settings.AutosaveInterval
+	case "Save On Pause":
+		return sm.Settings.SaveOnPause
 	// Time of Day
 	case "Day Duration (sec)":
 		return sm.Settings.DayDuration
@@ -314,6 +401,8 @@ func (sm *SettingsMenu) getSettingValue(name string) interface{} {
 		return sm.Settings.TreeDensity
 	case "Cave Frequency":
 		return sm.Settings.CaveFrequency
+	case "World Type":
+		return sm.Settings.WorldType
 	}
 	return nil
 }
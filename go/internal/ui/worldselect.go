@@ -0,0 +1,412 @@
+// Package ui provides the world selection screen
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"voxelgame/internal/save"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// WorldSelectMode is the current interaction of the world selection screen
+type WorldSelectMode int
+
+const (
+	WorldSelectBrowse WorldSelectMode = iota
+	WorldSelectCreate
+	WorldSelectRename
+	WorldSelectConfirmDelete
+)
+
+// Text fields of the create dialog
+const (
+	worldFieldName = iota
+	worldFieldSeed
+	worldFieldLayers
+	worldFieldCount
+)
+
+// maxWorldNameLength limits typed world names
+const maxWorldNameLength = 32
+
+// maxLayersLength limits typed superflat layers
+const maxLayersLength = 32
+
+// WorldSelectScreen lists saved worlds and lets the player create,
+// play, rename, duplicate and delete them
+type WorldSelectScreen struct {
+	Worlds        []save.WorldInfo
+	SelectedIndex int
+	IsVisible     bool
+	Mode          WorldSelectMode
+
+	// Text input for the create and rename dialogs
+	NameInput   string
+	SeedInput   string
+	LayersInput string // Superflat layers, empty for the default
+	ActiveField int
+
+	// Status line (e.g. errors)
+	Message string
+
+	// Thumbnail textures by world ID
+	Thumbnails map[string]uint32
+
+	// Callbacks
+	OnPlay      func(info save.WorldInfo)
+	OnCreate    func(name, seed, layers string)
+	OnRename    func(info save.WorldInfo, name string)
+	OnDuplicate func(info save.WorldInfo)
+	OnDelete    func(info save.WorldInfo)
+	OnBack      func()
+}
+
+// NewWorldSelectScreen creates a world selection screen
+func NewWorldSelectScreen() *WorldSelectScreen {
+	return &WorldSelectScreen{
+		Thumbnails: make(map[string]uint32),
+	}
+}
+
+// SetWorlds replaces the listed worlds, keeping the selection in range
+func (ws *WorldSelectScreen) SetWorlds(worlds []save.WorldInfo) {
+	ws.Worlds = worlds
+	if ws.SelectedIndex >= len(worlds) {
+		ws.SelectedIndex = len(worlds) - 1
+	}
+	if ws.SelectedIndex < 0 {
+		ws.SelectedIndex = 0
+	}
+}
+
+// Selected returns the selected world, or nil if there are none
+func (ws *WorldSelectScreen) Selected() *save.WorldInfo {
+	if ws.SelectedIndex < 0 || ws.SelectedIndex >= len(ws.Worlds) {
+		return nil
+	}
+	return &ws.Worlds[ws.SelectedIndex]
+}
+
+// SelectNext selects the next world
+func (ws *WorldSelectScreen) SelectNext() {
+	if len(ws.Worlds) > 0 {
+		ws.SelectedIndex = (ws.SelectedIndex + 1) % len(ws.Worlds)
+	}
+}
+
+// SelectPrevious selects the previous world
+func (ws *WorldSelectScreen) SelectPrevious() {
+	if len(ws.Worlds) > 0 {
+		ws.SelectedIndex = (ws.SelectedIndex - 1 + len(ws.Worlds)) % len(ws.Worlds)
+	}
+}
+
+// BeginCreate opens the create world dialog
+func (ws *WorldSelectScreen) BeginCreate() {
+	ws.Mode = WorldSelectCreate
+	ws.NameInput = "New World"
+	ws.SeedInput = ""
+	ws.LayersInput = ""
+	ws.ActiveField = worldFieldName
+	ws.Message = ""
+}
+
+// BeginRename opens the rename dialog for the selected world
+func (ws *WorldSelectScreen) BeginRename() {
+	if selected := ws.Selected(); selected != nil {
+		ws.Mode = WorldSelectRename
+		ws.NameInput = selected.Name
+		ws.ActiveField = worldFieldName
+		ws.Message = ""
+	}
+}
+
+// BeginDelete asks for confirmation before deleting the selected world
+func (ws *WorldSelectScreen) BeginDelete() {
+	if ws.Selected() != nil {
+		ws.Mode = WorldSelectConfirmDelete
+		ws.Message = ""
+	}
+}
+
+// IsEditingText returns true while a dialog with text fields is open
+func (ws *WorldSelectScreen) IsEditingText() bool {
+	return ws.Mode == WorldSelectCreate || ws.Mode == WorldSelectRename
+}
+
+// TypeText appends typed characters to the active text field
+func (ws *WorldSelectScreen) TypeText(text string) {
+	if !ws.IsEditingText() {
+		return
+	}
+	for _, r := range text {
+		if r < 32 || r > 126 {
+			continue // The UI font only has printable ASCII
+		}
+		switch ws.ActiveField {
+		case worldFieldSeed:
+			if len(ws.SeedInput) < maxWorldNameLength {
+				ws.SeedInput += string(r)
+			}
+		case worldFieldLayers:
+			if len(ws.LayersInput) < maxLayersLength {
+				ws.LayersInput += string(r)
+			}
+		default:
+			if len(ws.NameInput) < maxWorldNameLength {
+				ws.NameInput += string(r)
+			}
+		}
+	}
+}
+
+// Backspace deletes the last character of the active text field
+func (ws *WorldSelectScreen) Backspace() {
+	switch ws.ActiveField {
+	case worldFieldSeed:
+		if len(ws.SeedInput) > 0 {
+			ws.SeedInput = ws.SeedInput[:len(ws.SeedInput)-1]
+		}
+	case worldFieldLayers:
+		if len(ws.LayersInput) > 0 {
+			ws.LayersInput = ws.LayersInput[:len(ws.LayersInput)-1]
+		}
+	default:
+		if len(ws.NameInput) > 0 {
+			ws.NameInput = ws.NameInput[:len(ws.NameInput)-1]
+		}
+	}
+}
+
+// NextField switches between the name, seed and layers fields when
+// creating a world
+func (ws *WorldSelectScreen) NextField() {
+	if ws.Mode == WorldSelectCreate {
+		ws.ActiveField = (ws.ActiveField + 1) % worldFieldCount
+	}
+}
+
+// Confirm plays the selected world or confirms the open dialog
+func (ws *WorldSelectScreen) Confirm() {
+	mode := ws.Mode
+	ws.Mode = WorldSelectBrowse
+
+	switch mode {
+	case WorldSelectBrowse:
+		if selected := ws.Selected(); selected != nil && ws.OnPlay != nil {
+			ws.OnPlay(*selected)
+		}
+	case WorldSelectCreate:
+		if ws.OnCreate != nil {
+			ws.OnCreate(ws.NameInput, ws.SeedInput, ws.LayersInput)
+		}
+	case WorldSelectRename:
+		if selected := ws.Selected(); selected != nil && ws.OnRename != nil {
+			ws.OnRename(*selected, ws.NameInput)
+		}
+	case WorldSelectConfirmDelete:
+		if selected := ws.Selected(); selected != nil && ws.OnDelete != nil {
+			ws.OnDelete(*selected)
+		}
+	}
+}
+
+// Duplicate copies the selected world
+func (ws *WorldSelectScreen) Duplicate() {
+	if selected := ws.Selected(); selected != nil && ws.OnDuplicate != nil {
+		ws.OnDuplicate(*selected)
+	}
+}
+
+// Cancel closes the open dialog, or leaves the screen when browsing
+func (ws *WorldSelectScreen) Cancel() {
+	if ws.Mode != WorldSelectBrowse {
+		ws.Mode = WorldSelectBrowse
+		return
+	}
+	if ws.OnBack != nil {
+		ws.OnBack()
+	}
+}
+
+// ClearThumbnails releases all thumbnail textures
+func (ws *WorldSelectScreen) ClearThumbnails() {
+	for id, tex := range ws.Thumbnails {
+		gl.DeleteTextures(1, &tex)
+		delete(ws.Thumbnails, id)
+	}
+}
+
+// RenderWorldSelect renders the world selection screen
+func (mr *MenuRenderer) RenderWorldSelect(ws *WorldSelectScreen, screenWidth, screenHeight int) {
+	if ws == nil || !ws.IsVisible || mr.uiRenderer == nil {
+		return
+	}
+	r := mr.uiRenderer
+
+	// Background overlay
+	r.DrawRect(0, 0, float32(screenWidth), float32(screenHeight), [4]float32{0.1, 0.1, 0.15, 0.95})
+
+	// Panel
+	panelWidth := float32(720)
+	panelHeight := float32(600)
+	panelX := (float32(screenWidth) - panelWidth) / 2
+	panelY := (float32(screenHeight) - panelHeight) / 2
+	r.DrawRect(panelX, panelY, panelWidth, panelHeight, [4]float32{0.15, 0.15, 0.2, 0.9})
+	r.DrawRect(panelX, panelY, panelWidth, 3, [4]float32{0.3, 0.5, 0.8, 1.0})
+	r.DrawRect(panelX, panelY+panelHeight-3, panelWidth, 3, [4]float32{0.3, 0.5, 0.8, 1.0})
+
+	// Title bar
+	titleHeight := float32(60)
+	r.DrawRect(panelX, panelY, panelWidth, titleHeight, [4]float32{0.2, 0.3, 0.5, 1.0})
+	r.DrawText(panelX+panelWidth/2-110, panelY+15, 3.0, "SELECT WORLD", [4]float32{1, 1, 1, 1})
+
+	// World list
+	itemHeight := float32(80)
+	listY := panelY + titleHeight + 15
+	maxVisible := 5
+
+	if len(ws.Worlds) == 0 {
+		r.DrawText(panelX+40, listY+20, 2.0, "No worlds yet. Press N to create one.", [4]float32{0.7, 0.7, 0.7, 1})
+	}
+
+	// Scroll so the selection stays visible
+	first := 0
+	if ws.SelectedIndex >= maxVisible {
+		first = ws.SelectedIndex - maxVisible + 1
+	}
+
+	for i := first; i < len(ws.Worlds) && i < first+maxVisible; i++ {
+		info := ws.Worlds[i]
+		y := listY + float32(i-first)*(itemHeight+5)
+		selected := i == ws.SelectedIndex
+
+		bgColor := [4]float32{0.15, 0.15, 0.2, 0.5}
+		if selected {
+			bgColor = [4]float32{0.3, 0.4, 0.6, 0.8}
+		}
+		r.DrawRect(panelX+20, y, panelWidth-40, itemHeight, bgColor)
+		if selected {
+			r.DrawRect(panelX+20, y, 4, itemHeight, [4]float32{1, 0.8, 0.2, 1})
+		}
+
+		// Thumbnail
+		thumbSize := itemHeight - 16
+		thumbX := panelX + 32
+		if tex, ok := ws.Thumbnails[info.ID]; ok {
+			r.DrawTexture(thumbX, y+8, thumbSize, thumbSize, tex)
+		} else {
+			r.DrawRect(thumbX, y+8, thumbSize, thumbSize, [4]float32{0.08, 0.08, 0.12, 1})
+		}
+
+		// Details
+		textX := thumbX + thumbSize + 16
+		nameColor := [4]float32{0.85, 0.85, 0.85, 1}
+		if selected {
+			nameColor = [4]float32{1, 1, 1, 1}
+		}
+		r.DrawText(textX, y+10, 2.0, info.Name, nameColor)
+
+		details := fmt.Sprintf("Last played %s   Play time %s",
+			time.Unix(info.LastPlayed, 0).Format("2006-01-02 15:04"), FormatPlayTime(info.PlayTime))
+		r.DrawText(textX, y+38, 1.3, details, [4]float32{0.65, 0.65, 0.7, 1})
+		r.DrawText(textX, y+58, 1.2, fmt.Sprintf("Seed %d", info.Seed), [4]float32{0.5, 0.5, 0.55, 1})
+	}
+
+	// Status message
+	if ws.Message != "" {
+		r.DrawText(panelX+20, panelY+panelHeight-70, 1.5, ws.Message, [4]float32{1, 0.5, 0.4, 1})
+	}
+
+	// Controls
+	r.DrawText(panelX+20, panelY+panelHeight-40, 1.4,
+		"ENTER play  N new  R rename  C copy  DEL delete  ESC back", [4]float32{0.6, 0.6, 0.6, 1})
+
+	switch ws.Mode {
+	case WorldSelectCreate:
+		mr.renderWorldDialog(ws, "CREATE WORLD", screenWidth, screenHeight, true)
+	case WorldSelectRename:
+		mr.renderWorldDialog(ws, "RENAME WORLD", screenWidth, screenHeight, false)
+	case WorldSelectConfirmDelete:
+		mr.renderDeleteDialog(ws, screenWidth, screenHeight)
+	}
+}
+
+// renderWorldDialog renders the create/rename dialog with its text fields
+func (mr *MenuRenderer) renderWorldDialog(ws *WorldSelectScreen, title string, screenWidth, screenHeight int, withSeed bool) {
+	r := mr.uiRenderer
+
+	width := float32(500)
+	height := float32(170)
+	if withSeed {
+		height = 310
+	}
+	x := (float32(screenWidth) - width) / 2
+	y := (float32(screenHeight) - height) / 2
+
+	r.DrawRect(0, 0, float32(screenWidth), float32(screenHeight), [4]float32{0, 0, 0, 0.5})
+	r.DrawRect(x, y, width, height, [4]float32{0.12, 0.12, 0.18, 0.98})
+	r.DrawRect(x, y, width, 3, [4]float32{0.3, 0.5, 0.8, 1.0})
+	r.DrawText(x+20, y+15, 2.2, title, [4]float32{1, 1, 1, 1})
+
+	drawField := func(label, value string, fy float32, active bool) {
+		r.DrawText(x+20, fy, 1.5, label, [4]float32{0.7, 0.7, 0.7, 1})
+		bg := [4]float32{0.08, 0.08, 0.1, 1}
+		if active {
+			bg = [4]float32{0.2, 0.25, 0.35, 1}
+			value += "_"
+		}
+		r.DrawRect(x+20, fy+22, width-40, 32, bg)
+		r.DrawText(x+28, fy+30, 1.8, value, [4]float32{1, 1, 1, 1})
+	}
+
+	drawField("Name", ws.NameInput, y+55, ws.ActiveField == worldFieldName)
+	if withSeed {
+		seed := ws.SeedInput
+		if seed == "" && ws.ActiveField != worldFieldSeed {
+			seed = "(random)"
+		}
+		drawField("Seed", seed, y+125, ws.ActiveField == worldFieldSeed)
+
+		layers := ws.LayersInput
+		if layers == "" && ws.ActiveField != worldFieldLayers {
+			layers = "(default)"
+		}
+		drawField("Superflat Layers", layers, y+195, ws.ActiveField == worldFieldLayers)
+	}
+
+	help := "ENTER confirm  ESC cancel"
+	if withSeed {
+		help = "TAB next field  ENTER create  ESC cancel"
+	}
+	r.DrawText(x+20, y+height-30, 1.3, help, [4]float32{0.6, 0.6, 0.6, 1})
+}
+
+// renderDeleteDialog renders the delete confirmation dialog
+func (mr *MenuRenderer) renderDeleteDialog(ws *WorldSelectScreen, screenWidth, screenHeight int) {
+	selected := ws.Selected()
+	if selected == nil {
+		return
+	}
+	r := mr.uiRenderer
+
+	width := float32(500)
+	height := float32(140)
+	x := (float32(screenWidth) - width) / 2
+	y := (float32(screenHeight) - height) / 2
+
+	r.DrawRect(0, 0, float32(screenWidth), float32(screenHeight), [4]float32{0, 0, 0, 0.5})
+	r.DrawRect(x, y, width, height, [4]float32{0.18, 0.1, 0.1, 0.98})
+	r.DrawRect(x, y, width, 3, [4]float32{0.8, 0.2, 0.2, 1.0})
+	r.DrawText(x+20, y+15, 2.2, "DELETE WORLD", [4]float32{1, 1, 1, 1})
+	r.DrawText(x+20, y+55, 1.6, fmt.Sprintf("Delete \"%s\" forever?", selected.Name), [4]float32{1, 0.8, 0.8, 1})
+	r.DrawText(x+20, y+height-30, 1.3, "ENTER delete  ESC cancel", [4]float32{0.6, 0.6, 0.6, 1})
+}
+
+// FormatPlayTime formats seconds of play as e.g. "2h 05m"
+func FormatPlayTime(seconds float64) string {
+	total := int(seconds) / 60
+	return fmt.Sprintf("%dh %02dm", total/60, total%60)
+}
@@ -0,0 +1,225 @@
+// Package world provides periodic background saving
+package world
+
+import (
+	"fmt"
+	"image"
+	"sync"
+	"time"
+
+	"voxelgame/internal/core/chunk"
+	"voxelgame/internal/headless"
+	"voxelgame/internal/save"
+)
+
+// AutosaveConfig controls periodic background saving
+type AutosaveConfig struct {
+	Enabled     bool
+	Interval    float32 // Seconds of play between autosaves
+	SaveName    string
+	SaveOnPause bool // Also autosave when the game is paused
+}
+
+// DefaultAutosaveConfig returns default autosave settings
+func DefaultAutosaveConfig() AutosaveConfig {
+	return AutosaveConfig{
+		Enabled:     true,
+		Interval:    300.0, // 5 minutes
+		SaveName:    "autosave",
+		SaveOnPause: true,
+	}
+}
+
+// SaveStatus describes the background save state for the HUD
+type SaveStatus struct {
+	InProgress bool
+	LastSave   time.Time // When the last background save finished
+	LastError  error     // Error of the last background save, if it failed
+}
+
+// WorldSnapshot is a point-in-time copy of everything needed to write a save.
+// It is taken on the game thread and owns its data, so it can be serialized
+// on a background goroutine while the world keeps changing.
+type WorldSnapshot struct {
+	Seed          int64
+	Generator     save.GeneratorSave
+	Player        save.PlayerSave
+	Modifications map[string][]chunk.BlockModificationWorld
+	Time          save.TimeSave
+	Weather       save.WeatherSave
+	Creatures     []save.CreatureSave
+	Items         []save.ItemSave
+
+	// Not part of the save file, passed to OnSaved
+	PlayTime  float64
+	Thumbnail *image.RGBA
+}
+
+// autosaver tracks the autosave timer and in-flight background saves
+type autosaver struct {
+	config  AutosaveConfig
+	elapsed float32
+
+	mu         sync.Mutex
+	inProgress bool
+	lastSave   time.Time
+	lastErr    error
+	done       sync.WaitGroup
+}
+
+// ConfigureAutosave updates the autosave settings
+func (w *World) ConfigureAutosave(config AutosaveConfig) {
+	if config.SaveName == "" {
+		config.SaveName = DefaultAutosaveConfig().SaveName
+	}
+	w.autosave.config = config
+
+	// Frequent saves shouldn't push every backup out
+	if w.SaveManager != nil && config.Interval > 0 {
+		w.SaveManager.SetBackupInterval(time.Duration(config.Interval * float32(time.Second)))
+	}
+}
+
+// GetAutosaveConfig returns the current autosave settings
+func (w *World) GetAutosaveConfig() AutosaveConfig {
+	return w.autosave.config
+}
+
+// Snapshot copies the current world and player state for saving
+func (w *World) Snapshot() WorldSnapshot {
+	return WorldSnapshot{
+		Seed:          w.Seed,
+		Generator:     w.GeneratorSave(),
+		Player:        w.playerState(),
+		Modifications: w.ChunkManager.GetAllModifications(),
+		Time: save.TimeSave{
+			Hour:          w.TimeOfDay.CurrentHour,
+			Day:           w.TimeOfDay.Day,
+			DaysPerSeason: w.TimeOfDay.DaysPerSeason,
+		},
+		Weather: save.WeatherSave{
+			State:   w.Weather.State.String(),
+			Spell:   w.Weather.Spell,
+			Elapsed: w.Weather.Elapsed,
+		},
+		Creatures: w.CreatureManager.SaveState(),
+		Items:     w.saveItems(),
+		PlayTime:  w.PlayTime,
+		Thumbnail: w.Thumbnail(ThumbnailSize),
+	}
+}
+
+// SaveAsync snapshots the world and writes it on a background goroutine.
+// Returns false if another background save is still running.
+func (w *World) SaveAsync(saveName string) bool {
+	a := &w.autosave
+
+	a.mu.Lock()
+	if a.inProgress {
+		a.mu.Unlock()
+		return false
+	}
+	a.inProgress = true
+	a.mu.Unlock()
+
+	snapshot := w.Snapshot()
+	manager := w.SaveManager
+	onSaved := w.OnSaved
+
+	a.done.Add(1)
+	go func() {
+		defer a.done.Done()
+
+		err := manager.Save(saveName, snapshot.ToSaveData())
+		if err != nil {
+			fmt.Printf("Failed to save %s: %v\n", saveName, err)
+		} else if onSaved != nil {
+			onSaved(snapshot)
+		}
+
+		a.mu.Lock()
+		a.inProgress = false
+		a.lastErr = err
+		a.lastSave = time.Now()
+		a.mu.Unlock()
+	}()
+
+	return true
+}
+
+// Autosave triggers a background autosave and resets the timer
+func (w *World) Autosave() bool {
+	if !w.autosave.config.Enabled {
+		return false
+	}
+	w.autosave.elapsed = 0
+	return w.SaveAsync(w.autosave.config.SaveName)
+}
+
+// OnPause autosaves if save-on-pause is enabled
+func (w *World) OnPause() {
+	if w.autosave.config.SaveOnPause {
+		w.Autosave()
+	}
+}
+
+// WaitForSave blocks until any in-flight background save has finished
+func (w *World) WaitForSave() {
+	w.autosave.done.Wait()
+}
+
+// GetSaveStatus returns the background save state
+func (w *World) GetSaveStatus() SaveStatus {
+	a := &w.autosave
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return SaveStatus{
+		InProgress: a.inProgress,
+		LastSave:   a.lastSave,
+		LastError:  a.lastErr,
+	}
+}
+
+// updateAutosave advances the autosave timer
+func (w *World) updateAutosave(dt float32) {
+	a := &w.autosave
+	if !a.config.Enabled || a.config.Interval <= 0 {
+		return
+	}
+
+	a.elapsed += dt
+	if a.elapsed >= a.config.Interval {
+		w.Autosave()
+	}
+}
+
+// playerState returns the player state to save
+func (w *World) playerState() save.PlayerSave {
+	if w.PlayerState != nil {
+		return w.PlayerState()
+	}
+	return save.PlayerSave{
+		PositionX: float32(w.playerX),
+		PositionY: float32(w.playerY),
+		PositionZ: float32(w.playerZ),
+	}
+}
+
+// ToSaveData converts the snapshot to the save file format
+func (s WorldSnapshot) ToSaveData() save.SaveData {
+	generator := s.Generator
+	timeSave := s.Time
+	weatherSave := s.Weather
+	return save.SaveData{
+		Player: s.Player,
+		World: save.WorldSave{
+			Seed:           s.Seed,
+			ModifiedChunks: headless.ModificationsToSave(s.Modifications),
+			Generator:      &generator,
+			Time:           &timeSave,
+			Weather:        &weatherSave,
+			Creatures:      s.Creatures,
+			Items:          s.Items,
+		},
+	}
+}
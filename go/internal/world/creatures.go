@@ -4,7 +4,10 @@ package world
 import (
 	"math"
 
+	"voxelgame/internal/core/block"
 	"voxelgame/internal/generation/entity"
+	"voxelgame/internal/generation/terrain"
+	"voxelgame/internal/save"
 	vmath "voxelgame/pkg/math"
 
 	"github.com/go-gl/mathgl/mgl32"
@@ -15,8 +18,12 @@ type CreatureManager struct {
 	// Creature generator
 	generator *entity.Generator
 
-	// Active creatures
-	creatures []*entity.Creature
+	// Biomes, for their spawn tables, and the season choosing between them
+	biomes *terrain.BiomeRegistry
+	season Season
+
+	// Entities of the world, creatures being those with a brain
+	entities *entity.Registry
 
 	// Configuration
 	maxCreatures      int
@@ -24,100 +31,57 @@ type CreatureManager struct {
 	despawnRadius     float32
 	creaturesPerChunk int
 
+	// Dungeon spawners
+	spawnerTimer    float32
+	spawnerInterval float32 // Seconds between spawns
+	spawnerRange    float32 // Spawners only work with the player this close
+	spawnerRadius   float32 // Radius creatures are counted in around a spawner
+	spawnerLimit    int     // Creatures a spawner keeps around it
+
 	// RNG
 	rng *vmath.SeededRNG
 }
 
-// NewCreatureManager creates a new creature manager
-func NewCreatureManager(seed int64) *CreatureManager {
+// NewCreatureManager creates a new creature manager spawning into the
+// world's entities
+func NewCreatureManager(seed int64, biomes *terrain.BiomeRegistry, entities *entity.Registry) *CreatureManager {
 	return &CreatureManager{
 		generator:         entity.NewGenerator(seed),
-		creatures:         make([]*entity.Creature, 0, 100),
+		biomes:            biomes,
+		entities:          entities,
 		maxCreatures:      50,
 		spawnRadius:       50,
 		despawnRadius:     80,
 		creaturesPerChunk: 2,
+		spawnerInterval:   2,
+		spawnerRange:      16,
+		spawnerRadius:     6,
+		spawnerLimit:      3,
 		rng:               vmath.NewSeededRNG(seed + 5000),
 	}
 }
 
-// Update updates all creatures and handles spawning/despawning
-func (cm *CreatureManager) Update(dt float32, playerPos mgl32.Vec3, getBiome func(x, z int) string, getHeight func(x, z int) int) {
-	// Update existing creatures
-	for i := len(cm.creatures) - 1; i >= 0; i-- {
-		creature := cm.creatures[i]
-
-		// Update AI
-		creature.Update(dt, playerPos)
-
-		// Ground creature to terrain
-		cm.groundCreature(creature, getHeight)
-
-		// Check despawn distance
-		dx := creature.Position.X() - playerPos.X()
-		dz := creature.Position.Z() - playerPos.Z()
-		dist := float32(math.Sqrt(float64(dx*dx + dz*dz)))
-
-		if dist > cm.despawnRadius {
-			// Remove creature
-			cm.creatures = append(cm.creatures[:i], cm.creatures[i+1:]...)
+// Update despawns creatures far from the player and spawns new ones.
+// The creatures themselves are moved by the world's entity systems.
+func (cm *CreatureManager) Update(playerPos mgl32.Vec3, getBiome func(x, z int) string, getHeight func(x, z int) int) {
+	cm.entities.Brains.Each(func(id entity.ID, _ *entity.Brain) {
+		t := cm.entities.Transforms.Get(id)
+		if t == nil {
+			return
 		}
-	}
+		dx := t.Position.X() - playerPos.X()
+		dz := t.Position.Z() - playerPos.Z()
+		if float32(math.Sqrt(float64(dx*dx+dz*dz))) > cm.despawnRadius {
+			cm.entities.Destroy(id)
+		}
+	})
 
 	// Try to spawn new creatures
-	if len(cm.creatures) < cm.maxCreatures && cm.rng.Next() < 0.02 {
+	if cm.GetCreatureCount() < cm.maxCreatures && cm.rng.Next() < 0.02 {
 		cm.trySpawn(playerPos, getBiome, getHeight)
 	}
 }
 
-// groundCreature ensures the creature is properly positioned on the terrain
-func (cm *CreatureManager) groundCreature(c *entity.Creature, getHeight func(x, z int) int) {
-	// Get terrain height at creature position
-	terrainHeight := float32(getHeight(int(c.Position.X()), int(c.Position.Z())))
-	c.GroundY = terrainHeight
-
-	switch c.Template {
-	case entity.TemplateFlying:
-		// Flying creatures hover above terrain
-		hoverHeight := terrainHeight + 3.0 + float32(math.Sin(float64(c.AnimationTime)*2.0))*0.5
-		// Smoothly interpolate to hover height
-		c.Position[1] += (hoverHeight - c.Position.Y()) * 0.1
-
-	case entity.TemplateSlime:
-		// Slimes can bounce, apply gravity when above ground
-		if c.Position.Y() > terrainHeight+0.01 {
-			c.Velocity[1] -= 15.0 * 0.016 // Gravity
-		}
-		// Clamp to ground
-		if c.Position.Y() < terrainHeight {
-			c.Position[1] = terrainHeight
-			c.Velocity[1] = 0
-		}
-
-	case entity.TemplateFish:
-		// Fish stay at water level or terrain if no water
-		waterLevel := float32(12) // Sea level
-		if terrainHeight < waterLevel {
-			c.Position[1] = waterLevel - 1.0 + float32(math.Sin(float64(c.AnimationTime)*3.0))*0.3
-		} else {
-			// On land - die or flop (for now just stay on ground)
-			c.Position[1] = terrainHeight
-		}
-
-	default:
-		// Quadrupeds, bipeds, spiders - walk on ground
-		// Apply gravity
-		if c.Position.Y() > terrainHeight+0.01 {
-			c.Velocity[1] -= 20.0 * 0.016 // Gravity
-		}
-		// Clamp to ground
-		if c.Position.Y() < terrainHeight {
-			c.Position[1] = terrainHeight
-			c.Velocity[1] = 0
-		}
-	}
-}
-
 // trySpawn attempts to spawn a new creature
 func (cm *CreatureManager) trySpawn(playerPos mgl32.Vec3, getBiome func(x, z int) string, getHeight func(x, z int) int) {
 	// Random position around player
@@ -140,74 +104,152 @@ func (cm *CreatureManager) trySpawn(playerPos mgl32.Vec3, getBiome func(x, z int
 
 	// Choose template based on biome
 	template := cm.chooseTemplate(biome)
+	if template == entity.TemplateFish {
+		// Fish need water, which land spawns don't have
+		return
+	}
 
 	// Create creature
 	size := float32(cm.rng.NextFloat(0.6, 1.4))
-	creature := cm.generator.Create(template, biome, spawnPos, size)
-
-	cm.creatures = append(cm.creatures, creature)
+	cm.generator.Spawn(cm.entities, template, biome, spawnPos, size)
 }
 
-// chooseTemplate selects a creature template based on biome
-func (cm *CreatureManager) chooseTemplate(biome string) entity.CreatureTemplate {
-	switch biome {
-	case "desert":
-		// Spiders and slimes in desert
-		if cm.rng.Next() > 0.7 {
-			return entity.TemplateSpider
+// UpdateSpawners lets the dungeon spawners near the player spawn their
+// creatures, until enough are around each of them
+func (cm *CreatureManager) UpdateSpawners(dt float32, playerPos mgl32.Vec3, spawners []terrain.Spawner, getBiome func(x, z int) string) {
+	cm.spawnerTimer += dt
+	if cm.spawnerTimer < cm.spawnerInterval {
+		return
+	}
+	cm.spawnerTimer = 0
+
+	for _, s := range spawners {
+		center := mgl32.Vec3{float32(s.X) + 0.5, float32(s.Y), float32(s.Z) + 0.5}
+		if center.Sub(playerPos).Len() > cm.spawnerRange || cm.GetCreatureCount() >= cm.maxCreatures {
+			continue
 		}
-		return entity.TemplateSlime
-
-	case "snow":
-		// Quadrupeds in snow
-		return entity.TemplateQuadruped
-
-	case "forest":
-		// Mix in forest
-		r := cm.rng.Next()
-		if r < 0.4 {
-			return entity.TemplateQuadruped
-		} else if r < 0.7 {
-			return entity.TemplateBiped
+
+		nearby := 0
+		cm.entities.Brains.Each(func(id entity.ID, _ *entity.Brain) {
+			if t := cm.entities.Transforms.Get(id); t != nil && t.Position.Sub(center).Len() <= cm.spawnerRadius {
+				nearby++
+			}
+		})
+		if nearby >= cm.spawnerLimit {
+			continue
 		}
-		return entity.TemplateFlying
 
-	case "mountains":
-		// Flying and quadrupeds
-		if cm.rng.Next() > 0.5 {
-			return entity.TemplateFlying
+		// Next to the spawner, on the room's floor
+		dx, dz := 0, 0
+		for dx == 0 && dz == 0 {
+			dx, dz = cm.rng.NextInt(-1, 1), cm.rng.NextInt(-1, 1)
 		}
-		return entity.TemplateQuadruped
-
-	default: // plains
-		r := cm.rng.Next()
-		if r < 0.5 {
-			return entity.TemplateQuadruped
-		} else if r < 0.8 {
-			return entity.TemplateSlime
+		pos := center.Add(mgl32.Vec3{float32(dx), 0, float32(dz)})
+		size := float32(cm.rng.NextFloat(0.6, 1.0))
+		cm.generator.Spawn(cm.entities, entity.CreatureTemplate(s.Creature), getBiome(s.X, s.Z), pos, size)
+	}
+}
+
+// chooseTemplate selects a creature template from the biome's spawn table
+func (cm *CreatureManager) chooseTemplate(biome string) entity.CreatureTemplate {
+	roll := cm.rng.Next()
+	if b := cm.biomes.Get(biome); b != nil {
+		if name := b.PickCreature(cm.season.String(), roll); name != "" {
+			return entity.CreatureTemplate(name)
 		}
-		return entity.TemplateBiped
 	}
+	return entity.TemplateQuadruped
 }
 
-// GetCreatures returns all active creatures
-func (cm *CreatureManager) GetCreatures() []*entity.Creature {
-	return cm.creatures
+// SetSeason sets the season whose spawn tables creatures are chosen from
+func (cm *CreatureManager) SetSeason(season Season) {
+	cm.season = season
 }
 
 // GetCreatureCount returns the number of active creatures
 func (cm *CreatureManager) GetCreatureCount() int {
-	return len(cm.creatures)
+	return cm.entities.Brains.Len()
 }
 
 // Clear removes all creatures
 func (cm *CreatureManager) Clear() {
-	cm.creatures = cm.creatures[:0]
+	cm.entities.Brains.Each(func(id entity.ID, _ *entity.Brain) {
+		cm.entities.Destroy(id)
+	})
+	cm.entities.Flush()
 }
 
 // SpawnCreature manually spawns a creature at a position
-func (cm *CreatureManager) SpawnCreature(template entity.CreatureTemplate, biome string, pos mgl32.Vec3) *entity.Creature {
-	creature := cm.generator.Create(template, biome, pos, 0)
-	cm.creatures = append(cm.creatures, creature)
-	return creature
+func (cm *CreatureManager) SpawnCreature(template entity.CreatureTemplate, biome string, pos mgl32.Vec3) entity.ID {
+	return cm.generator.Spawn(cm.entities, template, biome, pos, 0)
+}
+
+// SaveState returns the active creatures in save format
+func (cm *CreatureManager) SaveState() []save.CreatureSave {
+	r := cm.entities
+	saved := make([]save.CreatureSave, 0, r.Brains.Len())
+	r.Brains.Each(func(id entity.ID, brain *entity.Brain) {
+		t, body, m := r.Transforms.Get(id), r.Bodies.Get(id), r.Renderables.Get(id)
+		if t == nil || body == nil || m == nil {
+			return
+		}
+		parts := make([]save.BodyPartSave, len(m.BodyParts))
+		for i, p := range m.BodyParts {
+			parts[i] = save.BodyPartSave{Type: p.Type, Size: p.Size, Offset: p.Offset}
+		}
+		s := save.CreatureSave{
+			Template:       string(m.Template),
+			Biome:          m.Biome,
+			Size:           m.Size,
+			Position:       t.Position,
+			Velocity:       body.Velocity,
+			Rotation:       t.Rotation,
+			State:          brain.State,
+			PrimaryColor:   m.PrimaryColor,
+			SecondaryColor: m.SecondaryColor,
+			AccentColor:    m.AccentColor,
+			BodyParts:      parts,
+		}
+		if h := r.Healths.Get(id); h != nil {
+			s.Health = h.Current
+		}
+		if inv := r.Inventories.Get(id); inv != nil {
+			s.HeldItem = uint8(inv.Held)
+		}
+		saved = append(saved, s)
+	})
+	return saved
+}
+
+// RestoreState replaces the active creatures with saved ones
+func (cm *CreatureManager) RestoreState(saved []save.CreatureSave) {
+	cm.Clear()
+	r := cm.entities
+	for _, s := range saved {
+		if cm.GetCreatureCount() >= cm.maxCreatures {
+			break
+		}
+
+		// Spawn rebuilds the derived components (behaviors, stats, body),
+		// then the randomized appearance is overwritten with the saved one
+		id := cm.generator.Spawn(r, entity.CreatureTemplate(s.Template), s.Biome, s.Position, s.Size)
+		r.Transforms.Get(id).Rotation = s.Rotation
+		r.Bodies.Get(id).Velocity = s.Velocity
+		if s.State != "" {
+			r.Brains.Get(id).State = s.State
+		}
+		r.Healths.Get(id).Current = s.Health
+		r.Inventories.Get(id).Held = block.Type(s.HeldItem)
+
+		m := r.Renderables.Get(id)
+		m.PrimaryColor = s.PrimaryColor
+		m.SecondaryColor = s.SecondaryColor
+		m.AccentColor = s.AccentColor
+		if len(s.BodyParts) > 0 {
+			m.BodyParts = make([]entity.BodyPart, len(s.BodyParts))
+			for i, p := range s.BodyParts {
+				m.BodyParts[i] = entity.BodyPart{Type: p.Type, Size: p.Size, Offset: p.Offset}
+			}
+		}
+	}
 }
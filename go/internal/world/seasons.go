@@ -0,0 +1,71 @@
+// Package world provides the seasons of the in-game year
+package world
+
+// Season is a quarter of the in-game year
+type Season int
+
+const (
+	SeasonSpring Season = iota
+	SeasonSummer
+	SeasonAutumn
+	SeasonWinter
+)
+
+// seasonCount is the number of seasons in a year
+const seasonCount = 4
+
+// DefaultDaysPerSeason is the length of a season in in-game days
+const DefaultDaysPerSeason = 7
+
+// seasonClimate is how a season changes the world
+type seasonClimate struct {
+	name        string
+	title       string     // Name shown in the time indicator
+	temperature float64    // Added to the climate temperature
+	daylight    float32    // Hours from sunrise to sunset at the start of the season
+	foliage     [3]float32 // Tint of leaves and grass
+}
+
+var seasonClimates = [seasonCount]seasonClimate{
+	SeasonSpring: {"spring", "Spring", -0.05, 12, [3]float32{1, 1.05, 0.95}},
+	SeasonSummer: {"summer", "Summer", 0.15, 15, [3]float32{1.05, 1, 0.85}},
+	SeasonAutumn: {"autumn", "Autumn", -0.1, 12, [3]float32{1.35, 0.8, 0.35}},
+	SeasonWinter: {"winter", "Winter", -0.35, 9, [3]float32{0.8, 0.8, 0.75}},
+}
+
+// String returns the season's name, as used in biome spawn tables
+func (s Season) String() string {
+	return seasonClimates[s].name
+}
+
+// Title returns the season's display name
+func (s Season) Title() string {
+	return seasonClimates[s].title
+}
+
+// Next returns the season that follows
+func (s Season) Next() Season {
+	return (s + 1) % seasonCount
+}
+
+// Temperature returns the change the season makes to climate temperature
+func (s Season) Temperature() float64 {
+	return seasonClimates[s].temperature
+}
+
+// Foliage returns the tint of leaves and grass in the season
+func (s Season) Foliage() [3]float32 {
+	return seasonClimates[s].foliage
+}
+
+// Freezes reports whether cold places get snow cover and frozen water in
+// the season
+func (s Season) Freezes() bool {
+	return seasonClimates[s].temperature < 0
+}
+
+// Frozen reports whether a place with a climate temperature is under snow
+// in the season, the same places that get snow instead of rain
+func (s Season) Frozen(temperature float64) bool {
+	return s.Freezes() && temperature+s.Temperature() < snowTemperature
+}
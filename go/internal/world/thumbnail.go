@@ -0,0 +1,64 @@
+// Package world provides top-down world thumbnails
+package world
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ThumbnailSize is the width and height of save thumbnails in pixels
+const ThumbnailSize = 64
+
+// thumbnailScale is the number of blocks covered by one thumbnail pixel
+const thumbnailScale = 2
+
+// Thumbnail renders a top-down view of the loaded terrain around the player.
+// Columns in chunks that aren't loaded are left dark.
+func (w *World) Thumbnail(size int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	background := color.RGBA{20, 20, 30, 255}
+
+	px := int(w.playerX)
+	pz := int(w.playerZ)
+	seaLevel := w.TerrainGenerator.Config.SeaLevel
+
+	for iy := 0; iy < size; iy++ {
+		for ix := 0; ix < size; ix++ {
+			wx := px + (ix-size/2)*thumbnailScale
+			wz := pz + (iy-size/2)*thumbnailScale
+
+			height := w.GetHeight(wx, wz)
+			top := w.GetBlock(wx, height, wz)
+			if top.IsAir() {
+				img.SetRGBA(ix, iy, background)
+				continue
+			}
+
+			c := top.GetColor()
+			shade := 0.6 + float32(height)/80.0
+
+			// Show water over submerged terrain, darker with depth
+			if height < seaLevel {
+				if water := w.GetBlock(wx, seaLevel, wz); water.IsLiquid() {
+					c = water.GetColor()
+					shade = 1.0 - float32(seaLevel-height)/30.0
+				}
+			}
+
+			shade = mgl32.Clamp(shade, 0.3, 1.2)
+			img.SetRGBA(ix, iy, color.RGBA{
+				R: uint8(mgl32.Clamp(c[0]*shade, 0, 1) * 255),
+				G: uint8(mgl32.Clamp(c[1]*shade, 0, 1) * 255),
+				B: uint8(mgl32.Clamp(c[2]*shade, 0, 1) * 255),
+				A: 255,
+			})
+		}
+	}
+
+	// Mark the player at the center
+	img.SetRGBA(size/2, size/2, color.RGBA{255, 60, 60, 255})
+
+	return img
+}
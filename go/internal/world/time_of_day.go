@@ -1,4 +1,4 @@
-// Package world provides time of day management
+// Package world provides time of day and calendar management
 package world
 
 import (
@@ -12,12 +12,21 @@ type TimeOfDay struct {
 	// Current time in hours (0-24)
 	CurrentHour float32
 
+	// Number of full days elapsed since the world was created
+	Day int
+
+	// Length of a season in days, four seasons making a year
+	DaysPerSeason int
+
 	// Duration of a full day in real seconds (default: 600 = 10 minutes)
 	DayDurationSeconds float32
 
 	// Minimum brightness during night (0.1-0.5)
 	NightBrightness float32
 
+	// Cloud cover from the weather (0-1), dimming the light and graying the sky
+	Overcast float32
+
 	// Accumulated real time since start
 	elapsedTime float32
 }
@@ -28,6 +37,7 @@ func NewTimeOfDay() *TimeOfDay {
 		CurrentHour:        12.0, // Start at noon
 		DayDurationSeconds: 600.0,
 		NightBrightness:    0.15,
+		DaysPerSeason:      DefaultDaysPerSeason,
 		elapsedTime:        0,
 	}
 }
@@ -43,6 +53,7 @@ func (t *TimeOfDay) Update(deltaTime float32) {
 	// Wrap around at 24 hours
 	for t.CurrentHour >= 24.0 {
 		t.CurrentHour -= 24.0
+		t.Day++
 	}
 }
 
@@ -54,15 +65,59 @@ func (t *TimeOfDay) SetTime(hour float32) {
 	}
 }
 
+// Season returns the current season
+func (t *TimeOfDay) Season() Season {
+	return Season(t.Day / t.DaysPerSeason % seasonCount)
+}
+
+// DayOfSeason returns the day of the current season, starting at 1
+func (t *TimeOfDay) DayOfSeason() int {
+	return t.Day%t.DaysPerSeason + 1
+}
+
+// Year returns the current year, starting at 1
+func (t *TimeOfDay) Year() int {
+	return t.Day/(t.DaysPerSeason*seasonCount) + 1
+}
+
+// Daylight returns the hours from sunrise to sunset today. Days lengthen
+// through spring and shorten through autumn.
+func (t *TimeOfDay) Daylight() float32 {
+	season := t.Season()
+	progress := (float32(t.DayOfSeason()-1) + t.CurrentHour/24) / float32(t.DaysPerSeason)
+	from := seasonClimates[season].daylight
+	to := seasonClimates[season.Next()].daylight
+	return from + (to-from)*progress
+}
+
+// SolarHour returns the hour the sun's position matches on a day with
+// sunrise at 6:00 and sunset at 18:00. The sun, sky and light follow it,
+// so they keep their look on longer and shorter days.
+func (t *TimeOfDay) SolarHour() float32 {
+	daylight := t.Daylight()
+	sunrise := 12 - daylight/2
+	sunset := 12 + daylight/2
+	if t.CurrentHour >= sunrise && t.CurrentHour <= sunset {
+		return 6 + (t.CurrentHour-sunrise)/daylight*12
+	}
+	sinceSunset := t.CurrentHour - sunset
+	if sinceSunset < 0 {
+		sinceSunset += 24
+	}
+	return float32(math.Mod(float64(18+sinceSunset/(24-daylight)*12), 24))
+}
+
 // GetSunDirection returns the sun's direction vector based on current time
 func (t *TimeOfDay) GetSunDirection() mgl32.Vec3 {
-	// Sun rises at 6:00, sets at 18:00
+	hour := t.SolarHour()
+
+	// On the solar clock the sun rises at 6:00 and sets at 18:00
 	// At 6:00, sun is at horizon (east)
 	// At 12:00, sun is overhead
 	// At 18:00, sun is at horizon (west)
 
 	// Convert hour to angle (6:00 = 0°, 12:00 = 90°, 18:00 = 180°)
-	dayProgress := (t.CurrentHour - 6.0) / 12.0 // 0 at sunrise, 1 at sunset
+	dayProgress := (hour - 6.0) / 12.0 // 0 at sunrise, 1 at sunset
 	if dayProgress < 0 {
 		dayProgress = 0
 	}
@@ -77,14 +132,14 @@ func (t *TimeOfDay) GetSunDirection() mgl32.Vec3 {
 	sunX := float32(math.Cos(float64(angle)))
 
 	// During night, sun is below horizon
-	if t.CurrentHour < 6.0 || t.CurrentHour > 18.0 {
+	if hour < 6.0 || hour > 18.0 {
 		sunY = -0.3
 		// Moon position (opposite of sun)
 		nightProgress := 0.0
-		if t.CurrentHour >= 18.0 {
-			nightProgress = (float64(t.CurrentHour) - 18.0) / 6.0
+		if hour >= 18.0 {
+			nightProgress = (float64(hour) - 18.0) / 6.0
 		} else {
-			nightProgress = (float64(t.CurrentHour) + 6.0) / 6.0
+			nightProgress = (float64(hour) + 6.0) / 6.0
 		}
 		sunX = float32(-math.Cos(nightProgress * math.Pi))
 	}
@@ -94,29 +149,41 @@ func (t *TimeOfDay) GetSunDirection() mgl32.Vec3 {
 
 // GetSunIntensity returns the sun's light intensity (0-1)
 func (t *TimeOfDay) GetSunIntensity() float32 {
+	return t.OvercastLight(t.clearSunIntensity())
+}
+
+// clearSunIntensity returns the sun's light intensity under a clear sky
+func (t *TimeOfDay) clearSunIntensity() float32 {
+	hour := t.SolarHour()
+
 	// Full intensity from 8:00 to 16:00
 	// Gradual transition during dawn/dusk
 
-	if t.CurrentHour >= 8.0 && t.CurrentHour <= 16.0 {
+	if hour >= 8.0 && hour <= 16.0 {
 		return 1.0
 	}
 
-	if t.CurrentHour >= 6.0 && t.CurrentHour < 8.0 {
+	if hour >= 6.0 && hour < 8.0 {
 		// Dawn
-		return (t.CurrentHour - 6.0) / 2.0
+		return (hour - 6.0) / 2.0
 	}
 
-	if t.CurrentHour > 16.0 && t.CurrentHour <= 18.0 {
+	if hour > 16.0 && hour <= 18.0 {
 		// Dusk
-		return (18.0 - t.CurrentHour) / 2.0
+		return (18.0 - hour) / 2.0
 	}
 
 	// Night
 	return t.NightBrightness
 }
 
-// GetSkyColor returns the sky color based on time of day
+// GetSkyColor returns the sky color based on time of day and weather
 func (t *TimeOfDay) GetSkyColor() mgl32.Vec3 {
+	return t.OvercastColor(t.clearSkyColor())
+}
+
+// clearSkyColor returns the sky color under a clear sky
+func (t *TimeOfDay) clearSkyColor() mgl32.Vec3 {
 	// Define key colors
 	dayColor := mgl32.Vec3{0.53, 0.81, 0.98}     // Light blue
 	sunriseColor := mgl32.Vec3{0.98, 0.6, 0.4}   // Orange/pink
@@ -124,7 +191,7 @@ func (t *TimeOfDay) GetSkyColor() mgl32.Vec3 {
 	nightColor := mgl32.Vec3{0.05, 0.05, 0.15}   // Dark blue
 	twilightColor := mgl32.Vec3{0.2, 0.15, 0.35} // Purple
 
-	hour := t.CurrentHour
+	hour := t.SolarHour()
 
 	// Night (0:00 - 5:00)
 	if hour < 5.0 {
@@ -185,6 +252,19 @@ func (t *TimeOfDay) GetAmbientColor() mgl32.Vec3 {
 	return lerpVec3(nightAmbient, dayAmbient, intensity)
 }
 
+// OvercastLight dims a light intensity by the cloud cover, down to 40%
+// in a full storm
+func (t *TimeOfDay) OvercastLight(intensity float32) float32 {
+	return intensity * (1 - 0.6*t.Overcast)
+}
+
+// OvercastColor grays a sky or light color by the cloud cover, keeping
+// its brightness
+func (t *TimeOfDay) OvercastColor(c mgl32.Vec3) mgl32.Vec3 {
+	gray := (c.X() + c.Y() + c.Z()) / 3 * (1 - 0.5*t.Overcast)
+	return lerpVec3(c, mgl32.Vec3{gray, gray, gray * 1.05}, t.Overcast*0.8)
+}
+
 // GetFogColor returns fog color based on time
 func (t *TimeOfDay) GetFogColor() mgl32.Vec3 {
 	sky := t.GetSkyColor()
@@ -198,11 +278,18 @@ func (t *TimeOfDay) GetFogColor() mgl32.Vec3 {
 
 // IsNight returns true if it's night time
 func (t *TimeOfDay) IsNight() bool {
-	return t.CurrentHour < 6.0 || t.CurrentHour >= 18.0
+	hour := t.SolarHour()
+	return hour < 6.0 || hour >= 18.0
 }
 
-// GetTimeString returns a formatted time string (e.g., "12:30 PM")
+// GetTimeString returns the season, its day and the time (e.g.,
+// "Spring 3, 12:30 PM")
 func (t *TimeOfDay) GetTimeString() string {
+	return t.Season().Title() + " " + itoa(t.DayOfSeason()) + ", " + t.clockString()
+}
+
+// clockString returns the formatted time of day (e.g., "12:30 PM")
+func (t *TimeOfDay) clockString() string {
 	hour := int(t.CurrentHour)
 	minute := int((t.CurrentHour - float32(hour)) * 60)
 
@@ -0,0 +1,258 @@
+// Package world provides the weather cycle
+package world
+
+import (
+	"math"
+
+	vmath "voxelgame/pkg/math"
+)
+
+// WeatherState is a kind of weather
+type WeatherState int
+
+const (
+	WeatherClear WeatherState = iota
+	WeatherRain
+	WeatherStorm // Heavy rain with lightning
+	WeatherSnow
+)
+
+var weatherNames = [...]string{"clear", "rain", "storm", "snow"}
+
+// String returns the weather's name
+func (s WeatherState) String() string {
+	if s < 0 || int(s) >= len(weatherNames) {
+		return "unknown"
+	}
+	return weatherNames[s]
+}
+
+// ParseWeatherState returns the weather with a name
+func ParseWeatherState(name string) (WeatherState, bool) {
+	for i, n := range weatherNames {
+		if n == name {
+			return WeatherState(i), true
+		}
+	}
+	return WeatherClear, false
+}
+
+// weatherSpell is how long a kind of weather lasts and what follows it
+type weatherSpell struct {
+	minSeconds, maxSeconds float64
+	cover                  float32 // Cloud cover at full intensity, 0-1
+	next                   []weatherChance
+}
+
+type weatherChance struct {
+	state  WeatherState
+	chance float64
+}
+
+var weatherSpells = [...]weatherSpell{
+	WeatherClear: {300, 900, 0, []weatherChance{{WeatherRain, 0.55}, {WeatherStorm, 0.2}, {WeatherSnow, 0.25}}},
+	WeatherRain:  {120, 360, 0.6, []weatherChance{{WeatherClear, 0.6}, {WeatherStorm, 0.4}}},
+	WeatherStorm: {90, 240, 1, []weatherChance{{WeatherRain, 0.6}, {WeatherClear, 0.4}}},
+	WeatherSnow:  {120, 360, 0.5, []weatherChance{{WeatherClear, 1}}},
+}
+
+const (
+	weatherFadeSeconds = 15.0 // Time weather takes to build up and clear
+	flashDecay         = 4.0  // Lightning flash fade per second
+
+	// Climate thresholds, matching the biome table
+	snowTemperature = -0.25 // Colder places get snow instead of rain
+	dryTemperature  = 0.25  // Hotter places with dryHumidity stay clear
+	dryHumidity     = -0.15
+
+	// Seconds between strikes in a full storm
+	minStrikeSeconds = 6.0
+	maxStrikeSeconds = 20.0
+	strikeRadius     = 48 // Blocks from the player lightning may strike
+)
+
+// Weather runs the world's weather as a sequence of spells. Each spell has
+// one world-wide state that builds up and clears at its ends; what falls at
+// a place depends on its climate (see At). Spells are drawn from the world
+// seed, so a world always has the same weather.
+type Weather struct {
+	State    WeatherState
+	Spell    int     // Number of spells since the world was created
+	Elapsed  float32 // Seconds into the current spell
+	Duration float32 // Length of the current spell in seconds
+
+	// Flash is the brightness of the last lightning strike, fading from 1
+	Flash float32
+
+	// Separate streams for spell lengths, following states and strikes,
+	// drawn per spell (and per strike), so a restored spell continues
+	// where it left off
+	random       vmath.PositionalRNG
+	nextRandom   vmath.PositionalRNG
+	strikeRandom vmath.PositionalRNG
+
+	strikes     int     // Strikes so far in the current spell
+	strikeTimer float32 // Storm seconds until the next strike
+	strikeDX    int     // Offset of the last strike from the player
+	strikeDZ    int
+}
+
+// NewWeather creates the weather of a world, starting with a clear spell
+func NewWeather(seed int64) *Weather {
+	random := vmath.NewPositionalRNG(seed).Split("weather")
+	w := &Weather{
+		random:       random,
+		nextRandom:   random.Split("next"),
+		strikeRandom: random.Split("strikes"),
+	}
+	w.startSpell(WeatherClear, 0)
+	return w
+}
+
+// Set starts a spell of weather now, as the next spell in the sequence
+func (w *Weather) Set(state WeatherState) {
+	w.startSpell(state, w.Spell+1)
+}
+
+// Restore resumes a saved spell. Strikes are timed by storm time alone, so
+// the ones that already struck are counted again from the elapsed time.
+func (w *Weather) Restore(state WeatherState, spell int, elapsed float32) {
+	w.startSpell(state, spell)
+	w.Elapsed = elapsed
+	if state != WeatherStorm {
+		return
+	}
+
+	// Strikes only count down while the storm is at half intensity or more
+	fade := float32(weatherFadeSeconds)
+	if w.Duration < 2*fade {
+		fade = w.Duration / 2
+	}
+	end := elapsed
+	if end > w.Duration-fade/2 {
+		end = w.Duration - fade/2
+	}
+	stormed := end - fade/2
+	for stormed > 0 && stormed >= w.strikeTimer {
+		stormed -= w.strikeTimer
+		w.strikes++
+		w.strikeTimer = w.nextStrike()
+	}
+	if stormed > 0 {
+		w.strikeTimer -= stormed
+	}
+}
+
+// startSpell begins a spell, drawing its length from the spell's stream
+func (w *Weather) startSpell(state WeatherState, spell int) {
+	w.State = state
+	w.Spell = spell
+	w.Elapsed = 0
+	s := weatherSpells[state]
+	w.Duration = float32(w.random.At(spell, 0).NextFloat(s.minSeconds, s.maxSeconds))
+	w.strikes = 0
+	w.strikeTimer = w.nextStrike()
+}
+
+// Update advances the weather and reports whether lightning struck
+func (w *Weather) Update(dt float32) bool {
+	w.Flash -= dt * flashDecay
+	if w.Flash < 0 {
+		w.Flash = 0
+	}
+
+	w.Elapsed += dt
+	if w.Elapsed >= w.Duration {
+		w.startSpell(w.following(), w.Spell+1)
+	}
+
+	if w.State != WeatherStorm || w.Intensity() < 0.5 {
+		return false
+	}
+	w.strikeTimer -= dt
+	if w.strikeTimer > 0 {
+		return false
+	}
+	w.strikeDX, w.strikeDZ = w.strikeOffset()
+	w.strikes++
+	w.strikeTimer = w.nextStrike()
+	w.Flash = 1
+	return true
+}
+
+// following draws the state of the next spell
+func (w *Weather) following() WeatherState {
+	roll := w.nextRandom.At(w.Spell, 0).Next()
+	next := weatherSpells[w.State].next
+	for _, c := range next {
+		if roll < c.chance {
+			return c.state
+		}
+		roll -= c.chance
+	}
+	return next[len(next)-1].state
+}
+
+// nextStrike draws the storm time until the next lightning strike from
+// the strike's stream
+func (w *Weather) nextStrike() float32 {
+	return float32(w.strikeRandom.At(w.Spell, 2*w.strikes).NextFloat(minStrikeSeconds, maxStrikeSeconds))
+}
+
+// strikeOffset draws where the next strike hits relative to the player
+func (w *Weather) strikeOffset() (dx, dz int) {
+	rng := w.strikeRandom.At(w.Spell, 2*w.strikes+1)
+	angle := rng.Next() * 2 * math.Pi
+	dist := 8 + rng.Next()*(strikeRadius-8)
+	return int(math.Cos(angle) * dist), int(math.Sin(angle) * dist)
+}
+
+// StrikeOffset returns where the last lightning strike hit relative to the
+// player
+func (w *Weather) StrikeOffset() (dx, dz int) {
+	return w.strikeDX, w.strikeDZ
+}
+
+// Intensity returns how far the current spell has built up, from 0 as it
+// starts and ends to 1 in between
+func (w *Weather) Intensity() float32 {
+	fade := float32(weatherFadeSeconds)
+	if w.Duration < 2*fade {
+		fade = w.Duration / 2
+	}
+	i := w.Elapsed / fade
+	if end := (w.Duration - w.Elapsed) / fade; end < i {
+		i = end
+	}
+	if i > 1 {
+		i = 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+// At returns the weather at a place with the given climate. Cold places
+// get snow instead of rain, warm places rain instead of snow, and hot dry
+// places stay clear.
+func (w *Weather) At(temperature, humidity float64) WeatherState {
+	if w.State == WeatherClear {
+		return WeatherClear
+	}
+	if temperature > dryTemperature && humidity < dryHumidity {
+		return WeatherClear
+	}
+	if temperature < snowTemperature {
+		return WeatherSnow
+	}
+	if w.State == WeatherSnow {
+		return WeatherRain
+	}
+	return w.State
+}
+
+// Overcast returns the cloud cover of local weather, from 0 to 1
+func (w *Weather) Overcast(local WeatherState) float32 {
+	return weatherSpells[local].cover * w.Intensity()
+}
@@ -2,6 +2,8 @@
 package world
 
 import (
+	"math"
+	"sort"
 	"time"
 
 	"fmt"
@@ -9,6 +11,7 @@ import (
 	"voxelgame/internal/core/chunk"
 	"voxelgame/internal/generation/entity"
 	"voxelgame/internal/generation/terrain"
+	"voxelgame/internal/headless"
 	"voxelgame/internal/render"
 	"voxelgame/internal/save"
 
@@ -20,8 +23,14 @@ type World struct {
 	// Seed for world generation
 	Seed int64
 
-	// Terrain generator
+	// Terrain generator, and the preset's chunk generator built on it
 	TerrainGenerator *terrain.Generator
+	chunkGenerator   chunk.ChunkGenerator
+
+	// Generator preset and algorithm version the world was created with.
+	// Together with the generator config they are fixed for the world's lifetime.
+	Preset           string
+	GeneratorVersion int
 
 	// Chunk manager
 	ChunkManager *chunk.Manager
@@ -32,6 +41,11 @@ type World struct {
 	// Chunk mesher
 	Mesher *chunk.Mesher
 
+	// Entities of the world: the player, creatures, dropped items and
+	// projectiles. Player is the player's entity.
+	Entities *entity.Registry
+	Player   entity.ID
+
 	// Creature manager
 	CreatureManager *CreatureManager
 
@@ -41,33 +55,77 @@ type World struct {
 	// Player position for chunk loading
 	playerX, playerY, playerZ float64
 
+	// PlayerState returns the full player state for saving.
+	// If nil, only the last known position is saved.
+	PlayerState func() save.PlayerSave
+
+	// OnSaved is called after a save has been written successfully.
+	// For background saves it runs on the saving goroutine.
+	OnSaved func(snapshot WorldSnapshot)
+
+	// Total seconds played in this world
+	PlayTime float64
+
+	// Background saving
+	autosave autosaver
+
 	// Stats
 	chunksLoaded    int
 	meshesGenerated int
 	lastUpdateTime  time.Time
 	// Time of Day system
 	TimeOfDay *TimeOfDay
+
+	// Weather, dimming TimeOfDay by its cloud cover at the player
+	Weather *Weather
+
+	// Season the world's meshes and spawn tables were last set up for
+	season Season
+
+	// Loaded chunks still drawn for the previous season, nearest first
+	seasonRemesh []*chunk.Chunk
+
+	// OnLightning is called with the surface block lightning struck
+	OnLightning func(x, y, z int)
+
+	// OnPickup is given the dropped items the player picks up and reports
+	// whether they were taken
+	OnPickup func(item block.Type, count int) bool
 }
 
-// NewWorld creates a new world with the given seed
+// NewWorld creates a new world with the given seed and default terrain
 func NewWorld(seed int64) *World {
-	terrainGen := terrain.NewGenerator(seed)
+	return NewWorldWithConfig(seed, terrain.PresetDefault, terrain.DefaultConfig())
+}
+
+// NewWorldWithConfig creates a new world with the given seed, preset and terrain config
+func NewWorldWithConfig(seed int64, preset string, config terrain.GeneratorConfig) *World {
+	terrainGen, chunkGen := headless.NewGenerator(seed, preset, config)
 
 	chunkConfig := chunk.DefaultManagerConfig()
 	chunkConfig.RenderDistance = 10 // Default render distance
 	chunkConfig.MaxLoadedChunks = 200
 
+	entities := entity.NewRegistry()
 	w := &World{
 		Seed:             seed,
 		TerrainGenerator: terrainGen,
-		ChunkManager:     chunk.NewManager(chunkConfig, terrainGen),
+		chunkGenerator:   chunkGen,
+		Preset:           preset,
+		GeneratorVersion: terrain.GeneratorVersion,
+		ChunkManager:     chunk.NewManager(chunkConfig, chunkGen),
 		ChunkRenderer:    render.NewChunkRenderer(),
 		Mesher:           chunk.NewMesher(),
-		CreatureManager:  NewCreatureManager(seed),
+		Entities:         entities,
+		CreatureManager:  NewCreatureManager(seed, terrainGen.Biomes, entities),
 		SaveManager:      save.NewManager(),
 		lastUpdateTime:   time.Now(),
 		TimeOfDay:        NewTimeOfDay(),
+		Weather:          NewWeather(seed),
 	}
+	w.autosave.config = DefaultAutosaveConfig()
+	w.spawnPlayer()
+	w.applySeason()
 
 	// Set up callbacks
 	w.ChunkManager.OnChunkLoaded = w.onChunkLoaded
@@ -91,6 +149,14 @@ func (w *World) Update(playerX, playerY, playerZ float64) {
 
 	// Update time of day
 	w.TimeOfDay.Update(dt)
+	if w.TimeOfDay.Season() != w.season {
+		w.applySeason()
+	}
+	w.updateWeather(dt)
+	w.PlayTime += float64(dt)
+
+	// Periodic background autosave
+	w.updateAutosave(dt)
 
 	// Update chunks around player
 	loadRequests := w.ChunkManager.UpdateAroundPlayer(playerX, playerZ)
@@ -102,6 +168,8 @@ func (w *World) Update(playerX, playerY, playerZ float64) {
 		w.ChunkManager.LoadChunk(req.CX, req.CZ)
 	}
 
+	w.queueSeasonRemesh()
+
 	// Update dirty chunks
 	dirtyChunks := w.ChunkManager.GetDirtyChunks()
 	// Process more meshes per frame to handle updates faster
@@ -112,20 +180,133 @@ func (w *World) Update(playerX, playerY, playerZ float64) {
 
 	w.chunksLoaded = w.ChunkManager.LoadedCount()
 
-	// Update creatures
+	// Update creatures and the other entities
 	playerPos := mgl32.Vec3{float32(playerX), float32(playerY), float32(playerZ)}
-	w.CreatureManager.Update(0.016, playerPos, w.GetBiomeAt, w.GetHeight)
+	w.CreatureManager.Update(playerPos, w.GetBiomeAt, w.GetHeight)
+	w.CreatureManager.UpdateSpawners(dt, playerPos, w.spawnersNear(playerX, playerZ), w.GetBiomeAt)
+	w.updateEntities(dt, playerPos)
+}
+
+// updateEntities runs the entity systems
+func (w *World) updateEntities(dt float32, playerPos mgl32.Vec3) {
+	entity.UpdateBrains(w.Entities, dt, playerPos)
+	entity.UpdateBodies(w.Entities, dt, w)
+	entity.UpdateProjectiles(w.Entities, dt)
+	entity.UpdateItems(w.Entities, dt)
+	entity.UpdateHealth(w.Entities)
+	entity.UpdateAnimations(w.Entities, dt)
+	w.Entities.Flush()
+}
+
+// spawnPlayer creates the player's entity, handing what it picks up to
+// OnPickup
+func (w *World) spawnPlayer() {
+	pos := mgl32.Vec3{float32(w.playerX), float32(w.playerY), float32(w.playerZ)}
+	w.Player = entity.NewGenerator(w.Seed).SpawnPlayer(w.Entities, pos)
+	w.Entities.Inventories.Get(w.Player).OnCollect = func(item block.Type, count int) bool {
+		return w.OnPickup != nil && w.OnPickup(item, count)
+	}
+}
+
+// SyncPlayer moves the player's entity to where the player's physics put
+// it. onGround is false while flying, so the model doesn't walk in the air.
+func (w *World) SyncPlayer(feet mgl32.Vec3, rotation float32, velocity mgl32.Vec3, onGround bool) {
+	if t := w.Entities.Transforms.Get(w.Player); t != nil {
+		t.Position = feet
+		t.Rotation = rotation
+	}
+	if b := w.Entities.Bodies.Get(w.Player); b != nil {
+		b.Velocity = velocity
+		b.OnGround = onGround
+	}
 }
 
-// ApplySettings applies settings to the world
-func (w *World) ApplySettings(dayDuration, nightBrightness float32, terrainConfig terrain.GeneratorConfig) {
+// DropItem drops items into the world, popping up from a position
+func (w *World) DropItem(item block.Type, count int, pos mgl32.Vec3) {
+	entity.SpawnItem(w.Entities, item, count, pos, mgl32.Vec3{0, 4, 0})
+}
+
+// saveItems converts the items in the world to the save format. Projectiles
+// are saved as the item they drop where they are, so nothing thrown is lost.
+func (w *World) saveItems() []save.ItemSave {
+	r := w.Entities
+	var saved []save.ItemSave
+	r.Items.Each(func(id entity.ID, it *entity.Item) {
+		t, b := r.Transforms.Get(id), r.Bodies.Get(id)
+		if t == nil || b == nil || it.Count == 0 {
+			return
+		}
+		saved = append(saved, save.ItemSave{
+			Type: uint8(it.Type), Count: it.Count, Position: t.Position, Velocity: b.Velocity, Age: it.Age,
+		})
+	})
+	r.Projectiles.Each(func(id entity.ID, p *entity.Projectile) {
+		t, b := r.Transforms.Get(id), r.Bodies.Get(id)
+		if t == nil || b == nil || p.Drops == block.Air {
+			return
+		}
+		saved = append(saved, save.ItemSave{
+			Type: uint8(p.Drops), Count: 1, Position: t.Position, Velocity: b.Velocity,
+		})
+	})
+	return saved
+}
+
+// restoreItems drops saved items back into the world
+func (w *World) restoreItems(saved []save.ItemSave) {
+	for _, s := range saved {
+		id := entity.SpawnItem(w.Entities, block.Type(s.Type), s.Count, s.Position, s.Velocity)
+		w.Entities.Items.Get(id).Age = s.Age
+	}
+}
+
+// thrownDamage is the damage an item thrown by the player does
+const thrownDamage = 3
+
+// ThrowItem throws an item from the player, hurting what it hits
+func (w *World) ThrowItem(item block.Type, from, velocity mgl32.Vec3) {
+	entity.SpawnProjectile(w.Entities, w.Player, item, thrownDamage, from, velocity)
+}
+
+// ApplySettings applies settings to the world.
+// Terrain settings are fixed at creation and are not affected.
+func (w *World) ApplySettings(dayDuration, nightBrightness float32) {
 	if w.TimeOfDay != nil {
 		w.TimeOfDay.DayDurationSeconds = dayDuration
 		w.TimeOfDay.NightBrightness = nightBrightness
 	}
-	if w.TerrainGenerator != nil {
-		w.TerrainGenerator.SetConfig(terrainConfig)
+}
+
+// GetGeneratorConfig returns the terrain config the world was created with
+func (w *World) GetGeneratorConfig() terrain.GeneratorConfig {
+	return w.TerrainGenerator.Config
+}
+
+// GeneratorSave returns the world's terrain settings in save format
+func (w *World) GeneratorSave() save.GeneratorSave {
+	config := w.GetGeneratorConfig()
+	return save.GeneratorSave{
+		Version:          w.GeneratorVersion,
+		Preset:           w.Preset,
+		SeaLevel:         config.SeaLevel,
+		TerrainAmplitude: config.TerrainAmplitude,
+		TreeDensity:      config.TreeDensity,
+		CaveFrequency:    config.CaveFrequency,
+		FlatLayers:       config.FlatLayers,
+	}
+}
+
+// UsePregenerated loads chunks from a pre-generated chunk directory when
+// available instead of generating them. The directory is ignored unless it
+// was generated with this world's seed and terrain settings.
+func (w *World) UsePregenerated(dir string) bool {
+	store := headless.OpenPregenerated(dir, w.Seed, w.GeneratorSave())
+	if store == nil {
+		return false
 	}
+	w.ChunkManager.SetGenerator(&chunk.StoredGenerator{Store: store, Generator: w.chunkGenerator})
+	fmt.Printf("[World] Using %d pre-generated chunks from %s\n", store.Count(), dir)
+	return true
 }
 
 // Render renders all visible chunks
@@ -152,15 +333,54 @@ func (w *World) GetHeight(x, z int) int {
 	return w.ChunkManager.GetHeight(x, z)
 }
 
+// GroundHeight returns the height of the floor under a position: the
+// highest block below it with air above, or the column height if the
+// position is inside the terrain
+func (w *World) GroundHeight(x, y, z int) int {
+	if w.GetBlock(x, y+1, z) != block.Air {
+		return w.GetHeight(x, z)
+	}
+	for gy := y; gy > 0; gy-- {
+		if w.GetBlock(x, gy, z) != block.Air {
+			return gy
+		}
+	}
+	return w.GetHeight(x, z)
+}
+
+// spawnersNear returns the generated spawners in the chunks around a
+// position that haven't been broken
+func (w *World) spawnersNear(x, z float64) []terrain.Spawner {
+	cx, cz := headless.ChunkCoords(int(math.Floor(x)), int(math.Floor(z)))
+	var spawners []terrain.Spawner
+	for dx := -1; dx <= 1; dx++ {
+		for dz := -1; dz <= 1; dz++ {
+			for _, s := range w.TerrainGenerator.Spawners(cx+dx, cz+dz) {
+				if w.GetBlock(s.X, s.Y, s.Z) == block.Spawner {
+					spawners = append(spawners, s)
+				}
+			}
+		}
+	}
+	return spawners
+}
+
 // GetSpawnPosition returns a suitable spawn position
 func (w *World) GetSpawnPosition() (x, y, z float64) {
-	spawnX, spawnZ := 0.0, 0.0
+	// Start where the preset wants players, on land near the origin
+	// rather than out at sea by default
+	var finder terrain.SpawnFinder = w.TerrainGenerator
+	if f, ok := w.chunkGenerator.(terrain.SpawnFinder); ok {
+		finder = f
+	}
+	landX, landZ := finder.SpawnPoint()
+	spawnX, spawnZ := float64(landX)+0.5, float64(landZ)+0.5
 
 	// Load spawn chunk
-	w.ChunkManager.LoadChunk(0, 0)
+	w.ChunkManager.LoadChunk(headless.ChunkCoords(landX, landZ))
 
 	// Get terrain height - find highest non-air block
-	height := w.GetHeight(0, 0)
+	height := w.GetHeight(landX, landZ)
 
 	// Spawn well above terrain to avoid being stuck
 	spawnY := float64(height) + 10
@@ -191,8 +411,11 @@ type WorldStats struct {
 
 // Cleanup releases resources
 func (w *World) Cleanup() {
+	// Let any background save finish before writing the final one
+	w.WaitForSave()
+
 	// Auto-save on exit
-	if err := w.Save("autosave"); err != nil {
+	if err := w.Save(w.autosave.config.SaveName); err != nil {
 		fmt.Printf("Failed to autosave: %v\n", err)
 	}
 
@@ -201,96 +424,65 @@ func (w *World) Cleanup() {
 	w.CreatureManager.Clear()
 }
 
-// Save saves the world state
+// Save saves the world state synchronously
 func (w *World) Save(saveName string) error {
-	// Get all modifications from chunk manager
-	modifications := w.ChunkManager.GetAllModifications()
-
-	// Convert to save format
-	saveMods := make(map[string]save.ChunkModSave)
-	for id, mods := range modifications {
-		var saveBlockMods []save.BlockModSave
-		for _, m := range mods {
-			saveBlockMods = append(saveBlockMods, save.BlockModSave{
-				X:    m.X,
-				Y:    m.Y,
-				Z:    m.Z,
-				Type: uint8(m.Type),
-			})
-		}
-
-		// Parse chunk ID to get CX, CZ
-		var cx, cz int
-		fmt.Sscanf(id, "%d,%d", &cx, &cz)
-
-		saveMods[id] = save.ChunkModSave{
-			CX:            cx,
-			CZ:            cz,
-			Modifications: saveBlockMods,
-		}
+	snapshot := w.Snapshot()
+	if err := w.SaveManager.Save(saveName, snapshot.ToSaveData()); err != nil {
+		return err
 	}
-
-	playerSave := save.PlayerSave{
-		PositionX: float32(w.playerX),
-		PositionY: float32(w.playerY),
-		PositionZ: float32(w.playerZ),
-		// Yaw and Pitch would need to be passed in or stored in World if we want to save them
+	if w.OnSaved != nil {
+		w.OnSaved(snapshot)
 	}
+	return nil
+}
 
-	worldSave := save.WorldSave{
-		Seed:           w.Seed,
-		ModifiedChunks: saveMods,
+// NewWorldFromSave creates a world from loaded save data, with the seed,
+// preset and terrain config it was generated with.
+// Player state other than position is left to the caller.
+func NewWorldFromSave(data *save.SaveData) *World {
+	w := NewWorldWithConfig(data.World.Seed, headless.GeneratorPreset(data.World.Generator),
+		headless.GeneratorConfig(data.World.Generator))
+
+	w.GeneratorVersion = terrain.GeneratorVersion
+	if gen := data.World.Generator; gen != nil {
+		w.GeneratorVersion = gen.Version
+	}
+	if w.GeneratorVersion != terrain.GeneratorVersion {
+		fmt.Printf("[World] Save was generated with terrain version %d (current %d), new chunks may not match\n",
+			w.GeneratorVersion, terrain.GeneratorVersion)
 	}
 
-	return w.SaveManager.Save(saveName, save.SaveData{
-		Player: playerSave,
-		World:  worldSave,
-	})
-}
+	w.ChunkManager.SetModifications(headless.ModificationsFromSave(data.World.ModifiedChunks))
 
-// Load loads the world state
-func (w *World) Load(saveName string) error {
-	data, err := w.SaveManager.Load(saveName)
-	if err != nil {
-		return err
+	// Restore time of day (older saves start at the default time)
+	if data.World.Time != nil {
+		w.TimeOfDay.SetTime(data.World.Time.Hour)
+		w.TimeOfDay.Day = data.World.Time.Day
+		w.TimeOfDay.DaysPerSeason = DefaultDaysPerSeason
+		if data.World.Time.DaysPerSeason > 0 {
+			w.TimeOfDay.DaysPerSeason = data.World.Time.DaysPerSeason
+		}
 	}
 
-	w.Seed = data.World.Seed
-	// Re-initialize generator with saved seed
-	w.TerrainGenerator = terrain.NewGenerator(w.Seed)
-	// Re-create manager with new generator (keeps config)
-	config := chunk.DefaultManagerConfig()
-	config.RenderDistance = 10
-	config.MaxLoadedChunks = 200
-	w.ChunkManager = chunk.NewManager(config, w.TerrainGenerator)
-
-	// Convert modifications back to chunk manager format
-	chunkMods := make(map[string][]chunk.BlockModificationWorld)
-	for id, modSave := range data.World.ModifiedChunks {
-		var blockMods []chunk.BlockModificationWorld
-		for _, m := range modSave.Modifications {
-			blockMods = append(blockMods, chunk.BlockModificationWorld{
-				X:    m.X,
-				Y:    m.Y,
-				Z:    m.Z,
-				Type: block.Type(m.Type),
-			})
+	// Restore weather (older saves start clear)
+	if ws := data.World.Weather; ws != nil {
+		if state, ok := ParseWeatherState(ws.State); ok {
+			w.Weather.Restore(state, ws.Spell, ws.Elapsed)
 		}
-		chunkMods[id] = blockMods
 	}
 
-	w.ChunkManager.SetModifications(chunkMods)
-
 	// Set player position
 	w.playerX = float64(data.Player.PositionX)
 	w.playerY = float64(data.Player.PositionY)
 	w.playerZ = float64(data.Player.PositionZ)
+	if t := w.Entities.Transforms.Get(w.Player); t != nil {
+		t.Position = mgl32.Vec3{data.Player.PositionX, data.Player.PositionY, data.Player.PositionZ}
+	}
 
-	// Setup callbacks again since we recreated the manager
-	w.ChunkManager.OnChunkLoaded = w.onChunkLoaded
-	w.ChunkManager.OnChunkUnloaded = w.onChunkUnloaded
-
-	return nil
+	w.CreatureManager.RestoreState(data.World.Creatures)
+	w.restoreItems(data.World.Items)
+	w.applySeason()
+	return w
 }
 
 // Private methods
@@ -319,7 +511,74 @@ func (w *World) GetBiomeAt(x, z int) string {
 	return w.TerrainGenerator.GetBiomeName(x, z)
 }
 
-// GetCreatures returns all creatures for rendering
-func (w *World) GetCreatures() []*entity.Creature {
-	return w.CreatureManager.GetCreatures()
+// LocalWeather returns the weather at world coordinates, which depends on
+// the climate there
+func (w *World) LocalWeather(x, z int) WeatherState {
+	climate := w.TerrainGenerator.Climate(x, z)
+	return w.Weather.At(climate.Temperature+w.season.Temperature(), climate.Humidity)
+}
+
+// applySeason sets up the current season: snow cover, ice and foliage
+// color for meshes, and the creature spawn tables. Loaded chunks are
+// remeshed to show it.
+func (w *World) applySeason() {
+	season := w.TimeOfDay.Season()
+	w.season = season
+	w.CreatureManager.SetSeason(season)
+
+	w.Mesher.Surface = chunk.SurfaceLook{Foliage: season.Foliage()}
+	if season.Freezes() {
+		w.Mesher.Surface.Frozen = func(wx, wz int) bool {
+			return season.Frozen(w.TerrainGenerator.Climate(wx, wz).Temperature)
+		}
+	}
+
+	// Remesh gradually, so a season change doesn't stall a frame
+	pcx, pcz := headless.ChunkCoords(int(math.Floor(w.playerX)), int(math.Floor(w.playerZ)))
+	w.seasonRemesh = w.ChunkManager.GetLoadedChunks()
+	dist := func(c *chunk.Chunk) int {
+		dx, dz := int(c.CX)-pcx, int(c.CZ)-pcz
+		return dx*dx + dz*dz
+	}
+	sort.Slice(w.seasonRemesh, func(i, j int) bool {
+		return dist(w.seasonRemesh[i]) < dist(w.seasonRemesh[j])
+	})
+}
+
+// seasonRemeshPerFrame is how many chunks are queued for remeshing per
+// frame after a season change
+const seasonRemeshPerFrame = 4
+
+// queueSeasonRemesh marks the next chunks still showing the previous
+// season dirty. Chunks unloaded meanwhile are remeshed when they load.
+func (w *World) queueSeasonRemesh() {
+	n := min(seasonRemeshPerFrame, len(w.seasonRemesh))
+	for _, c := range w.seasonRemesh[:n] {
+		c.IsDirty = true
+	}
+	w.seasonRemesh = w.seasonRemesh[n:]
+}
+
+// updateWeather advances the weather, sets the cloud cover at the player
+// and strikes lightning near them in storms
+func (w *World) updateWeather(dt float32) {
+	struck := w.Weather.Update(dt)
+	px, pz := int(math.Floor(w.playerX)), int(math.Floor(w.playerZ))
+	w.TimeOfDay.Overcast = w.Weather.Overcast(w.LocalWeather(px, pz))
+	if !struck {
+		return
+	}
+
+	dx, dz := w.Weather.StrikeOffset()
+	x, z := px+dx, pz+dz
+	if w.LocalWeather(x, z) != WeatherStorm {
+		return
+	}
+	y := w.GetHeight(x, z)
+	if y == 0 {
+		return // Not loaded
+	}
+	if w.OnLightning != nil {
+		w.OnLightning(x, y, z)
+	}
 }
@@ -0,0 +1,178 @@
+// Package worldmap provides map image rendering
+package worldmap
+
+import (
+	"hash/fnv"
+	"image"
+	"image/color"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"voxelgame/internal/generation/terrain"
+)
+
+// Options controls what is drawn on a map image
+type Options struct {
+	Scale   int  // Pixels per block
+	Shading bool // Darken by height and slope
+	Biomes  bool // Tint each column with its biome color
+	Markers bool // Draw dungeon, waterfall, village and ruin markers
+}
+
+// DefaultOptions returns the options for a plain surface map
+func DefaultOptions() Options {
+	return Options{
+		Scale:   1,
+		Shading: true,
+		Markers: true,
+	}
+}
+
+// markerColors are the colors of feature markers
+var markerColors = map[string]color.RGBA{
+	terrain.FeatureDungeon:   {220, 40, 220, 255},
+	terrain.FeatureWaterfall: {40, 230, 255, 255},
+	terrain.FeatureVillage:   {255, 170, 30, 255},
+	terrain.FeatureRuin:      {170, 170, 160, 255},
+}
+
+// background is used for columns without any blocks
+var background = color.RGBA{20, 20, 30, 255}
+
+// BiomeColor returns the overlay color of a biome. Biomes without a map
+// color get a stable color derived from their name.
+func BiomeColor(b *terrain.Biome) color.RGBA {
+	if b.MapColor != [3]uint8{} {
+		return color.RGBA{b.MapColor[0], b.MapColor[1], b.MapColor[2], 255}
+	}
+	h := fnv.New32a()
+	h.Write([]byte(b.Name))
+	v := h.Sum32()
+	return color.RGBA{uint8(64 + v%160), uint8(64 + (v>>8)%160), uint8(64 + (v>>16)%160), 255}
+}
+
+// MarkerColor returns the color used for a feature marker
+func MarkerColor(kind string) color.RGBA {
+	if c, ok := markerColors[kind]; ok {
+		return c
+	}
+	return color.RGBA{255, 60, 60, 255}
+}
+
+// Render draws the map. North (negative Z) is up.
+func (m *Map) Render(opts Options) *image.RGBA {
+	scale := opts.Scale
+	if scale < 1 {
+		scale = 1
+	}
+	img := image.NewRGBA(image.Rect(0, 0, m.Width*scale, m.Depth*scale))
+
+	for z := 0; z < m.Depth; z++ {
+		for x := 0; x < m.Width; x++ {
+			c := m.columnColor(x, z, opts)
+			for py := 0; py < scale; py++ {
+				for px := 0; px < scale; px++ {
+					img.SetRGBA(x*scale+px, z*scale+py, c)
+				}
+			}
+		}
+	}
+
+	if opts.Markers {
+		for _, mk := range m.Markers {
+			m.drawMarker(img, mk, scale)
+		}
+	}
+
+	return img
+}
+
+// columnColor returns the color of one block column
+func (m *Map) columnColor(x, z int, opts Options) color.RGBA {
+	i := z*m.Width + x
+	height := m.Heights[i]
+	if height < 0 && m.LiquidHeights[i] < 0 {
+		return background
+	}
+
+	var rgb [3]float32
+	shade := float32(1)
+
+	if m.LiquidHeights[i] >= 0 {
+		// Liquid over terrain, darker with depth
+		rgb = m.Liquid[i].GetColor()
+		if opts.Shading {
+			shade = 1.0 - float32(m.LiquidHeights[i]-height)/30.0
+		}
+	} else {
+		rgb = m.Surface[i].GetColor()
+		if opts.Shading {
+			shade = 0.6 + float32(height)/80.0
+
+			// Light from the north-west, so slopes facing it are brighter
+			if x > 0 && z > 0 {
+				if nw := m.Heights[i-m.Width-1]; nw >= 0 {
+					shade += float32(height-nw) * 0.08
+				}
+			}
+		}
+	}
+	shade = mgl32.Clamp(shade, 0.3, 1.3)
+
+	r := mgl32.Clamp(rgb[0]*shade, 0, 1)
+	g := mgl32.Clamp(rgb[1]*shade, 0, 1)
+	b := mgl32.Clamp(rgb[2]*shade, 0, 1)
+
+	if opts.Biomes {
+		// Blend the biome color over the shaded terrain so relief stays visible
+		bc := m.BiomeColors[m.Biomes[i]]
+		const amount = 0.55
+		lum := (r + g + b) / 3
+		r = lum*(1-amount) + float32(bc.R)/255*amount*(0.5+lum)
+		g = lum*(1-amount) + float32(bc.G)/255*amount*(0.5+lum)
+		b = lum*(1-amount) + float32(bc.B)/255*amount*(0.5+lum)
+	}
+
+	return color.RGBA{
+		R: uint8(mgl32.Clamp(r, 0, 1) * 255),
+		G: uint8(mgl32.Clamp(g, 0, 1) * 255),
+		B: uint8(mgl32.Clamp(b, 0, 1) * 255),
+		A: 255,
+	}
+}
+
+// drawMarker draws a feature marker: a cross for waterfalls, a ring for the rest
+func (m *Map) drawMarker(img *image.RGBA, mk Marker, scale int) {
+	cx := (mk.X-m.MinX)*scale + scale/2
+	cy := (mk.Z-m.MinZ)*scale + scale/2
+	c := MarkerColor(mk.Kind)
+	outline := color.RGBA{0, 0, 0, 255}
+	r := 3 + scale
+
+	set := func(x, y int, col color.RGBA) {
+		if image.Pt(x, y).In(img.Bounds()) {
+			img.SetRGBA(x, y, col)
+		}
+	}
+
+	switch mk.Kind {
+	case terrain.FeatureWaterfall:
+		for d := -r; d <= r; d++ {
+			set(cx+d, cy, c)
+			set(cx, cy+d, c)
+		}
+		set(cx, cy, outline)
+	default:
+		for d := -r; d <= r; d++ {
+			for _, e := range []int{-r, r} {
+				set(cx+d, cy+e, c)
+				set(cx+e, cy+d, c)
+			}
+			for _, e := range []int{-r - 1, r + 1} {
+				set(cx+d, cy+e, outline)
+				set(cx+e, cy+d, outline)
+			}
+		}
+		set(cx, cy, c)
+	}
+}
@@ -0,0 +1,147 @@
+// Package worldmap provides top-down map rendering of generated worlds
+package worldmap
+
+import (
+	"image/color"
+	"sort"
+
+	"voxelgame/internal/core/block"
+	"voxelgame/internal/core/chunk"
+	"voxelgame/internal/generation/terrain"
+	"voxelgame/internal/headless"
+)
+
+// Marker is a generated feature shown on the map
+type Marker struct {
+	Kind    string // One of the terrain Feature constants
+	X, Y, Z int
+}
+
+// Map holds the surface of a rectangular area, one entry per block column
+// in row-major order starting at the north-west corner
+type Map struct {
+	MinX, MinZ    int
+	Width, Depth  int
+	SeaLevel      int
+	Surface       []block.Type // Top solid block, Air for empty columns
+	Heights       []int        // Y of the top solid block, -1 for empty columns
+	Liquid        []block.Type // Liquid above the surface, Air if none
+	LiquidHeights []int        // Y of the top liquid block, -1 if none
+	Biomes        []uint8      // Index into BiomeNames
+	BiomeNames    []string
+	BiomeColors   []color.RGBA // Overlay color of each entry in BiomeNames
+	Markers       []Marker
+}
+
+// Build scans the inclusive chunk range of a world, including saved
+// modifications. Chunks are unloaded again after scanning, so large areas
+// don't have to fit in memory. progress, if set, is called after each chunk.
+func Build(w *headless.World, minCX, minCZ, maxCX, maxCZ int, progress func(done, total int)) *Map {
+	m := &Map{
+		MinX:     minCX * chunk.Size,
+		MinZ:     minCZ * chunk.Size,
+		Width:    (maxCX - minCX + 1) * chunk.Size,
+		Depth:    (maxCZ - minCZ + 1) * chunk.Size,
+		SeaLevel: w.Generator.Config.SeaLevel,
+	}
+	n := m.Width * m.Depth
+	m.Surface = make([]block.Type, n)
+	m.Heights = make([]int, n)
+	m.Liquid = make([]block.Type, n)
+	m.LiquidHeights = make([]int, n)
+	m.Biomes = make([]uint8, n)
+
+	// Features are reported while chunks generate
+	previous := w.Generator.OnFeature
+	w.Generator.OnFeature = func(kind string, wx, wy, wz int) {
+		m.Markers = append(m.Markers, Marker{Kind: kind, X: wx, Y: wy, Z: wz})
+		if previous != nil {
+			previous(kind, wx, wy, wz)
+		}
+	}
+	defer func() { w.Generator.OnFeature = previous }()
+
+	biomeIndex := make(map[string]uint8)
+	total := (maxCX - minCX + 1) * (maxCZ - minCZ + 1)
+	done := 0
+
+	for cz := minCZ; cz <= maxCZ; cz++ {
+		for cx := minCX; cx <= maxCX; cx++ {
+			c := w.Chunks.LoadChunk(cx, cz)
+			for lz := 0; lz < chunk.Size; lz++ {
+				for lx := 0; lx < chunk.Size; lx++ {
+					wx := cx*chunk.Size + lx
+					wz := cz*chunk.Size + lz
+					i := (wz-m.MinZ)*m.Width + (wx - m.MinX)
+					m.scanColumn(c, lx, lz, i)
+
+					biome := w.Generator.GetBiome(wx, wz)
+					idx, ok := biomeIndex[biome.Name]
+					if !ok {
+						idx = uint8(len(m.BiomeNames))
+						biomeIndex[biome.Name] = idx
+						m.BiomeNames = append(m.BiomeNames, biome.Name)
+						m.BiomeColors = append(m.BiomeColors, BiomeColor(biome))
+					}
+					m.Biomes[i] = idx
+				}
+			}
+			w.Chunks.UnloadChunk(cx, cz)
+
+			done++
+			if progress != nil {
+				progress(done, total)
+			}
+		}
+	}
+
+	sort.Slice(m.Markers, func(i, j int) bool {
+		if m.Markers[i].Kind != m.Markers[j].Kind {
+			return m.Markers[i].Kind < m.Markers[j].Kind
+		}
+		if m.Markers[i].X != m.Markers[j].X {
+			return m.Markers[i].X < m.Markers[j].X
+		}
+		return m.Markers[i].Z < m.Markers[j].Z
+	})
+	return m
+}
+
+// scanColumn records the surface and liquid of one column
+func (m *Map) scanColumn(c *chunk.Chunk, lx, lz, i int) {
+	m.Surface[i] = block.Air
+	m.Heights[i] = -1
+	m.Liquid[i] = block.Air
+	m.LiquidHeights[i] = -1
+
+	for y := chunk.Height - 1; y >= 0; y-- {
+		t := c.GetBlock(lx, y, lz)
+		if t.IsAir() {
+			continue
+		}
+		if t.IsLiquid() {
+			if m.LiquidHeights[i] < 0 {
+				m.Liquid[i] = t
+				m.LiquidHeights[i] = y
+			}
+			continue
+		}
+		m.Surface[i] = t
+		m.Heights[i] = y
+		return
+	}
+}
+
+// CountMarkers returns the number of markers of a kind
+func (m *Map) CountMarkers(kind string) int {
+	n := 0
+	for _, mk := range m.Markers {
+		if mk.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// MarkerKinds lists the features drawn on maps, in legend order
+var MarkerKinds = []string{terrain.FeatureDungeon, terrain.FeatureWaterfall, terrain.FeatureVillage, terrain.FeatureRuin}
@@ -0,0 +1,58 @@
+// Package math provides positional random streams for world generation
+package math
+
+// PositionalRNG derives random streams from a world seed, a chain of salts
+// naming what a stream is for, and coordinates. The inputs are hashed
+// rather than added, so every combination of salt and position gets its own
+// stream: chunk (1, 0) and chunk (0, 1000) no longer share one.
+//
+// A PositionalRNG holds no mutable state. It can be shared between
+// goroutines, and each stream it returns belongs to its caller.
+type PositionalRNG struct {
+	key uint64
+}
+
+// NewPositionalRNG creates a positional RNG for a world seed
+func NewPositionalRNG(seed int64) PositionalRNG {
+	return PositionalRNG{key: mix64(uint64(seed) ^ 0x6A09E667F3BCC909)}
+}
+
+// Split returns an independent positional RNG for a purpose, such as a
+// generation pass or an entry of a data table
+func (p PositionalRNG) Split(salt string) PositionalRNG {
+	// FNV-1a, then mixed with the parent key
+	h := uint64(0xCBF29CE484222325)
+	for i := 0; i < len(salt); i++ {
+		h ^= uint64(salt[i])
+		h *= 0x100000001B3
+	}
+	return PositionalRNG{key: mix64(p.key ^ mix64(h))}
+}
+
+// At returns the random stream for 2D coordinates, such as a chunk or region
+func (p PositionalRNG) At(x, z int) *SeededRNG {
+	return &SeededRNG{state: p.Hash(x, 0, z)}
+}
+
+// At3 returns the random stream for 3D coordinates, such as a block
+func (p PositionalRNG) At3(x, y, z int) *SeededRNG {
+	return &SeededRNG{state: p.Hash(x, y, z)}
+}
+
+// Hash returns 64 random bits for 3D coordinates
+func (p PositionalRNG) Hash(x, y, z int) uint64 {
+	h := mix64(p.key ^ uint64(int64(x))*0x9E3779B97F4A7C15)
+	h = mix64(h ^ uint64(int64(y))*0xC2B2AE3D27D4EB4F)
+	return mix64(h ^ uint64(int64(z))*0x165667B19E3779F9)
+}
+
+// mix64 is the SplitMix64 finalizer, spreading every input bit over the
+// whole output
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xBF58476D1CE4E5B9
+	h ^= h >> 27
+	h *= 0x94D049BB133111EB
+	h ^= h >> 31
+	return h
+}
@@ -1,28 +1,27 @@
 // Package math provides mathematical utilities including seeded RNG
 package math
 
-// SeededRNG is a Linear Congruential Generator for deterministic random numbers
+// SeededRNG is a SplitMix64 generator for deterministic random numbers.
+// Every 64-bit seed starts a different sequence, so seeds derived by
+// hashing (see PositionalRNG) don't collide.
 type SeededRNG struct {
 	state uint64
-	m     uint64
-	a     uint64
-	c     uint64
 }
 
 // NewSeededRNG creates a new seeded random number generator
 func NewSeededRNG(seed int64) *SeededRNG {
-	return &SeededRNG{
-		state: uint64(seed),
-		m:     0x80000000, // 2^31
-		a:     1103515245,
-		c:     12345,
-	}
+	return &SeededRNG{state: uint64(seed)}
+}
+
+// Uint64 returns 64 random bits
+func (r *SeededRNG) Uint64() uint64 {
+	r.state += 0x9E3779B97F4A7C15
+	return mix64(r.state)
 }
 
 // Next returns a random float64 in [0, 1)
 func (r *SeededRNG) Next() float64 {
-	r.state = (r.a*r.state + r.c) % r.m
-	return float64(r.state) / float64(r.m)
+	return float64(r.Uint64()>>11) / (1 << 53)
 }
 
 // NextInt returns a random integer in [min, max]
@@ -49,6 +49,16 @@ func Mod(n, m int) int {
 	return ((n % m) + m) % m
 }
 
+// FloorDiv divides rounding towards negative infinity, so negative world
+// coordinates fall into the right chunk or region
+func FloorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
 // ModFloat performs modulo for float64 that works correctly with negative numbers
 func ModFloat(n, m float64) float64 {
 	return math.Mod(math.Mod(n, m)+m, m)